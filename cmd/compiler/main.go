@@ -1,11 +1,13 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 
 	"github.com/khevencolino/Solar/internal/compiler"
+	"github.com/khevencolino/Solar/internal/utils"
 )
 
 // Config centraliza as configurações do compilador
@@ -40,7 +42,14 @@ func main() {
 	}
 
 	if err := compilador.CompilarArquivo(compileConfig); err != nil {
-		fmt.Fprintf(os.Stderr, "Erro de compilação: %v\n", err)
+		// O interpretador executa o programa: os erros dele são de execução, como nos
+		// executáveis gerados pelos outros backends
+		var execucao *utils.ErroExecucao
+		if errors.As(err, &execucao) {
+			fmt.Fprintf(os.Stderr, "Erro de execução: %v\n", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Erro de compilação: %v\n", err)
+		}
 		os.Exit(1)
 	}
 }
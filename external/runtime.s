@@ -1,56 +1,1364 @@
 // +build ignore
 # comentario acima para evitar que o arquivo seja processado por go build
-  
+
   #
   # funcoes de apoio para o codigo compilado
   #
+  # convencao: argumento em %rdi (inteiro, texto, booleano) ou %xmm0 (decimal);
+  # preservam %rbx, %rbp e %r12-%r15 (registradores callee-saved da System V)
+  #
 
-imprime_num:
-  xor %r9, %r9            # rcx indice, r9 contagem
-  mov $20, %rcx
-  movb $10, buffer(%rcx)  # \n no final da string
-  dec %rcx
-  inc %r9
+  .set BN_LIMBS, 20             # limbs de 64 bits dos inteiros grandes (1280 bits)
 
-  mov $10, %r8
-  or %rax, %rax
-  jz printzero_L0
-  jl mark_neg
-  mov $0, %r10            # r10 flag p/ negativo
-  jmp loop_L0
+# escreve: rsi = dados, rdx = tamanho (no descritor_saida, normalmente stdout)
+# Com descritor_saida = -1 (texto em construção) os dados vão para o fim do heap
+escreve:
+  cmpq $-1, descritor_saida(%rip)
+  je .Lescreve_texto
+  mov $1, %rax            # sys_write
+  mov descritor_saida(%rip), %rdi
+  syscall
+  ret
+.Lescreve_texto:
+  mov %rdx, %rdi
+  call heap_reserva
+  mov heap_atual(%rip), %rdi
+  mov %rdx, %rcx
+  rep movsb
+  mov %rdi, heap_atual(%rip)
+  ret
+
+  #
+  # textos montados em tempo de execucao (interpolacao)
+  #
+  # Ficam no heap, que cresce com brk e nunca e liberado. Entre texto_inicia e
+  # texto_finaliza as rotinas imprime_* escrevem no texto em vez da saida.
+  #
+
+# heap_reserva: rdi = bytes; garante que heap_atual + rdi cabe no heap
+# (preserva rsi e rdx)
+heap_reserva:
+  mov heap_limite(%rip), %rax
+  test %rax, %rax
+  jnz 1f
+  push %rdi
+  mov $12, %rax           # sys_brk(0): fim atual do segmento de dados
+  xor %rdi, %rdi
+  syscall
+  pop %rdi
+  mov %rax, heap_atual(%rip)
+  mov %rax, heap_limite(%rip)
+1:
+  add heap_atual(%rip), %rdi
+  cmp heap_limite(%rip), %rdi
+  jbe 2f
+  add $65535, %rdi        # cresce em blocos de 64 KiB
+  and $-65536, %rdi
+  mov $12, %rax           # sys_brk(novo fim)
+  syscall
+  mov %rax, heap_limite(%rip)
+2:
+  ret
+
+# texto_inicia: passa a acumular a saida em um novo texto no heap
+texto_inicia:
+  xor %rdi, %rdi
+  call heap_reserva       # inicializa o heap no primeiro uso
+  mov heap_atual(%rip), %rax
+  mov %rax, texto_inicio(%rip)
+  mov descritor_saida(%rip), %rax
+  mov %rax, descritor_anterior(%rip)
+  movq $-1, descritor_saida(%rip)
+  ret
+
+# texto_finaliza: termina o texto com \0 e devolve seu endereco em rax
+texto_finaliza:
+  mov $1, %rdi
+  call heap_reserva
+  mov heap_atual(%rip), %rax
+  movb $0, (%rax)
+  inc %rax
+  mov %rax, heap_atual(%rip)
+  mov descritor_anterior(%rip), %rax
+  mov %rax, descritor_saida(%rip)
+  mov texto_inicio(%rip), %rax
+  ret
+
+# alocar: rdi = bytes; devolve em rax um bloco novo no heap (preserva rsi, rdx e r8-r10)
+alocar:
+  push %rdi
+  call heap_reserva
+  pop %rdi
+  mov heap_atual(%rip), %rax
+  add %rax, %rdi
+  mov %rdi, heap_atual(%rip)
+  ret
+
+  #
+  # operacoes com textos (terminados em \0, nunca alterados depois de criados)
+  #
+
+# texto_bytes: rdi = texto; rax = quantidade de bytes sem o \0 (so altera rax)
+texto_bytes:
+  xor %rax, %rax
+.Lbytes_proximo:
+  cmpb $0, (%rdi,%rax)
+  je .Lbytes_fim
+  inc %rax
+  jmp .Lbytes_proximo
+.Lbytes_fim:
+  ret
+
+# texto_tamanho: rdi = texto; rax = caracteres (bytes UTF-8 que nao sao de continuacao)
+texto_tamanho:
+  xor %rax, %rax
+.Ltamanho_byte:
+  movzbl (%rdi), %ecx
+  test %ecx, %ecx
+  jz .Ltamanho_fim
+  inc %rdi
+  and $0xC0, %ecx
+  cmp $0x80, %ecx         # 10xxxxxx continua o caractere anterior
+  je .Ltamanho_byte
+  inc %rax
+  jmp .Ltamanho_byte
+.Ltamanho_fim:
+  ret
+
+# copia_texto: copia o texto em rsi (com o \0) para rdi
+copia_texto:
+  lodsb
+  stosb
+  test %al, %al
+  jnz copia_texto
+  ret
+
+# texto_concatena: rdi = a, rsi = b; rax = novo texto com a seguido de b
+texto_concatena:
+  push %rbx
+  push %r12
+  push %r13
+  mov %rdi, %r12
+  mov %rsi, %r13
+  call texto_bytes
+  mov %rax, %rbx          # bytes de a
+  mov %r13, %rdi
+  call texto_bytes
+  lea 1(%rbx,%rax), %rdi  # a + b + \0
+  call alocar
+  mov %rax, %rdi
+  mov %r12, %rsi
+  mov %rax, %r12          # inicio do resultado
+  mov %rbx, %rcx
+  rep movsb
+  mov %r13, %rsi
+  call copia_texto
+  mov %r12, %rax
+  pop %r13
+  pop %r12
+  pop %rbx
+  ret
+
+# texto_compara: rdi = a, rsi = b; rax = -1, 0 ou 1 pela ordem dos bytes (como strcmp)
+texto_compara:
+  movzbl (%rdi), %eax
+  movzbl (%rsi), %ecx
+  cmp %ecx, %eax
+  jne .Lcompara_diferente
+  test %eax, %eax
+  jz .Lcompara_fim        # os dois terminaram: iguais (rax = 0)
+  inc %rdi
+  inc %rsi
+  jmp texto_compara
+.Lcompara_diferente:
+  sbb %rax, %rax          # -1 se a < b (carry), 0 caso contrario
+  or $1, %rax
+.Lcompara_fim:
+  ret
+
+# texto_comeca_com: rdi = texto, rsi = prefixo; rax = 1 se o texto comeca com o
+# prefixo (so altera rax e rcx)
+texto_comeca_com:
+  xor %rcx, %rcx
+.Lcomeca_byte:
+  movzbl (%rsi,%rcx), %eax
+  test %eax, %eax
+  jz .Lcomeca_sim
+  cmpb %al, (%rdi,%rcx)
+  jne .Lcomeca_nao
+  inc %rcx
+  jmp .Lcomeca_byte
+.Lcomeca_sim:
+  mov $1, %eax
+  ret
+.Lcomeca_nao:
+  xor %eax, %eax
+  ret
+
+# texto_contem: rdi = texto, rsi = parte; rax = 1 se a parte aparece no texto
+texto_contem:
+  call texto_comeca_com
+  test %rax, %rax
+  jnz .Lcontem_fim
+  cmpb $0, (%rdi)
+  je .Lcontem_fim
+  inc %rdi
+  jmp texto_contem
+.Lcontem_fim:
+  ret
+
+# texto_substitui: rdi = texto, rsi = antigo, rdx = novo; rax = novo texto com todas
+# as ocorrencias de antigo trocadas (com antigo vazio devolve o proprio texto)
+texto_substitui:
+  push %rbx
+  push %r12
+  push %r13
+  push %r14
+  push %r15
+  mov %rdi, %r12          # posicao no texto
+  mov %rsi, %r13          # antigo
+  mov %rdx, %r14          # novo
+  mov %rdi, %rax
+  cmpb $0, (%rsi)
+  je .Lsubstitui_retorna
+  mov %rsi, %rdi
+  call texto_bytes
+  mov %rax, %r15          # bytes de antigo
+  mov %r14, %rdi
+  call texto_bytes
+  mov %rax, %rbx          # bytes de novo
+
+  # primeira passada: tamanho do resultado em rdx
+  xor %rdx, %rdx
+  mov %r12, %rdi
+  mov %r13, %rsi
+.Lsubstitui_conta:
+  cmpb $0, (%rdi)
+  je .Lsubstitui_aloca
+  call texto_comeca_com
+  test %rax, %rax
+  jz .Lsubstitui_conta_byte
+  add %rbx, %rdx
+  add %r15, %rdi
+  jmp .Lsubstitui_conta
+.Lsubstitui_conta_byte:
+  inc %rdx
+  inc %rdi
+  jmp .Lsubstitui_conta
+
+  # segunda passada: copia para o resultado (rdx = destino)
+.Lsubstitui_aloca:
+  lea 1(%rdx), %rdi
+  call alocar
+  push %rax
+  mov %rax, %rdx
+.Lsubstitui_copia:
+  cmpb $0, (%r12)
+  je .Lsubstitui_termina
+  mov %r12, %rdi
+  mov %r13, %rsi
+  call texto_comeca_com
+  test %rax, %rax
+  jz .Lsubstitui_copia_byte
+  mov %rdx, %rdi
+  mov %r14, %rsi
+  mov %rbx, %rcx
+  rep movsb
+  mov %rdi, %rdx
+  add %r15, %r12
+  jmp .Lsubstitui_copia
+.Lsubstitui_copia_byte:
+  movb (%r12), %al
+  movb %al, (%rdx)
+  inc %r12
+  inc %rdx
+  jmp .Lsubstitui_copia
+.Lsubstitui_termina:
+  movb $0, (%rdx)
+  pop %rax
+.Lsubstitui_retorna:
+  pop %r15
+  pop %r14
+  pop %r13
+  pop %r12
+  pop %rbx
+  ret
+
+# texto_maiusculas / texto_minusculas: rdi = texto; rax = copia com as letras ASCII e
+# as letras do Latin-1 (segundo byte depois de 0xC3, exceto ÷ e ×) convertidas
+texto_maiusculas:
+  mov $0x61, %r8d         # 'a'
+  mov $0xA0, %r9d         # à = C3 A0
+  mov $-32, %r10d
+  jmp texto_caixa
+texto_minusculas:
+  mov $0x41, %r8d         # 'A'
+  mov $0x80, %r9d         # À = C3 80
+  mov $32, %r10d
+texto_caixa:
+  push %rbx
+  push %r12
+  mov %rdi, %r12
+  call texto_bytes
+  lea 1(%rax), %rdi
+  call alocar
+  mov %rax, %rbx
+  mov %rax, %rdi
+  mov %r12, %rsi
+  xor %edx, %edx          # byte anterior
+.Lcaixa_byte:
+  movzbl (%rsi), %eax
+  inc %rsi
+  mov %eax, %ecx
+  sub %r8d, %ecx
+  cmp $25, %ecx
+  jbe .Lcaixa_converte    # letra ASCII
+  cmp $0xC3, %edx
+  jne .Lcaixa_copia
+  mov %eax, %ecx
+  sub %r9d, %ecx
+  cmp $30, %ecx
+  ja .Lcaixa_copia
+  cmp $0x17, %ecx         # ÷ (C3 B7) e × (C3 97)
+  je .Lcaixa_copia
+.Lcaixa_converte:
+  add %r10d, %eax
+.Lcaixa_copia:
+  movb %al, (%rdi)
+  inc %rdi
+  mov %eax, %edx
+  test %eax, %eax
+  jnz .Lcaixa_byte
+  mov %rbx, %rax
+  pop %r12
+  pop %rbx
+  ret
+
+# texto_divide: rdi = texto, rsi = separador; rax = lista<texto> com os trechos entre
+# as ocorrencias do separador (vazio: um trecho por caractere UTF-8)
+# rbx = lista, r12 = inicio do trecho, r13 = separador, r14 = tamanho dele, r15 = cursor
+texto_divide:
+  push %rbx
+  push %r12
+  push %r13
+  push %r14
+  push %r15
+  mov %rdi, %r12
+  mov %rsi, %r13
+  xor %rdi, %rdi
+  call lista_nova
+  mov %rax, %rbx
+  mov %r13, %rdi
+  call texto_bytes
+  mov %rax, %r14
+  mov %r12, %r15
+  test %r14, %r14
+  jz .Ldivide_caractere
+.Ldivide_busca:
+  mov %r15, %rdi
+  mov %r13, %rsi
+  call texto_comeca_com
+  test %rax, %rax
+  jnz .Ldivide_achou
+  cmpb $0, (%r15)
+  je .Ldivide_ultimo
+  inc %r15
+  jmp .Ldivide_busca
+.Ldivide_achou:
+  call .Ldivide_anexa
+  add %r14, %r15
+  mov %r15, %r12
+  jmp .Ldivide_busca
+.Ldivide_ultimo:
+  call .Ldivide_anexa
+  jmp .Ldivide_fim
+.Ldivide_caractere:
+  cmpb $0, (%r12)
+  je .Ldivide_fim
+  lea 1(%r12), %r15
+.Ldivide_continuacao:
+  movzbl (%r15), %eax
+  and $0xC0, %eax
+  cmp $0x80, %eax         # 10xxxxxx continua o caractere
+  jne .Ldivide_char
+  inc %r15
+  jmp .Ldivide_continuacao
+.Ldivide_char:
+  call .Ldivide_anexa
+  mov %r15, %r12
+  jmp .Ldivide_caractere
+.Ldivide_fim:
+  mov %rbx, %rax
+  pop %r15
+  pop %r14
+  pop %r13
+  pop %r12
+  pop %rbx
+  ret
+# anexa a lista uma copia dos bytes em [r12, r15)
+.Ldivide_anexa:
+  mov %r15, %rdi
+  sub %r12, %rdi
+  inc %rdi
+  call alocar
+  mov %rax, %rdi
+  mov %r12, %rsi
+  mov %r15, %rcx
+  sub %r12, %rcx
+  rep movsb
+  movb $0, (%rdi)
+  mov %rax, %rsi
+  mov %rbx, %rdi
+  jmp lista_anexa
+
+  #
+  # listas: cabecalho de 24 bytes no heap [tamanho, capacidade, dados], com
+  # 8 bytes por elemento em dados (decimais guardados como bits do double)
+  #
+
+# lista_nova: rdi = quantidade de elementos; rax = lista com tamanho e capacidade
+# iguais a rdi e dados ainda nao preenchidos
+lista_nova:
+  push %rbx
+  mov %rdi, %rbx
+  mov $24, %rdi
+  call alocar
+  mov %rbx, (%rax)
+  mov %rbx, 8(%rax)
+  mov %rax, %rsi          # alocar preserva rsi
+  lea (,%rbx,8), %rdi
+  call alocar
+  mov %rax, 16(%rsi)
+  mov %rsi, %rax
+  pop %rbx
+  ret
+
+# lista_tamanho: rdi = lista; rax = quantidade de elementos
+lista_tamanho:
+  mov (%rdi), %rax
+  ret
+
+# lista_elemento: rdi = lista, rsi = indice, rdx = linha, rcx = coluna do '['
+# rax = endereco do elemento; indice fora de [0, tamanho) e erro de execucao
+lista_elemento:
+  cmp (%rdi), %rsi
+  jae .Lelemento_fora     # sem sinal: negativos tambem ficam fora
+  mov 16(%rdi), %rax
+  lea (%rax,%rsi,8), %rax
+  ret
+.Lelemento_fora:
+  mov %rdx, %rsi
+  mov %rcx, %rdx
+  lea texto_indice_fora(%rip), %rdi
+  jmp erro_execucao
+
+# lista_anexa: rdi = lista, rsi = valor; sem espaco, os dados vao para um bloco
+# novo com o dobro da capacidade (no minimo 4)
+lista_anexa:
+  mov (%rdi), %rax
+  cmp 8(%rdi), %rax
+  jne .Lanexa_guarda
+  mov %rdi, %r8           # alocar preserva rsi e r8
+  mov 8(%rdi), %r9
+  add %r9, %r9
+  mov $4, %rax
+  cmp %rax, %r9
+  cmovl %rax, %r9
+  mov %r9, 8(%r8)
+  lea (,%r9,8), %rdi
+  call alocar
+  mov %rsi, %r9
+  mov %rax, %rdi          # copia os elementos atuais
+  mov 16(%r8), %rsi
+  mov (%r8), %rcx
+  rep movsq
+  mov %rax, 16(%r8)
+  mov %r8, %rdi
+  mov %r9, %rsi
+  mov (%rdi), %rax
+.Lanexa_guarda:
+  mov 16(%rdi), %rcx
+  mov %rsi, (%rcx,%rax,8)
+  inc %rax
+  mov %rax, (%rdi)
+  ret
 
-mark_neg:
+# imprime_lista: rdi = lista, rsi = rotina que imprime um elemento (recebe em rdi)
+# Escreve [a, b, c]
+imprime_lista:
+  push %rbx
+  push %r12
+  push %r13
+  mov %rdi, %rbx
+  mov %rsi, %r12
+  xor %r13, %r13
+  lea abre_colchete(%rip), %rsi
+  mov $1, %rdx
+  call escreve
+.Llista_elemento:
+  cmp (%rbx), %r13
+  jae .Llista_fim
+  test %r13, %r13
+  jz .Llista_escreve
+  lea separador_lista(%rip), %rsi
+  mov $2, %rdx
+  call escreve
+.Llista_escreve:
+  mov 16(%rbx), %rax
+  mov (%rax,%r13,8), %rdi
+  call *%r12
+  inc %r13
+  jmp .Llista_elemento
+.Llista_fim:
+  lea fecha_colchete(%rip), %rsi
+  mov $1, %rdx
+  pop %r13
+  pop %r12
+  pop %rbx
+  jmp escreve
+
+  #
+  # mapas: cabecalho de 40 bytes no heap [tamanho, capacidade, chaves, valores,
+  # chaves_texto], com 8 bytes por entrada em chaves e valores na ordem de
+  # insercao. A busca e linear; com chaves_texto != 0 as chaves sao comparadas
+  # com texto_compara, senao pelo valor
+  #
+
+# mapa_novo: rdi = 1 se as chaves sao textos; rax = mapa vazio
+mapa_novo:
+  mov %rdi, %rsi          # alocar preserva rsi
+  mov $40, %rdi
+  call alocar
+  movq $0, (%rax)
+  movq $0, 8(%rax)
+  movq $0, 16(%rax)
+  movq $0, 24(%rax)
+  mov %rsi, 32(%rax)
+  ret
+
+# mapa_tamanho: rdi = mapa; rax = quantidade de entradas
+mapa_tamanho:
+  mov (%rdi), %rax
+  ret
+
+# mapa_busca: rdi = mapa, rsi = chave; rax = indice da entrada ou -1
+mapa_busca:
+  push %rbx
+  push %r12
+  push %r13
+  mov %rdi, %rbx
+  mov %rsi, %r12
+  xor %r13, %r13
+.Lbusca_proxima:
+  cmp (%rbx), %r13
+  jae .Lbusca_ausente
+  mov 16(%rbx), %rax
+  mov (%rax,%r13,8), %rdi
+  cmpq $0, 32(%rbx)
+  jne .Lbusca_texto
+  cmp %r12, %rdi
+  je .Lbusca_fim
+  jmp .Lbusca_segue
+.Lbusca_texto:
+  mov %r12, %rsi
+  call texto_compara
+  test %rax, %rax
+  jz .Lbusca_fim
+.Lbusca_segue:
+  inc %r13
+  jmp .Lbusca_proxima
+.Lbusca_ausente:
+  mov $-1, %r13
+.Lbusca_fim:
+  mov %r13, %rax
+  pop %r13
+  pop %r12
+  pop %rbx
+  ret
+
+# mapa_contem: rdi = mapa, rsi = chave; rax = 1 se a chave existe
+mapa_contem:
+  call mapa_busca
+  not %rax                # -1 vira 0; indices viram negativos
+  shr $63, %rax
+  ret
+
+# mapa_elemento: rdi = mapa, rsi = chave, rdx = linha, rcx = coluna do '['
+# rax = endereco do valor; chave ausente e erro de execucao
+mapa_elemento:
+  push %rdx
+  push %rcx
+  push %rdi
+  call mapa_busca
+  pop %rdi
+  pop %rcx
+  pop %rdx
+  test %rax, %rax
+  js .Lelemento_ausente
+  mov 24(%rdi), %rcx
+  lea (%rcx,%rax,8), %rax
+  ret
+.Lelemento_ausente:
+  mov %rdx, %rsi
+  mov %rcx, %rdx
+  lea texto_chave_ausente(%rip), %rdi
+  jmp erro_execucao
+
+# mapa_insere: rdi = mapa, rsi = chave; rax = endereco do valor da chave, criando a
+# entrada no fim se ela nao existe. Sem espaco, chaves e valores vao para blocos
+# novos com o dobro da capacidade (no minimo 4)
+mapa_insere:
+  push %rbx
+  push %r12
+  mov %rdi, %rbx
+  mov %rsi, %r12
+  call mapa_busca
+  test %rax, %rax
+  jns .Linsere_endereco
+  mov (%rbx), %rax
+  cmp 8(%rbx), %rax
+  jne .Linsere_guarda
+  mov 8(%rbx), %r8
+  add %r8, %r8
+  mov $4, %rax
+  cmp %rax, %r8
+  cmovl %rax, %r8
+  mov %r8, 8(%rbx)
+  shl $3, %r8             # bytes de cada bloco (alocar preserva r8 e r9)
+  mov $16, %r9            # campo copiado: chaves e depois valores
+.Linsere_copia:
+  mov %r8, %rdi
+  call alocar
+  mov %rax, %rdi
+  mov (%rbx,%r9), %rsi
+  mov (%rbx), %rcx
+  rep movsq
+  mov %rax, (%rbx,%r9)
+  add $8, %r9
+  cmp $24, %r9
+  jbe .Linsere_copia
+  mov (%rbx), %rax
+.Linsere_guarda:
+  mov 16(%rbx), %rcx
+  mov %r12, (%rcx,%rax,8)
+  lea 1(%rax), %rcx
+  mov %rcx, (%rbx)
+.Linsere_endereco:
+  mov 24(%rbx), %rcx
+  lea (%rcx,%rax,8), %rax
+  pop %r12
+  pop %rbx
+  ret
+
+# mapa_remove: rdi = mapa, rsi = chave; remove a entrada (se existir) deslocando as
+# seguintes para manter a ordem de insercao
+mapa_remove:
+  push %rdi
+  call mapa_busca
+  pop %r8
+  test %rax, %rax
+  js .Lremove_fim
+  mov (%r8), %rdx
+  dec %rdx
+  mov %rdx, (%r8)
+  sub %rax, %rdx          # entradas depois da removida
+  mov $16, %r9
+.Lremove_desloca:
+  mov (%r8,%r9), %rdi
+  lea (%rdi,%rax,8), %rdi
+  lea 8(%rdi), %rsi
+  mov %rdx, %rcx
+  rep movsq
+  add $8, %r9
+  cmp $24, %r9
+  jbe .Lremove_desloca
+.Lremove_fim:
+  ret
+
+# mapa_chaves / mapa_valores: rdi = mapa; rax = lista nova com uma copia das
+# chaves ou dos valores, na ordem de insercao
+mapa_chaves:
+  mov $16, %rsi
+  jmp mapa_lista
+mapa_valores:
+  mov $24, %rsi
+mapa_lista:
+  push %rbx
+  push %r12
+  mov %rdi, %rbx
+  mov %rsi, %r12
+  mov (%rdi), %rdi
+  call lista_nova
+  mov 16(%rax), %rdi
+  mov (%rbx,%r12), %rsi
+  mov (%rbx), %rcx
+  rep movsq
+  pop %r12
+  pop %rbx
+  ret
+
+# imprime_mapa: rdi = mapa, rsi = rotina que imprime uma chave, rdx = rotina que
+# imprime um valor (recebem em rdi). Escreve {k: v, ...}
+imprime_mapa:
+  push %rbx
+  push %r12
+  push %r13
+  push %r14
+  mov %rdi, %rbx
+  mov %rsi, %r12
+  mov %rdx, %r14
+  xor %r13, %r13
+  lea abre_chave(%rip), %rsi
+  mov $1, %rdx
+  call escreve
+.Lmapa_entrada:
+  cmp (%rbx), %r13
+  jae .Lmapa_fim
+  test %r13, %r13
+  jz .Lmapa_escreve
+  lea separador_lista(%rip), %rsi
+  mov $2, %rdx
+  call escreve
+.Lmapa_escreve:
+  mov 16(%rbx), %rax
+  mov (%rax,%r13,8), %rdi
+  call *%r12
+  lea separador_mapa(%rip), %rsi
+  mov $2, %rdx
+  call escreve
+  mov 24(%rbx), %rax
+  mov (%rax,%r13,8), %rdi
+  call *%r14
+  inc %r13
+  jmp .Lmapa_entrada
+.Lmapa_fim:
+  lea fecha_chave(%rip), %rsi
+  mov $1, %rdx
+  pop %r14
+  pop %r13
+  pop %r12
+  pop %rbx
+  jmp escreve
+
+# imprime_elemento_decimal: rdi = bits do double (elemento de lista)
+imprime_elemento_decimal:
+  movq %rdi, %xmm0
+  jmp imprime_decimal
+
+imprime_espaco:
+  lea espaco(%rip), %rsi
+  mov $1, %rdx
+  jmp escreve
+
+imprime_nova_linha:
+  lea nova_linha(%rip), %rsi
+  mov $1, %rdx
+  jmp escreve
+
+# imprime_inteiro: rdi = valor com sinal
+imprime_inteiro:
+  mov %rdi, %rax
+  lea buffer+32(%rip), %rsi   # preenche o buffer de tras para frente
+  mov $10, %r8
+  xor %r10, %r10              # r10 flag p/ negativo
+  test %rax, %rax
+  jns .Lint_digito
   mov $1, %r10
-  neg %rax
+  neg %rax                    # o menor inteiro continua correto como sem sinal
 
-loop_L0:
-  cqo
-  idiv %r8  
+.Lint_digito:
+  xor %rdx, %rdx
+  div %r8
   addb $0x30, %dl
-  movb %dl, buffer(%rcx)
-  dec %rcx
-  inc %r9
-  or %rax, %rax
-  jnz loop_L0
+  dec %rsi
+  movb %dl, (%rsi)
+  test %rax, %rax
+  jnz .Lint_digito
   test %r10, %r10
-  jz print_L0
-  movb $45, buffer(%rcx)
-  dec %rcx
-  jmp print_L0
+  jz .Lint_escreve
+  dec %rsi
+  movb $45, (%rsi)            # '-'
 
-printzero_L0:
-  movb $0x30, buffer(%rcx)
-  dec %rcx
-  inc %r9
+.Lint_escreve:
+  lea buffer+32(%rip), %rdx
+  sub %rsi, %rdx              # tamanho
+  jmp escreve
 
-print_L0:
-  mov $1, %rax            # sys_write
-  mov $1, %rdi            # stdout
-  mov $buffer, %rsi       # dados
+# imprime_texto: rdi = endereco da string terminada em \0
+imprime_texto:
+  mov %rdi, %rsi
+  xor %rdx, %rdx
+.Ltexto_tamanho:
+  cmpb $0, (%rsi,%rdx)
+  je escreve
+  inc %rdx
+  jmp .Ltexto_tamanho
+
+# imprime_booleano: rdi = 0 (falso) ou diferente de 0 (verdadeiro)
+imprime_booleano:
+  test %rdi, %rdi
+  jz .Lbool_falso
+  lea texto_verdadeiro(%rip), %rsi
+  mov $10, %rdx
+  jmp escreve
+.Lbool_falso:
+  lea texto_falso(%rip), %rsi
+  mov $5, %rdx
+  jmp escreve
+
+  #
+  # imprime_decimal: xmm0 = valor
+  #
+  # Produz a menor sequencia de digitos que identifica o double (algoritmo de
+  # Burger & Dybvig com inteiros grandes) e formata como o %g do Go:
+  # notacao cientifica quando o expoente e < -4 ou >= 6.
+  #
+  # rbx = expoente enviesado / contagem, r12 = mantissa, r13 = expoente / digito,
+  # r14 = k (posicao do ponto decimal), r15 = cursor de saida
+  #
+imprime_decimal:
+  push %rbx
+  push %r12
+  push %r13
+  push %r14
+  push %r15
+
+  movq %xmm0, %rax
+  lea buffer_decimal(%rip), %r15
+  mov %rax, %rbx
+  shr $52, %rbx
+  and $0x7ff, %ebx            # expoente enviesado
+  mov %rax, %r12
+  shl $12, %r12
+  shr $12, %r12               # fracao (52 bits)
+
+  cmp $0x7ff, %ebx
+  jne .Ldec_finito
+  test %r12, %r12
+  jz .Ldec_infinito
+  lea texto_nan(%rip), %rsi
+  mov $3, %rdx
+  jmp .Ldec_fim_especial
+
+.Ldec_infinito:
+  lea texto_mais_inf(%rip), %rsi
+  test %rax, %rax
+  jns 1f
+  lea texto_menos_inf(%rip), %rsi
+1:
+  mov $4, %rdx
+
+.Ldec_fim_especial:
+  call escreve
+  jmp .Ldec_retorna
+
+.Ldec_finito:
+  test %rax, %rax
+  jns 1f
+  movb $45, (%r15)            # '-'
+  inc %r15
+1:
+  mov %r12, %rax
+  or %rbx, %rax
+  jnz .Ldec_nao_zero
+  movb $48, (%r15)            # '0'
+  inc %r15
+  jmp .Ldec_escreve
+
+.Ldec_nao_zero:
+  test %ebx, %ebx
+  jz .Ldec_subnormal
+  bts $52, %r12               # bit implicito
+  lea -1075(%rbx), %r13       # expoente real: valor = r12 * 2^r13
+  jmp .Ldec_inicia
+.Ldec_subnormal:
+  mov $-1074, %r13
+
+  # valor = r / s, com margens mp (para cima) e mm (para baixo)
+.Ldec_inicia:
+  test %r13, %r13
+  js .Ldec_expoente_negativo
+
+  mov $0x10000000000000, %rax
+  cmp %rax, %r12
+  je .Ldec_positivo_borda
+  lea bn_r(%rip), %rdi        # r = f * 2^(e+1), s = 2, mp = mm = 2^e
+  mov %r12, %rsi
+  lea 1(%r13), %rdx
+  call bn_define
+  lea bn_s(%rip), %rdi
+  mov $2, %rsi
+  xor %rdx, %rdx
+  call bn_define
+  lea bn_mp(%rip), %rdi
+  mov $1, %rsi
+  mov %r13, %rdx
+  call bn_define
+  lea bn_mm(%rip), %rdi
+  mov $1, %rsi
+  mov %r13, %rdx
+  call bn_define
+  jmp .Ldec_estima
+
+.Ldec_positivo_borda:
+  lea bn_r(%rip), %rdi        # r = f * 2^(e+2), s = 4, mp = 2^(e+1), mm = 2^e
+  mov %r12, %rsi
+  lea 2(%r13), %rdx
+  call bn_define
+  lea bn_s(%rip), %rdi
+  mov $4, %rsi
+  xor %rdx, %rdx
+  call bn_define
+  lea bn_mp(%rip), %rdi
+  mov $1, %rsi
+  lea 1(%r13), %rdx
+  call bn_define
+  lea bn_mm(%rip), %rdi
+  mov $1, %rsi
+  mov %r13, %rdx
+  call bn_define
+  jmp .Ldec_estima
+
+.Ldec_expoente_negativo:
+  cmp $1, %ebx
+  jbe .Ldec_negativo_normal
+  mov $0x10000000000000, %rax
+  cmp %rax, %r12
+  jne .Ldec_negativo_normal
+  lea bn_r(%rip), %rdi        # r = f * 4, s = 2^(2-e), mp = 2, mm = 1
+  mov %r12, %rsi
+  mov $2, %rdx
+  call bn_define
+  lea bn_s(%rip), %rdi
+  mov $1, %rsi
+  mov $2, %rdx
+  sub %r13, %rdx
+  call bn_define
+  lea bn_mp(%rip), %rdi
+  mov $2, %rsi
+  xor %rdx, %rdx
+  call bn_define
+  lea bn_mm(%rip), %rdi
+  mov $1, %rsi
+  xor %rdx, %rdx
+  call bn_define
+  jmp .Ldec_estima
+
+.Ldec_negativo_normal:
+  lea bn_r(%rip), %rdi        # r = f * 2, s = 2^(1-e), mp = mm = 1
+  mov %r12, %rsi
+  mov $1, %rdx
+  call bn_define
+  lea bn_s(%rip), %rdi
+  mov $1, %rsi
+  mov $1, %rdx
+  sub %r13, %rdx
+  call bn_define
+  lea bn_mp(%rip), %rdi
+  mov $1, %rsi
+  xor %rdx, %rdx
+  call bn_define
+  lea bn_mm(%rip), %rdi
+  mov $1, %rsi
+  xor %rdx, %rdx
+  call bn_define
+
+  # k = teto(log10(valor)), estimado pelo bit mais alto (pode ficar 1 abaixo)
+.Ldec_estima:
+  bsr %r12, %rax
+  add %r13, %rax
+  cvtsi2sd %rax, %xmm0
+  mulsd log10_2(%rip), %xmm0
+  subsd epsilon_estimativa(%rip), %xmm0
+  cvttsd2si %xmm0, %r14
+  cvtsi2sd %r14, %xmm1
+  ucomisd %xmm1, %xmm0
+  jbe 1f
+  inc %r14
+1:
+  mov %r14, %rbx
+  test %rbx, %rbx
+  js .Ldec_escala_negativa
+
+.Ldec_escala_positiva:        # s = s * 10^k
+  test %rbx, %rbx
+  jz .Ldec_corrige
+  lea bn_s(%rip), %rdi
+  call bn_mul10
+  dec %rbx
+  jmp .Ldec_escala_positiva
+
+.Ldec_escala_negativa:        # r, mp, mm = r, mp, mm * 10^-k
+  lea bn_r(%rip), %rdi
+  call bn_mul10
+  lea bn_mp(%rip), %rdi
+  call bn_mul10
+  lea bn_mm(%rip), %rdi
+  call bn_mul10
+  inc %rbx
+  jnz .Ldec_escala_negativa
+
+.Ldec_corrige:
+  call dec_testa_alto
+  test %eax, %eax
+  jz .Ldec_gera
+  lea bn_s(%rip), %rdi
+  call bn_mul10
+  inc %r14
+
+  # gera digitos ate que o restante caiba nas margens
+.Ldec_gera:
+  xor %ebx, %ebx
+.Ldec_proximo:
+  lea bn_r(%rip), %rdi
+  call bn_mul10
+  lea bn_mp(%rip), %rdi
+  call bn_mul10
+  lea bn_mm(%rip), %rdi
+  call bn_mul10
+  xor %r13d, %r13d            # digito = r / s por subtracoes sucessivas
+.Ldec_divide:
+  lea bn_r(%rip), %rdi
+  lea bn_s(%rip), %rsi
+  call bn_compara
+  test %eax, %eax
+  js .Ldec_testa
+  lea bn_r(%rip), %rdi
+  lea bn_s(%rip), %rsi
+  call bn_subtrai
+  inc %r13
+  jmp .Ldec_divide
+
+.Ldec_testa:
+  call dec_testa_baixo
+  push %rax
+  call dec_testa_alto
+  pop %rcx
+  test %ecx, %ecx
+  jnz .Ldec_baixo
+  test %eax, %eax
+  jnz .Ldec_sobe
+  addb $48, %r13b
+  lea dec_digitos(%rip), %rdi
+  movb %r13b, (%rdi,%rbx)
+  inc %rbx
+  jmp .Ldec_proximo
+
+.Ldec_baixo:
+  test %eax, %eax
+  jz .Ldec_ultimo
+  lea bn_t(%rip), %rdi        # ambas as margens: escolhe o mais proximo (2r vs s),
+  lea bn_r(%rip), %rsi        # no empate o digito par
+  lea bn_r(%rip), %rdx
+  call bn_soma
+  lea bn_t(%rip), %rdi
+  lea bn_s(%rip), %rsi
+  call bn_compara
+  test %eax, %eax
+  js .Ldec_ultimo
+  jnz .Ldec_sobe
+  test $1, %r13b
+  jz .Ldec_ultimo
+.Ldec_sobe:
+  inc %r13
+.Ldec_ultimo:
+  addb $48, %r13b
+  lea dec_digitos(%rip), %rdi
+  movb %r13b, (%rdi,%rbx)
+  inc %rbx
+
+  # formata: rbx = numero de digitos, r14 = posicao do ponto decimal
+  lea dec_digitos(%rip), %rsi
+  lea -1(%r14), %rax          # expoente cientifico
+  cmp $-4, %rax
+  jl .Ldec_cientifico
+  cmp $6, %rax
+  jge .Ldec_cientifico
+
+  test %r14, %r14
+  jg .Ldec_parte_inteira
+  movb $48, (%r15)
+  inc %r15
+  jmp .Ldec_parte_fracionaria
+
+.Ldec_parte_inteira:
+  xor %rcx, %rcx
+.Ldec_inteira_digito:
+  cmp %r14, %rcx
+  jge .Ldec_parte_fracionaria
+  mov $48, %al
+  cmp %rbx, %rcx
+  jge 1f
+  movb (%rsi,%rcx), %al
+1:
+  movb %al, (%r15)
+  inc %r15
   inc %rcx
-  add %rcx, %rsi
-  mov %r9, %rdx           # tamanho
-  syscall
+  jmp .Ldec_inteira_digito
+
+.Ldec_parte_fracionaria:
+  cmp %r14, %rbx
+  jle .Ldec_escreve
+  movb $46, (%r15)            # '.'
+  inc %r15
+  mov %r14, %rcx
+.Ldec_fracao_digito:
+  cmp %rbx, %rcx
+  jge .Ldec_escreve
+  mov $48, %al
+  test %rcx, %rcx
+  js 1f
+  movb (%rsi,%rcx), %al
+1:
+  movb %al, (%r15)
+  inc %r15
+  inc %rcx
+  jmp .Ldec_fracao_digito
+
+.Ldec_cientifico:
+  movb (%rsi), %al
+  movb %al, (%r15)
+  inc %r15
+  cmp $1, %rbx
+  jle .Ldec_expoente
+  movb $46, (%r15)            # '.'
+  inc %r15
+  mov $1, %rcx
+.Ldec_cientifico_digito:
+  movb (%rsi,%rcx), %al
+  movb %al, (%r15)
+  inc %r15
+  inc %rcx
+  cmp %rbx, %rcx
+  jl .Ldec_cientifico_digito
+
+.Ldec_expoente:
+  movb $101, (%r15)           # 'e'
+  inc %r15
+  lea -1(%r14), %rax
+  movb $43, (%r15)            # '+'
+  test %rax, %rax
+  jns 1f
+  movb $45, (%r15)            # '-'
+  neg %rax
+1:
+  inc %r15
+  mov $10, %r8
+  cmp $10, %rax
+  jge 2f
+  movb $48, (%r15)            # pelo menos dois digitos
+  inc %r15
+  jmp 4f
+2:
+  cmp $100, %rax
+  jl 3f
+  xor %rdx, %rdx
+  mov $100, %rcx
+  div %rcx
+  addb $48, %al
+  movb %al, (%r15)
+  inc %r15
+  mov %rdx, %rax
+3:
+  xor %rdx, %rdx
+  div %r8
+  addb $48, %al
+  movb %al, (%r15)
+  inc %r15
+  mov %rdx, %rax
+4:
+  addb $48, %al
+  movb %al, (%r15)
+  inc %r15
+
+.Ldec_escreve:
+  lea buffer_decimal(%rip), %rsi
+  mov %r15, %rdx
+  sub %rsi, %rdx
+  call escreve
+
+.Ldec_retorna:
+  pop %r15
+  pop %r14
+  pop %r13
+  pop %r12
+  pop %rbx
+  ret
+
+# dec_testa_alto: eax = 1 se r + mp alcanca s (inclusivo quando a mantissa r12 e par)
+dec_testa_alto:
+  lea bn_t(%rip), %rdi
+  lea bn_r(%rip), %rsi
+  lea bn_mp(%rip), %rdx
+  call bn_soma
+  lea bn_t(%rip), %rdi
+  lea bn_s(%rip), %rsi
+  call bn_compara
+  test $1, %r12
+  jnz 1f
+  inc %eax                    # par: t >= s
+1:
+  cmp $0, %eax
+  setg %al
+  movzbl %al, %eax
+  ret
+
+# dec_testa_baixo: eax = 1 se r esta abaixo de mm (inclusivo quando a mantissa r12 e par)
+dec_testa_baixo:
+  lea bn_r(%rip), %rdi
+  lea bn_mm(%rip), %rsi
+  call bn_compara
+  test $1, %r12
+  jnz 1f
+  dec %eax                    # par: r <= mm
+1:
+  cmp $0, %eax
+  setl %al
+  movzbl %al, %eax
+  ret
+
+  #
+  # inteiros grandes sem sinal com BN_LIMBS limbs (little endian)
+  #
+
+# bn_define: rdi = destino, rsi = valor, rdx = deslocamento; destino = valor << deslocamento
+bn_define:
+  push %rdi
+  xor %eax, %eax
+  mov $BN_LIMBS, %ecx
+1:
+  mov %rax, (%rdi)
+  add $8, %rdi
+  dec %ecx
+  jnz 1b
+  pop %rdi
+  mov %rdx, %rcx
+  shr $6, %rdx                # limb
+  and $63, %ecx               # bits dentro do limb
+  mov %rsi, %rax
+  shl %cl, %rax
+  mov %rax, (%rdi,%rdx,8)
+  test %ecx, %ecx
+  jz 2f
+  neg %ecx
+  add $64, %ecx
+  mov %rsi, %rax
+  shr %cl, %rax
+  mov %rax, 8(%rdi,%rdx,8)
+2:
+  ret
+
+# bn_mul10: rdi = destino; destino = destino * 10
+bn_mul10:
+  mov $BN_LIMBS, %ecx
+  xor %r8, %r8                # carry
+  mov $10, %r9
+1:
+  mov (%rdi), %rax
+  mul %r9
+  add %r8, %rax
+  adc $0, %rdx
+  mov %rax, (%rdi)
+  mov %rdx, %r8
+  add $8, %rdi
+  dec %ecx
+  jnz 1b
+  ret
+
+# bn_compara: rdi = a, rsi = b; eax = -1, 0 ou 1
+bn_compara:
+  mov $BN_LIMBS-1, %ecx
+1:
+  mov (%rdi,%rcx,8), %rax
+  cmp (%rsi,%rcx,8), %rax
+  ja 2f
+  jb 3f
+  dec %ecx
+  jns 1b
+  xor %eax, %eax
+  ret
+2:
+  mov $1, %eax
+  ret
+3:
+  mov $-1, %eax
+  ret
+
+# bn_subtrai: rdi = a, rsi = b; a = a - b (a >= b)
+bn_subtrai:
+  mov $BN_LIMBS, %ecx
+  clc
+1:
+  mov (%rsi), %rax
+  sbb %rax, (%rdi)
+  lea 8(%rsi), %rsi
+  lea 8(%rdi), %rdi
+  dec %ecx
+  jnz 1b
+  ret
+
+# bn_soma: rdi = destino, rsi = a, rdx = b; destino = a + b
+bn_soma:
+  mov $BN_LIMBS, %ecx
+  clc
+1:
+  mov (%rsi), %rax
+  adc (%rdx), %rax
+  mov %rax, (%rdi)
+  lea 8(%rsi), %rsi
+  lea 8(%rdx), %rdx
+  lea 8(%rdi), %rdi
+  dec %ecx
+  jnz 1b
+  ret
+
+# resto_decimal: xmm0 = x, xmm1 = y; resultado (como fmod) em xmm0
+# fprem repete a reducao parcial ate C2 = 0; o resto e exato e tem o sinal de x
+resto_decimal:
+  sub $16, %rsp
+  movsd %xmm1, (%rsp)
+  fldl (%rsp)               # st0 = y
+  movsd %xmm0, 8(%rsp)
+  fldl 8(%rsp)              # st0 = x, st1 = y
+.Lresto_reduz:
+  fprem
+  fnstsw %ax
+  test $0x400, %ax          # C2: reducao incompleta
+  jnz .Lresto_reduz
+  fstp %st(1)               # descarta y
+  fstpl (%rsp)
+  movsd (%rsp), %xmm0
+  add $16, %rsp
+  ret
+
+# potencia_inteira: rdi = base, rsi = expoente; resultado em rax
+# Quadrados sucessivos (o produto transborda como a multiplicacao); com expoente
+# negativo 1/base^n trunca para 0, exceto para as bases 1 e -1
+potencia_inteira:
+  mov $1, %rax
+  test %rsi, %rsi
+  js .Lpot_int_negativo
+.Lpot_int_laco:
+  test %rsi, %rsi
+  jz .Lpot_int_fim
+  test $1, %rsi
+  jz .Lpot_int_quadrado
+  imul %rdi, %rax
+.Lpot_int_quadrado:
+  imul %rdi, %rdi
+  shr %rsi
+  jmp .Lpot_int_laco
+.Lpot_int_negativo:
+  cmp $1, %rdi
+  je .Lpot_int_fim
+  xor %rax, %rax
+  cmp $-1, %rdi
+  jne .Lpot_int_fim
+  mov $1, %rax
+  test $1, %rsi
+  jz .Lpot_int_fim
+  mov $-1, %rax
+.Lpot_int_fim:
   ret
 
 sair:
@@ -58,7 +1366,93 @@ sair:
   xor %rdi, %rdi    # codigo de saida (0)
   syscall
 
+# divisao_por_zero: rdi = linha, rsi = coluna do operador (nao retorna)
+divisao_por_zero:
+  mov %rsi, %rdx
+  mov %rdi, %rsi
+  lea texto_divisao_zero(%rip), %rdi
+  jmp erro_execucao
 
-  .section .bss
-  .lcomm buffer, 21
+# deslocamento_negativo: rdi = linha, rsi = coluna do operador (nao retorna)
+deslocamento_negativo:
+  mov %rsi, %rdx
+  mov %rdi, %rsi
+  lea texto_deslocamento_negativo(%rip), %rdi
+  jmp erro_execucao
+
+# passo_zero: rdi = linha, rsi = coluna do 'para' (nao retorna)
+passo_zero:
+  mov %rsi, %rdx
+  mov %rdi, %rsi
+  lea texto_passo_zero(%rip), %rdi
+  jmp erro_execucao
+
+# erro_execucao: rdi = mensagem (terminada em \0), rsi = linha, rdx = coluna
+# Escreve "Erro de execução: <mensagem> em linha L, coluna C" em stderr e
+# encerra com codigo 1 (nao retorna)
+erro_execucao:
+  mov %rsi, %rbx
+  mov %rdx, %r12
+  mov %rdi, %r13
+  movq $2, descritor_saida(%rip)  # stderr
+  lea texto_erro_execucao(%rip), %rdi
+  call imprime_texto
+  mov %r13, %rdi
+  call imprime_texto
+  lea texto_em_linha(%rip), %rdi
+  call imprime_texto
+  mov %rbx, %rdi
+  call imprime_inteiro
+  lea texto_coluna(%rip), %rdi
+  call imprime_texto
+  mov %r12, %rdi
+  call imprime_inteiro
+  call imprime_nova_linha
+  mov $60, %rax     # sys_exit
+  mov $1, %rdi      # codigo de saida (1)
+  syscall
 
+
+  .section .rodata
+espaco:             .ascii " "
+nova_linha:         .ascii "\n"
+texto_verdadeiro:   .ascii "verdadeiro"
+texto_falso:        .ascii "falso"
+texto_nan:          .ascii "NaN"
+texto_mais_inf:     .ascii "+Inf"
+texto_menos_inf:    .ascii "-Inf"
+texto_erro_execucao: .asciz "Erro de execução: "
+texto_em_linha:     .asciz " em linha "
+texto_coluna:       .asciz ", coluna "
+texto_divisao_zero: .asciz "divisão por zero"
+texto_deslocamento_negativo: .asciz "deslocamento negativo"
+texto_indice_fora:  .asciz "índice fora dos limites da lista"
+texto_passo_zero:   .asciz "passo do laço 'para' não pode ser zero"
+texto_chave_ausente: .asciz "chave não encontrada no mapa"
+abre_colchete:      .ascii "["
+fecha_colchete:     .ascii "]"
+separador_lista:    .ascii ", "
+abre_chave:         .ascii "{"
+fecha_chave:        .ascii "}"
+separador_mapa:     .ascii ": "
+  .balign 8
+log10_2:            .double 0.30102999566398114
+epsilon_estimativa: .double 1e-10
+
+  .section .data
+  .balign 8
+descritor_saida:    .quad 1
+
+  .section .bss
+  .lcomm buffer, 32
+  .lcomm heap_atual, 8
+  .lcomm heap_limite, 8
+  .lcomm texto_inicio, 8
+  .lcomm descritor_anterior, 8
+  .lcomm buffer_decimal, 64
+  .lcomm dec_digitos, 32
+  .lcomm bn_r, BN_LIMBS*8
+  .lcomm bn_s, BN_LIMBS*8
+  .lcomm bn_mp, BN_LIMBS*8
+  .lcomm bn_mm, BN_LIMBS*8
+  .lcomm bn_t, BN_LIMBS*8
@@ -14,25 +14,82 @@ import (
 	"github.com/khevencolino/Solar/internal/utils"
 )
 
+// registradoresArgumentos são os registradores de argumentos inteiros da System V AMD64 ABI
+var registradoresArgumentos = []string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
+
+// registradoresDecimais são os registradores de argumentos de ponto flutuante da System V AMD64 ABI
+var registradoresDecimais = []string{"%xmm0", "%xmm1", "%xmm2", "%xmm3", "%xmm4", "%xmm5", "%xmm6", "%xmm7"}
+
+// posicaoParametro indica onde a ABI coloca um argumento: em um registrador ou,
+// quando os registradores da sua classe acabam, na pilha (índice entre os da pilha)
+type posicaoParametro struct {
+	registrador string
+	decimal     bool
+	pilha       int
+}
+
+// destinoLaco guarda os rótulos de saída e de continuação de um laço envolvente
+type destinoLaco struct {
+	rotulo    string // rótulo do laço no código fonte ("" se não houver)
+	parar     string
+	continuar string
+}
+
 type X86_64Backend struct {
-	output     strings.Builder
-	variables  map[string]bool
-	decimals   map[string]float64
-	strings    map[string]string
-	labelCount int
-	functions  map[string]*parser.FuncaoDeclaracao
+	output      strings.Builder
+	variables   map[string]bool // variáveis globais (armazenadas em .data)
+	decimals    map[string]float64
+	strings     map[string]string
+	labelCount  int
+	functions   map[string]*parser.FuncaoDeclaracao
+	tipos       map[parser.Expressao]parser.Tipo // tipos inferidos pelo TypeChecker
+	declarados  *parser.TiposDeclarados          // registros e enumerações da compilação
+	impressoras map[string]string                // rotina de impressão de lista, mapa ou registro -> corpo
+
+	palavrasGlobais map[string]int // palavras das globais que guardam registros
+
+	// Estado do quadro de pilha (stack frame) sendo gerado
+	escopos      []map[string]int // escopos locais: nome -> deslocamento relativo a %rbp
+	slotsUsados  int              // bytes já reservados abaixo de %rbp
+	maiorQuadro  int              // maior valor de slotsUsados no quadro atual
+	profundidade int              // palavras empilhadas além do quadro (controle de alinhamento)
+	quadros      map[string]int   // marcador do quadro -> tamanho a reservar com sub
+	rotuloSaida  string           // rótulo do epílogo da função sendo gerada
+	lacos        []destinoLaco    // laços envolventes, do mais externo ao mais interno
+
+	usaLibm bool // o programa chama o pow da libm e é ligado dinamicamente a ela
 }
 
 func NewX86_64Backend() *X86_64Backend {
 	return &X86_64Backend{
-		variables: make(map[string]bool),
-		decimals:  make(map[string]float64),
-		strings:   make(map[string]string),
-		functions: make(map[string]*parser.FuncaoDeclaracao),
+		variables:   make(map[string]bool),
+		decimals:    make(map[string]float64),
+		strings:     make(map[string]string),
+		functions:   make(map[string]*parser.FuncaoDeclaracao),
+		quadros:     make(map[string]int),
+		impressoras: make(map[string]string),
+
+		palavrasGlobais: make(map[string]int),
+		declarados:      parser.NovosTiposDeclarados(),
 	}
 }
 
-func (a *X86_64Backend) GetName() string      { return "Assembly x86-64" }
+func (a *X86_64Backend) GetName() string { return "Assembly x86-64" }
+
+// DefinirTipos recebe os tipos inferidos na checagem e os registros e enumerações
+// declarados (implementa backends.BackendTipado)
+func (a *X86_64Backend) DefinirTipos(tipos map[parser.Expressao]parser.Tipo, declarados *parser.TiposDeclarados) {
+	a.tipos = tipos
+	a.declarados = declarados
+}
+
+// tipoDe retorna o tipo inferido da expressão (inteiro se desconhecido)
+func (a *X86_64Backend) tipoDe(expr parser.Expressao) parser.Tipo {
+	if tp, ok := a.tipos[expr]; ok {
+		return tp
+	}
+	return parser.TipoInteiro
+}
 func (a *X86_64Backend) GetExtension() string { return ".s" }
 
 func (a *X86_64Backend) Compile(statements []parser.Expressao) error {
@@ -49,6 +106,18 @@ func (a *X86_64Backend) Compile(statements []parser.Expressao) error {
 		}
 	}
 
+	// Variáveis atribuídas no nível do módulo são globais (.data) e visíveis
+	// dentro das funções, que são geradas antes do código de inicialização
+	for _, s := range statements {
+		if atr, ok := s.(*parser.Atribuicao); ok {
+			if tipo := a.tipoDe(atr.Valor); a.declarados.EhRegistro(tipo) {
+				a.declararRegistro(atr.Nome, tipo)
+			} else {
+				a.declararVariavel(atr.Nome)
+			}
+		}
+	}
+
 	a.gerarPrologo()
 
 	// Emite corpos de funções antes do _start
@@ -66,20 +135,20 @@ func (a *X86_64Backend) Compile(statements []parser.Expressao) error {
 	// Gera o ponto de entrada _start
 	a.gerarPontoEntrada()
 
-	// Se existe função principal(), chama ela. Senão, executa statements globais
+	// Inicializa o módulo: statements globais em ordem de código fonte
+	// (símbolos importados já vêm antes dos statements do programa)
+	for i, stmt := range statements {
+		// Pula declarações de função pois já foram processadas
+		if _, ok := stmt.(*parser.FuncaoDeclaracao); !ok {
+			debug.Printf("  Processando statement global %d...\n", i+1)
+			a.checarExpressao(stmt)
+		}
+	}
+
+	// Com as globais inicializadas, chama a função principal() se existir
 	if funcaoPrincipal != nil {
 		debug.Printf("  Chamando função principal()...\n")
-		// Chama a função principal()
-		a.output.WriteString("    call func_principal\n")
-	} else {
-		// Processa statements globais (comportamento antigo)
-		for i, stmt := range statements {
-			// Pula declarações de função pois já foram processadas
-			if _, ok := stmt.(*parser.FuncaoDeclaracao); !ok {
-				debug.Printf("  Processando statement global %d...\n", i+1)
-				a.checarExpressao(stmt)
-			}
-		}
+		a.chamar("func_principal")
 	}
 
 	a.gerarEpilogo()
@@ -126,38 +195,101 @@ func (a *X86_64Backend) LiteralTexto(literal *parser.LiteralTexto) interface{} {
 	return nil
 }
 
+// TextoInterpolado avalia todas as partes (empilhadas) e só então as escreve entre
+// texto_inicia e texto_finaliza: nesse intervalo o runtime acumula a saída em um texto
+// novo no heap, devolvido em %rax
+func (a *X86_64Backend) TextoInterpolado(texto *parser.TextoInterpolado) interface{} {
+	for _, parte := range texto.Partes {
+		parte.Aceitar(a)
+		a.empilhar("%rax")
+	}
+	a.chamar("texto_inicia")
+	for i, parte := range texto.Partes {
+		a.output.WriteString(fmt.Sprintf("    mov %d(%%rsp), %%rax\n", (len(texto.Partes)-1-i)*8))
+		a.imprimirValor(a.tipoDe(parte))
+	}
+	a.chamar("texto_finaliza")
+	a.output.WriteString(fmt.Sprintf("    add $%d, %%rsp\n", len(texto.Partes)*8))
+	a.profundidade -= len(texto.Partes)
+	return nil
+}
+
 func (a *X86_64Backend) LiteralDecimal(literal *parser.LiteralDecimal) interface{} {
 	// Implementa suporte a números de ponto flutuante usando SSE (armazenados em .data)
 	id := a.reserveID()
 	label := fmt.Sprintf("decimal_%d", id)
 	a.declararDecimal(label, literal.Valor)                                   // Armazena como double
 	a.output.WriteString(fmt.Sprintf("    movsd %s(%%rip), %%xmm0\n", label)) // Carrega em XMM0
-	a.output.WriteString("    cvttsd2si %xmm0, %rax\n")                       // Converte p/ inteiro em %rax (compatibilidade)
+	a.output.WriteString("    movq %xmm0, %rax\n")                            // Bits do double em %rax, como os demais valores
 
 	return nil
 }
 
 func (a *X86_64Backend) Variavel(variavel *parser.Variavel) interface{} {
-	a.output.WriteString(fmt.Sprintf("    mov %s(%%rip), %%rax\n", a.getVarName(variavel.Nome)))
+	// Um registro é usado pelo endereço das palavras guardadas na variável
+	if a.declarados.EhRegistro(a.tipoDe(variavel)) {
+		a.output.WriteString(fmt.Sprintf("    lea %s, %%rax\n", a.enderecoVariavel(variavel.Nome)))
+		return nil
+	}
+	if a.gerarVariante(variavel) {
+		return nil
+	}
+	a.output.WriteString(fmt.Sprintf("    mov %s, %%rax\n", a.enderecoVariavel(variavel.Nome)))
 	return nil
 }
 
 func (a *X86_64Backend) Atribuicao(atribuicao *parser.Atribuicao) interface{} {
-	a.declararVariavel(atribuicao.Nome)
 	atribuicao.Valor.Aceitar(a)
-	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s(%%rip)\n", a.getVarName(atribuicao.Nome)))
+
+	// Mesma regra do TypeChecker: anotação de tipo declara no escopo atual,
+	// sem anotação reatribui a variável visível mais próxima ou declara uma nova
+	declarar := atribuicao.TipoAnotado != nil || !a.variavelVisivel(atribuicao.Nome)
+	if tipo := a.tipoDe(atribuicao.Valor); a.declarados.EhRegistro(tipo) {
+		if declarar {
+			a.declararRegistro(atribuicao.Nome, tipo)
+		}
+		a.gerarAtribuicaoRegistro(atribuicao.Nome, tipo)
+		return nil
+	}
+	if declarar {
+		a.declararLocal(atribuicao.Nome)
+	}
+	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", a.enderecoVariavel(atribuicao.Nome)))
 	return nil
 }
 
 func (a *X86_64Backend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interface{} {
+	// Operadores lógicos: salto condicional sobre o operando direito (curto-circuito)
+	if operacao.Operador == parser.E_LOGICO || operacao.Operador == parser.OU_LOGICO {
+		a.gerarOperacaoLogica(operacao)
+		return nil
+	}
+
 	// Operando esquerdo
 	operacao.OperandoEsquerdo.Aceitar(a)
-	a.output.WriteString("    push %rax\n")
+	a.empilhar("%rax")
 
 	// Operando direito
 	operacao.OperandoDireito.Aceitar(a)
 	a.output.WriteString("    mov %rax, %rbx\n")
-	a.output.WriteString("    pop %rax\n")
+	a.desempilhar("%rax")
+
+	// Os dois operandos têm o mesmo tipo (garantido pelo TypeChecker)
+	if a.tipoDe(operacao.OperandoEsquerdo) == parser.TipoDecimal {
+		a.gerarOperacaoDecimal(operacao.Operador)
+		return nil
+	}
+	if a.tipoDe(operacao.OperandoEsquerdo) == parser.TipoTexto {
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.output.WriteString("    mov %rbx, %rsi\n")
+		if operacao.Operador == parser.ADICAO {
+			a.chamar("texto_concatena")
+			return nil
+		}
+		// texto_compara devolve -1, 0 ou 1, que segue para as comparações com 0
+		a.chamar("texto_compara")
+		a.output.WriteString("    xor %rbx, %rbx\n")
+	}
 
 	// Operação
 	switch operacao.Operador {
@@ -167,23 +299,22 @@ func (a *X86_64Backend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interf
 		a.output.WriteString("    sub %rbx, %rax\n")
 	case parser.MULTIPLICACAO:
 		a.output.WriteString("    imul %rbx, %rax\n")
-	case parser.DIVISAO:
-		a.output.WriteString("    cqo\n")
-		a.output.WriteString("    idiv %rbx\n")
+	case parser.DIVISAO, parser.RESTO:
+		a.gerarDivisaoInteira(operacao)
 	case parser.POWER:
-		// Exponenciação multiplicações sucessivas
-		id := a.reserveID()
-		powLoop := fmt.Sprintf(".pow_loop_%d", id)
-		powDone := fmt.Sprintf(".pow_done_%d", id)
-		a.output.WriteString("    mov %rax, %rcx\n")  // base -> %rcx
-		a.output.WriteString("    mov $1, %rax\n")    // resultado = 1
-		a.output.WriteString("    test %rbx, %rbx\n") // expoente == 0 ?
-		a.output.WriteString(fmt.Sprintf("    jz %s\n", powDone))
-		a.output.WriteString(fmt.Sprintf("%s:\n", powLoop))
-		a.output.WriteString("    imul %rcx, %rax\n") // resultado *= base
-		a.output.WriteString("    dec %rbx\n")        // expoente--
-		a.output.WriteString(fmt.Sprintf("    jnz %s\n", powLoop))
-		a.output.WriteString(fmt.Sprintf("%s:\n", powDone))
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.output.WriteString("    mov %rbx, %rsi\n")
+		a.chamar("potencia_inteira")
+
+	// Operações bit a bit
+	case parser.E_BIT:
+		a.output.WriteString("    and %rbx, %rax\n")
+	case parser.OU_BIT:
+		a.output.WriteString("    or %rbx, %rax\n")
+	case parser.XOU_BIT:
+		a.output.WriteString("    xor %rbx, %rax\n")
+	case parser.DESLOCAMENTO_ESQUERDA, parser.DESLOCAMENTO_DIREITA:
+		a.gerarDeslocamento(operacao)
 
 	// Operações de comparação
 	case parser.IGUALDADE, parser.DIFERENCA, parser.MENOR_QUE, parser.MAIOR_QUE, parser.MENOR_IGUAL, parser.MAIOR_IGUAL:
@@ -211,12 +342,157 @@ func (a *X86_64Backend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interf
 	return nil
 }
 
+// gerarOperacaoLogica gera 'e'/'ou': se o operando esquerdo (0 ou 1 em %rax) já
+// decide o resultado, ele mesmo é o resultado e o direito não é avaliado
+func (a *X86_64Backend) gerarOperacaoLogica(operacao *parser.OperacaoBinaria) {
+	labelFim := fmt.Sprintf(".logico_fim_%d", a.reserveID())
+	salto := "jz" // 'e': esquerdo falso
+	if operacao.Operador == parser.OU_LOGICO {
+		salto = "jnz" // 'ou': esquerdo verdadeiro
+	}
+	operacao.OperandoEsquerdo.Aceitar(a)
+	a.output.WriteString("    test %rax, %rax\n")
+	a.output.WriteString(fmt.Sprintf("    %s %s\n", salto, labelFim))
+	operacao.OperandoDireito.Aceitar(a)
+	a.output.WriteString(fmt.Sprintf("%s:\n", labelFim))
+}
+
+func (a *X86_64Backend) OperacaoUnaria(operacao *parser.OperacaoUnaria) interface{} {
+	operacao.Operando.Aceitar(a)
+	switch operacao.Operador {
+	case parser.NAO_LOGICO:
+		a.output.WriteString("    xor $1, %rax\n")
+	case parser.NEGACAO:
+		if a.tipoDe(operacao.Operando) == parser.TipoDecimal {
+			a.output.WriteString("    btc $63, %rax\n") // inverte o bit de sinal do double
+		} else {
+			a.output.WriteString("    neg %rax\n")
+		}
+	case parser.NAO_BIT:
+		a.output.WriteString("    not %rax\n")
+	}
+	return nil
+}
+
+// gerarDivisaoInteira divide %rax por %rbx deixando em %rax o quociente (truncado) ou
+// o resto (sinal do dividendo); divisor zero encerra o programa com a posição do operador,
+// e o divisor -1 é tratado à parte (idiv falharia para o menor inteiro)
+func (a *X86_64Backend) gerarDivisaoInteira(operacao *parser.OperacaoBinaria) {
+	id := a.reserveID()
+	divisorOk := fmt.Sprintf(".div_ok_%d", id)
+	divide := fmt.Sprintf(".div_%d", id)
+	fim := fmt.Sprintf(".div_fim_%d", id)
+	a.output.WriteString("    test %rbx, %rbx\n")
+	a.output.WriteString(fmt.Sprintf("    jnz %s\n", divisorOk))
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", operacao.Token.Position.Line))
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rsi\n", operacao.Token.Position.Column))
+	a.output.WriteString("    jmp divisao_por_zero\n") // não retorna
+	a.output.WriteString(fmt.Sprintf("%s:\n", divisorOk))
+	a.output.WriteString("    cmp $-1, %rbx\n")
+	a.output.WriteString(fmt.Sprintf("    jne %s\n", divide))
+	if operacao.Operador == parser.RESTO {
+		a.output.WriteString("    xor %rax, %rax\n") // x % -1 == 0
+	} else {
+		a.output.WriteString("    neg %rax\n") // x / -1 == -x
+	}
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", fim))
+	a.output.WriteString(fmt.Sprintf("%s:\n", divide))
+	a.output.WriteString("    cqo\n")
+	a.output.WriteString("    idiv %rbx\n")
+	if operacao.Operador == parser.RESTO {
+		a.output.WriteString("    mov %rdx, %rax\n")
+	}
+	a.output.WriteString(fmt.Sprintf("%s:\n", fim))
+}
+
+// gerarDeslocamento desloca %rax por %rbx bits; contagem negativa encerra o programa com a
+// posição do operador. shl/sar usam só os 6 bits baixos de %cl, então contagens de 64 ou mais
+// são tratadas à parte: '<<' resulta em 0 e '>>' desloca 63 (só o bit de sinal)
+func (a *X86_64Backend) gerarDeslocamento(operacao *parser.OperacaoBinaria) {
+	id := a.reserveID()
+	contagemOk := fmt.Sprintf(".desl_ok_%d", id)
+	fim := fmt.Sprintf(".desl_fim_%d", id)
+	a.output.WriteString("    test %rbx, %rbx\n")
+	a.output.WriteString(fmt.Sprintf("    jns %s\n", contagemOk))
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", operacao.Token.Position.Line))
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rsi\n", operacao.Token.Position.Column))
+	a.output.WriteString("    jmp deslocamento_negativo\n") // não retorna
+	a.output.WriteString(fmt.Sprintf("%s:\n", contagemOk))
+	a.output.WriteString("    mov $63, %rcx\n")
+	a.output.WriteString("    cmp %rcx, %rbx\n")
+	a.output.WriteString("    cmovbe %rbx, %rcx\n")
+	if operacao.Operador == parser.DESLOCAMENTO_DIREITA {
+		a.output.WriteString("    sar %cl, %rax\n")
+	} else {
+		a.output.WriteString("    shl %cl, %rax\n")
+		a.output.WriteString("    cmp $63, %rbx\n")
+		a.output.WriteString(fmt.Sprintf("    jbe %s\n", fim))
+		a.output.WriteString("    xor %rax, %rax\n")
+	}
+	a.output.WriteString(fmt.Sprintf("%s:\n", fim))
+}
+
+// gerarOperacaoDecimal opera sobre os bits dos doubles em %rax (esquerdo) e %rbx (direito)
+// usando SSE; o resultado volta para %rax
+func (a *X86_64Backend) gerarOperacaoDecimal(operador parser.TipoOperador) {
+	a.output.WriteString("    movq %rax, %xmm0\n")
+	a.output.WriteString("    movq %rbx, %xmm1\n")
+
+	switch operador {
+	case parser.ADICAO:
+		a.output.WriteString("    addsd %xmm1, %xmm0\n")
+	case parser.SUBTRACAO:
+		a.output.WriteString("    subsd %xmm1, %xmm0\n")
+	case parser.MULTIPLICACAO:
+		a.output.WriteString("    mulsd %xmm1, %xmm0\n")
+	case parser.DIVISAO:
+		a.output.WriteString("    divsd %xmm1, %xmm0\n")
+	case parser.RESTO:
+		a.chamar("resto_decimal")
+	case parser.POWER:
+		// pow da libm: como as rotinas do runtime, preserva os registradores callee-saved
+		a.usaLibm = true
+		a.chamar("pow")
+
+	// Comparações: ucomisd marca operandos NaN como não ordenados (PF=1),
+	// então NaN só é diferente de tudo
+	case parser.IGUALDADE:
+		a.output.WriteString("    ucomisd %xmm1, %xmm0\n")
+		a.output.WriteString("    sete %al\n")
+		a.output.WriteString("    setnp %cl\n")
+		a.output.WriteString("    and %cl, %al\n")
+		a.output.WriteString("    movzx %al, %rax\n")
+		return
+	case parser.DIFERENCA:
+		a.output.WriteString("    ucomisd %xmm1, %xmm0\n")
+		a.output.WriteString("    setne %al\n")
+		a.output.WriteString("    setp %cl\n")
+		a.output.WriteString("    or %cl, %al\n")
+		a.output.WriteString("    movzx %al, %rax\n")
+		return
+	case parser.MENOR_QUE, parser.MAIOR_QUE, parser.MENOR_IGUAL, parser.MAIOR_IGUAL:
+		// seta/setae são falsos para não ordenados; para < e <= inverte os operandos
+		instr := "seta"
+		if operador == parser.MENOR_IGUAL || operador == parser.MAIOR_IGUAL {
+			instr = "setae"
+		}
+		if operador == parser.MENOR_QUE || operador == parser.MENOR_IGUAL {
+			a.output.WriteString("    ucomisd %xmm0, %xmm1\n")
+		} else {
+			a.output.WriteString("    ucomisd %xmm1, %xmm0\n")
+		}
+		a.output.WriteString(fmt.Sprintf("    %s %%al\n", instr))
+		a.output.WriteString("    movzx %al, %rax\n")
+		return
+	}
+
+	a.output.WriteString("    movq %xmm0, %rax\n")
+}
+
 func (a *X86_64Backend) ChamadaFuncao(chamada *parser.ChamadaFuncao) interface{} {
 	// Função de usuário: chamada direta por label
 	if _, ok := a.functions[chamada.Nome]; ok {
-		a.prepararArgumentos(chamada.Argumentos)
-		a.output.WriteString(fmt.Sprintf("    call func_%s\n", chamada.Nome))
-		a.limparArgumentosPilha(len(chamada.Argumentos))
+		a.gerarChamadaUsuario(chamada.Nome, chamada.Argumentos)
 		return nil
 	}
 	// Valida a função usando o registro
@@ -241,15 +517,67 @@ func (a *X86_64Backend) ChamadaFuncao(chamada *parser.ChamadaFuncao) interface{}
 		a.gerarAssemblyImprime(chamada.Argumentos)
 	case registry.FUNCAO_PURA:
 		a.gerarAssemblyFuncaoPura(chamada.Nome, chamada.Argumentos)
+	case registry.FUNCAO_RUNTIME:
+		if tipo := a.tipoDe(chamada.Argumentos[0]); tipo.EhLista() {
+			a.gerarChamadaRotina(assinatura.RotinaLista, chamada.Argumentos)
+		} else if tipo.EhMapa() {
+			a.gerarChamadaRotina(assinatura.RotinaMapa, chamada.Argumentos)
+		} else {
+			a.gerarChamadaRotina(assinatura.Rotina, chamada.Argumentos)
+		}
 	}
 	return nil
 }
 
+// gerarChamadaRotina chama uma rotina do runtime com os argumentos em %rdi, %rsi e %rdx
+func (a *X86_64Backend) gerarChamadaRotina(rotina string, argumentos []parser.Expressao) {
+	for _, argumento := range argumentos {
+		argumento.Aceitar(a)
+		a.gerarCopiaHeap(a.tipoDe(argumento))
+		a.empilhar("%rax")
+	}
+	registradores := []string{"%rdi", "%rsi", "%rdx"}
+	for i := len(argumentos) - 1; i >= 0; i-- {
+		a.desempilhar(registradores[i])
+	}
+	a.chamar(rotina)
+}
+
 // gerarAssemblyImprime gera código assembly para a função imprime
+// Os argumentos saem na mesma linha, separados por espaço, com a rotina do
+// runtime escolhida pelo tipo de cada argumento
 func (a *X86_64Backend) gerarAssemblyImprime(argumentos []parser.Expressao) {
-	for _, argumento := range argumentos {
+	for i, argumento := range argumentos {
+		if i > 0 {
+			a.chamar("imprime_espaco")
+		}
 		argumento.Aceitar(a)
-		a.output.WriteString("    call imprime_num\n")
+		a.imprimirValor(a.tipoDe(argumento))
+	}
+	a.chamar("imprime_nova_linha")
+	a.output.WriteString("    xor %rax, %rax\n") // imprime retorna 0
+}
+
+// imprimirValor escreve o valor em %rax com a rotina do runtime para o seu tipo
+func (a *X86_64Backend) imprimirValor(tipo parser.Tipo) {
+	if tipo.EhLista() || tipo.EhMapa() || a.declarados.Declarado(tipo) {
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.chamar(a.rotinaImpressao(tipo))
+		return
+	}
+	switch tipo {
+	case parser.TipoTexto:
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.chamar("imprime_texto")
+	case parser.TipoBooleano:
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.chamar("imprime_booleano")
+	case parser.TipoDecimal:
+		a.output.WriteString("    movq %rax, %xmm0\n")
+		a.chamar("imprime_decimal")
+	default:
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.chamar("imprime_inteiro")
 	}
 }
 
@@ -272,10 +600,34 @@ func (a *X86_64Backend) gerarPrologo() {
 
 func (a *X86_64Backend) gerarPontoEntrada() {
 	a.output.WriteString("_start:\n")
+	// O código global também tem um quadro, usado pelas variáveis locais de blocos
+	a.iniciarQuadro(0)
+	a.output.WriteString("    mov %rsp, %rbp\n")
+	a.output.WriteString(fmt.Sprintf("    sub $%s, %%rsp\n", a.marcadorQuadro("_start")))
+}
+
+// escaparAscii escreve um texto como conteúdo de uma diretiva .ascii: aspas e barras
+// invertidas são escapadas e bytes fora do ASCII imprimível (controle, UTF-8) viram octal
+func escaparAscii(texto string) string {
+	var resultado strings.Builder
+	for i := 0; i < len(texto); i++ {
+		switch c := texto[i]; {
+		case c == '"' || c == '\\':
+			resultado.WriteByte('\\')
+			resultado.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			resultado.WriteString(fmt.Sprintf("\\%03o", c))
+		default:
+			resultado.WriteByte(c)
+		}
+	}
+	return resultado.String()
 }
 
 func (a *X86_64Backend) gerarEpilogo() {
 	a.output.WriteString("    call sair\n\n")
+	a.finalizarQuadro("_start", 0)
+	a.gerarImpressoras()
 
 	// Adiciona seção de dados para variáveis, decimais e strings
 	if len(a.variables) > 0 || len(a.decimals) > 0 || len(a.strings) > 0 {
@@ -283,6 +635,10 @@ func (a *X86_64Backend) gerarEpilogo() {
 
 		// Variáveis inteiras
 		for varName := range a.variables {
+			if palavras := a.palavrasGlobais[varName]; palavras > 0 {
+				dataSection += fmt.Sprintf("%s: .zero %d\n", a.getVarName(varName), palavras*8)
+				continue
+			}
 			dataSection += fmt.Sprintf("%s: .quad 0\n", a.getVarName(varName))
 		}
 
@@ -296,9 +652,7 @@ func (a *X86_64Backend) gerarEpilogo() {
 		// Strings
 		for label, valor := range a.strings {
 			// Escapa caracteres especiais e adiciona terminador nulo
-			escapedStr := strings.ReplaceAll(valor, "\\", "\\\\")
-			escapedStr = strings.ReplaceAll(escapedStr, "\"", "\\\"")
-			dataSection += fmt.Sprintf("%s: .ascii \"%s\\0\"\n", label, escapedStr)
+			dataSection += fmt.Sprintf("%s: .ascii \"%s\\0\"\n", label, escaparAscii(valor))
 		}
 
 		// Substitui seção de dados no início
@@ -306,33 +660,56 @@ func (a *X86_64Backend) gerarEpilogo() {
 		a.output.Reset()
 		a.output.WriteString(fullCode)
 	}
+
+	// Substitui os marcadores pelos tamanhos finais dos quadros de pilha
+	fullCode := a.output.String()
+	for marcador, tamanho := range a.quadros {
+		fullCode = strings.ReplaceAll(fullCode, "$"+marcador+",", fmt.Sprintf("$%d,", tamanho))
+	}
+	a.output.Reset()
+	a.output.WriteString(fullCode)
 }
 
 func (a *X86_64Backend) ComandoSe(comando *parser.ComandoSe) interface{} {
-	// Reserva um ID para os labels deste if (fim / senao compartilham mesmo id)
+	// Reserva um ID para os labels deste if (fim / senao compartilham mesmo id);
+	// cada ramo "senao se" k testa sua condição em .if_senao_N_k
 	id := a.reserveID()
 	labelFim := fmt.Sprintf(".if_fim_%d", id)
 	labelSenao := fmt.Sprintf(".if_senao_%d", id)
+	labelRamo := func(k int) string {
+		if k < len(comando.SenaoSe) {
+			return fmt.Sprintf("%s_%d", labelSenao, k)
+		}
+		if comando.BlocoSenao != nil {
+			return labelSenao
+		}
+		return labelFim
+	}
 
 	// Avalia a condição
 	comando.Condicao.Aceitar(a)
 
-	// Testa se o resultado da condição é 0 (falso)
+	// Testa se o resultado da condição é 0 (falso) e pula para o próximo ramo
 	a.output.WriteString("    test %rax, %rax\n")
-
-	if comando.BlocoSenao != nil {
-		// Se há bloco senao, pula para o label senao se for falso
-		a.output.WriteString(fmt.Sprintf("    jz %s\n", labelSenao))
-	} else {
-		// Se não há bloco senao, pula para o fim se for falso
-		a.output.WriteString(fmt.Sprintf("    jz %s\n", labelFim))
-	}
+	a.output.WriteString(fmt.Sprintf("    jz %s\n", labelRamo(0)))
 
 	// Executa o bloco do "se"
 	comando.BlocoSe.Aceitar(a)
 
+	for k, ramo := range comando.SenaoSe {
+		// Pula para o fim após executar o ramo anterior
+		a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelFim))
+
+		// Label do ramo "senao se": testa a condição dele
+		a.output.WriteString(fmt.Sprintf("%s:\n", labelRamo(k)))
+		ramo.Condicao.Aceitar(a)
+		a.output.WriteString("    test %rax, %rax\n")
+		a.output.WriteString(fmt.Sprintf("    jz %s\n", labelRamo(k+1)))
+		ramo.Bloco.Aceitar(a)
+	}
+
 	if comando.BlocoSenao != nil {
-		// Pula para o fim após executar o bloco "se"
+		// Pula para o fim após executar o último ramo
 		a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelFim))
 
 		// Label para o bloco "senao"
@@ -347,6 +724,10 @@ func (a *X86_64Backend) ComandoSe(comando *parser.ComandoSe) interface{} {
 }
 
 func (a *X86_64Backend) Bloco(bloco *parser.Bloco) interface{} {
+	// Cada bloco abre um escopo próprio
+	a.abrirEscopo()
+	defer a.fecharEscopo()
+
 	// Executa todos os comandos do bloco
 	for _, comando := range bloco.Comandos {
 		comando.Aceitar(a)
@@ -364,8 +745,10 @@ func (a *X86_64Backend) ComandoEnquanto(cmd *parser.ComandoEnquanto) interface{}
 	// Jump para condição
 	a.output.WriteString(fmt.Sprintf("    jmp %s\n", lcond))
 	a.output.WriteString(fmt.Sprintf("%s:\n", lbody))
-	// Corpo
+	// Corpo ('continuar' volta para a condição)
+	a.entrarLaco(cmd.Rotulo, lend, lcond)
 	cmd.Corpo.Aceitar(a)
+	a.sairLaco()
 	// Volta para condição
 	a.output.WriteString(fmt.Sprintf("    jmp %s\n", lcond))
 	// Condição
@@ -384,6 +767,10 @@ func (a *X86_64Backend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 	lstep := fmt.Sprintf(".for_step_%d", id)
 	lend := fmt.Sprintf(".for_end_%d", id)
 
+	// A variável de controle vive em um escopo que envolve o laço
+	a.abrirEscopo()
+	defer a.fecharEscopo()
+
 	// init
 	if cmd.Inicializacao != nil {
 		cmd.Inicializacao.Aceitar(a)
@@ -397,9 +784,11 @@ func (a *X86_64Backend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 		a.output.WriteString(fmt.Sprintf("    jz %s\n", lend))
 	}
 
-	// Corpo
+	// Corpo ('continuar' segue para o passo)
 	a.output.WriteString(fmt.Sprintf("%s:\n", lbody))
+	a.entrarLaco(cmd.Rotulo, lend, lstep)
 	cmd.Corpo.Aceitar(a)
+	a.sairLaco()
 	a.output.WriteString(fmt.Sprintf("    jmp %s\n", lstep))
 
 	// Passo
@@ -413,23 +802,244 @@ func (a *X86_64Backend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 	return nil
 }
 
+// ComandoParaCada percorre a lista com um índice escondido; a lista e o tamanho são
+// guardados no quadro antes da primeira iteração e os dados lidos a cada uma. Um mapa
+// é percorrido por cópias das suas chaves e valores feitas no início do laço
+func (a *X86_64Backend) ComandoParaCada(cmd *parser.ComandoParaCada) interface{} {
+	id := a.reserveID()
+	lcond := fmt.Sprintf(".foreach_cond_%d", id)
+	lstep := fmt.Sprintf(".foreach_step_%d", id)
+	lend := fmt.Sprintf(".foreach_end_%d", id)
+	lista := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+	tamanho := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+	indice := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+
+	cmd.Colecao.Aceitar(a)
+	valores := ""
+	if a.tipoDe(cmd.Colecao).EhMapa() {
+		if cmd.Valor != "" {
+			valores = fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+			a.empilhar("%rax")
+			a.output.WriteString("    mov %rax, %rdi\n")
+			a.chamar("mapa_valores")
+			a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", valores))
+			a.desempilhar("%rax")
+		}
+		a.output.WriteString("    mov %rax, %rdi\n")
+		a.chamar("mapa_chaves")
+	}
+	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", lista))
+	a.output.WriteString("    mov (%rax), %rcx\n")
+	a.output.WriteString(fmt.Sprintf("    mov %%rcx, %s\n", tamanho))
+	a.output.WriteString(fmt.Sprintf("    movq $0, %s\n", indice))
+
+	// Condição
+	a.output.WriteString(fmt.Sprintf("%s:\n", lcond))
+	a.output.WriteString(fmt.Sprintf("    mov %s, %%rax\n", indice))
+	a.output.WriteString(fmt.Sprintf("    cmp %s, %%rax\n", tamanho))
+	a.output.WriteString(fmt.Sprintf("    jge %s\n", lend))
+
+	// Corpo, com o elemento (ou a chave) atual em %rax e o valor em %rdx
+	variaveis := []string{cmd.Variavel}
+	elementos := []parser.Tipo{a.tipoDe(cmd.Colecao).Elemento()}
+	if tipo := a.tipoDe(cmd.Colecao); tipo.EhMapa() {
+		elementos[0] = tipo.Chave()
+	}
+	if valores != "" {
+		a.output.WriteString(fmt.Sprintf("    mov %s, %%rcx\n", valores))
+		a.output.WriteString("    mov 16(%rcx), %rcx\n")
+		a.output.WriteString("    mov (%rcx,%rax,8), %rdx\n")
+		variaveis = append(variaveis, cmd.Valor)
+		elementos = append(elementos, a.tipoDe(cmd.Colecao).Valor())
+	}
+	a.output.WriteString(fmt.Sprintf("    mov %s, %%rcx\n", lista))
+	a.output.WriteString("    mov 16(%rcx), %rcx\n")
+	a.output.WriteString("    mov (%rcx,%rax,8), %rax\n")
+	a.gerarCorpoIteracao(variaveis, elementos, cmd.Rotulo, cmd.Corpo, lend, lstep)
+
+	// Passo
+	a.output.WriteString(fmt.Sprintf("%s:\n", lstep))
+	a.output.WriteString(fmt.Sprintf("    incq %s\n", indice))
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", lcond))
+	a.output.WriteString(fmt.Sprintf("%s:\n", lend))
+	return nil
+}
+
+// ComandoParaIntervalo avalia início, fim e passo uma única vez (no quadro) e conta com
+// um contador escondido; com passo negativo a comparação com o fim se inverte
+func (a *X86_64Backend) ComandoParaIntervalo(cmd *parser.ComandoParaIntervalo) interface{} {
+	id := a.reserveID()
+	lcond := fmt.Sprintf(".range_cond_%d", id)
+	ldesce := fmt.Sprintf(".range_desce_%d", id)
+	lbody := fmt.Sprintf(".range_body_%d", id)
+	lstep := fmt.Sprintf(".range_step_%d", id)
+	lend := fmt.Sprintf(".range_end_%d", id)
+	contador := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+	fim := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+
+	cmd.Inicio.Aceitar(a)
+	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", contador))
+	cmd.Fim.Aceitar(a)
+	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", fim))
+
+	passo := ""
+	if cmd.Passo != nil {
+		passo = fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+		passoOk := fmt.Sprintf(".range_passo_ok_%d", id)
+		cmd.Passo.Aceitar(a)
+		a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", passo))
+		a.output.WriteString("    test %rax, %rax\n")
+		a.output.WriteString(fmt.Sprintf("    jnz %s\n", passoOk))
+		a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", cmd.Token.Position.Line))
+		a.output.WriteString(fmt.Sprintf("    mov $%d, %%rsi\n", cmd.Token.Position.Column))
+		a.output.WriteString("    jmp passo_zero\n") // não retorna
+		a.output.WriteString(fmt.Sprintf("%s:\n", passoOk))
+	}
+
+	// Condição: sai quando o contador passa do fim ('..' também sai ao alcançá-lo)
+	sobe, desce := "jge", "jle"
+	if cmd.Inclusivo {
+		sobe, desce = "jg", "jl"
+	}
+	a.output.WriteString(fmt.Sprintf("%s:\n", lcond))
+	a.output.WriteString(fmt.Sprintf("    mov %s, %%rax\n", contador))
+	if cmd.Passo != nil {
+		a.output.WriteString(fmt.Sprintf("    cmpq $0, %s\n", passo))
+		a.output.WriteString(fmt.Sprintf("    jl %s\n", ldesce))
+	}
+	a.output.WriteString(fmt.Sprintf("    cmp %s, %%rax\n", fim))
+	a.output.WriteString(fmt.Sprintf("    %s %s\n", sobe, lend))
+	if cmd.Passo != nil {
+		a.output.WriteString(fmt.Sprintf("    jmp %s\n", lbody))
+		a.output.WriteString(fmt.Sprintf("%s:\n", ldesce))
+		a.output.WriteString(fmt.Sprintf("    cmp %s, %%rax\n", fim))
+		a.output.WriteString(fmt.Sprintf("    %s %s\n", desce, lend))
+	}
+
+	// Corpo, com o valor atual em %rax
+	a.output.WriteString(fmt.Sprintf("%s:\n", lbody))
+	a.gerarCorpoIteracao([]string{cmd.Variavel}, []parser.Tipo{parser.TipoInteiro}, cmd.Rotulo, cmd.Corpo, lend, lstep)
+
+	// Passo: se a soma transbordar, o próximo valor passaria do fim e o laço acaba
+	a.output.WriteString(fmt.Sprintf("%s:\n", lstep))
+	if cmd.Passo != nil {
+		a.output.WriteString(fmt.Sprintf("    mov %s, %%rax\n", passo))
+		a.output.WriteString(fmt.Sprintf("    add %%rax, %s\n", contador))
+	} else {
+		a.output.WriteString(fmt.Sprintf("    incq %s\n", contador))
+	}
+	a.output.WriteString(fmt.Sprintf("    jo %s\n", lend))
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", lcond))
+	a.output.WriteString(fmt.Sprintf("%s:\n", lend))
+	return nil
+}
+
+// gerarCorpoIteracao copia %rax (e %rdx, para a segunda) para as variáveis do laço,
+// declaradas em um escopo só do corpo, e gera o corpo ('continuar' segue para o passo)
+func (a *X86_64Backend) gerarCorpoIteracao(variaveis []string, tipos []parser.Tipo, rotulo string, corpo *parser.Bloco, parar, continuar string) {
+	a.abrirEscopo()
+	for i, variavel := range variaveis {
+		registrador := []string{"%rax", "%rdx"}[i]
+		if a.declarados.EhRegistro(tipos[i]) {
+			a.declararRegistro(variavel, tipos[i])
+			a.gerarCopiaRegistro(tipos[i], registrador, "%rbp", a.escopos[len(a.escopos)-1][variavel])
+			continue
+		}
+		a.declararLocal(variavel)
+		a.output.WriteString(fmt.Sprintf("    mov %s, %s\n", registrador, a.enderecoVariavel(variavel)))
+	}
+	a.entrarLaco(rotulo, parar, continuar)
+	corpo.Aceitar(a)
+	a.sairLaco()
+	a.fecharEscopo()
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", continuar))
+}
+
+func (a *X86_64Backend) entrarLaco(rotulo, parar, continuar string) {
+	a.lacos = append(a.lacos, destinoLaco{rotulo: rotulo, parar: parar, continuar: continuar})
+}
+
+func (a *X86_64Backend) sairLaco() { a.lacos = a.lacos[:len(a.lacos)-1] }
+
+// destino retorna o laço alvo de 'parar'/'continuar' (sem rótulo: o mais interno)
+func (a *X86_64Backend) destino(rotulo string) destinoLaco {
+	for i := len(a.lacos) - 1; i >= 0; i-- {
+		if rotulo == "" || a.lacos[i].rotulo == rotulo {
+			return a.lacos[i]
+		}
+	}
+	return destinoLaco{} // inalcançável: o TypeChecker valida os rótulos
+}
+
+func (a *X86_64Backend) Parar(parar *parser.Parar) interface{} {
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", a.destino(parar.Rotulo).parar))
+	return nil
+}
+
+func (a *X86_64Backend) Continuar(continuar *parser.Continuar) interface{} {
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", a.destino(continuar.Rotulo).continuar))
+	return nil
+}
+
 // Declaração/definição de função do usuário
+//
+// Layout do quadro:
+//
+//	16(%rbp)...  argumentos 7+ (passados pela pilha)
+//	 8(%rbp)     endereço de retorno
+//	 0(%rbp)     %rbp de quem chamou
+//	-8(%rbp)     %rbx salvo (callee-saved, usado como temporário)
+//	-16(%rbp)... parâmetros e variáveis locais
 func (a *X86_64Backend) gerarFuncaoUsuario(nome string, fn *parser.FuncaoDeclaracao) {
 	a.output.WriteString(fmt.Sprintf("func_%s:\n", nome))
+	a.output.WriteString("    push %rbp\n")
+	a.output.WriteString("    mov %rsp, %rbp\n")
+	a.output.WriteString("    push %rbx\n")
+	a.output.WriteString(fmt.Sprintf("    sub $%s, %%rsp\n", a.marcadorQuadro(nome)))
+
+	a.iniciarQuadro(8)
+	a.abrirEscopo()
+	a.rotuloSaida = fmt.Sprintf(".func_%s_fim", nome)
 
-	// Extrai os parâmetros da convenção de chamada e armazena em variáveis
+	// Extrai os parâmetros da convenção de chamada para o quadro
 	a.extrairParametros(fn.Parametros)
 
-	// Gerar corpo (usando as variáveis globais dos params)
+	// Gerar corpo; um registro devolvido é copiado para o heap, pois o quadro deixa de existir
 	fn.Corpo.Aceitar(a)
+	if a.declarados.EhRegistro(fn.Retorno) {
+		a.gerarCopiaHeap(fn.Retorno)
+	}
+
+	a.fecharEscopo()
+	a.finalizarQuadro(nome, 8)
 
-	// Resultado esperado em %rax (pela última expressão)
+	// Saída única: todo 'retornar' salta para cá com o resultado em %rax
+	// (sem 'retornar', o resultado é o da última expressão)
+	a.output.WriteString(fmt.Sprintf("%s:\n", a.rotuloSaida))
+	a.rotuloSaida = ""
+	if fn.Retorno == parser.TipoDecimal {
+		a.output.WriteString("    movq %rax, %xmm0\n")
+	}
+	a.output.WriteString("    mov -8(%rbp), %rbx\n")
+	a.output.WriteString("    mov %rbp, %rsp\n")
+	a.output.WriteString("    pop %rbp\n")
 	a.output.WriteString("    ret\n\n")
 }
 
 func (a *X86_64Backend) FuncaoDeclaracao(fn *parser.FuncaoDeclaracao) interface{} { return nil }
-func (a *X86_64Backend) Retorno(ret *parser.Retorno) interface{}                  { return nil }
-func (a *X86_64Backend) Importacao(imp *parser.Importacao) interface{}            { return nil }
+
+func (a *X86_64Backend) Retorno(ret *parser.Retorno) interface{} {
+	if ret.Valor != nil {
+		ret.Valor.Aceitar(a)
+		a.gerarCopiaHeap(a.tipoDe(ret.Valor))
+	} else {
+		a.output.WriteString("    xor %rax, %rax\n")
+	}
+	// O epílogo restaura %rsp a partir de %rbp, descartando o que estiver empilhado
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", a.rotuloSaida))
+	return nil
+}
+func (a *X86_64Backend) Importacao(imp *parser.Importacao) interface{} { return nil }
 
 func (a *X86_64Backend) declararVariavel(nome string) {
 	a.variables[nome] = true
@@ -462,7 +1072,12 @@ func (a *X86_64Backend) compilarAssembly(arquivoAssembly string) error {
 	}
 
 	executavel := "programa"
-	cmdLd := exec.Command("ld", "-o", executavel, objectFile)
+	argumentos := []string{"-o", executavel, objectFile}
+	if a.usaLibm {
+		// Só o '**' decimal precisa da libm; os demais programas continuam estáticos
+		argumentos = append(argumentos, "-dynamic-linker", "/lib64/ld-linux-x86-64.so.2", "-lm")
+	}
+	cmdLd := exec.Command("ld", argumentos...)
 	if err := cmdLd.Run(); err != nil {
 		return fmt.Errorf("erro ao ligar (ld): %v", err)
 	}
@@ -480,70 +1095,196 @@ func (a *X86_64Backend) reserveID() int {
 	return id
 }
 
-// prepararArgumentos implementa a Convenção System V AMD64 ABI para passagem de argumentos
-// Primeiros 6 args em registradores: rdi, rsi, rdx, rcx, r8, r9
-// Args adicionais (7+) são passados pela pilha
-func (a *X86_64Backend) prepararArgumentos(argumentos []parser.Expressao) {
-	regs := []string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
+// gerarChamadaUsuario implementa a Convenção System V AMD64 ABI para passagem de argumentos
+// Inteiros, textos e booleanos nos 6 registradores rdi, rsi, rdx, rcx, r8, r9;
+// decimais em xmm0-xmm7; os que não couberem vão pela pilha, o primeiro no topo
+func (a *X86_64Backend) gerarChamadaUsuario(nome string, argumentos []parser.Expressao) {
+	fn := a.functions[nome]
+	posicoes := classificarParametros(fn.Parametros)
 	n := len(argumentos)
 
-	// Aloca espaço na pilha para argumentos 7+ (se necessário)
-	if n > len(regs) {
-		stackArgs := n - len(regs)
-		stackBytes := stackArgs * 8
-		// Alinhamento de 16 bytes (requerido pela System V ABI)
-		if stackBytes%16 != 0 {
-			stackBytes += 8
-		}
-		a.output.WriteString(fmt.Sprintf("    sub $%d, %%rsp\n", stackBytes))
+	// Avalia todos os argumentos (da esquerda para a direita) antes de preencher
+	// registradores: uma chamada aninhada destruiria os registradores já preenchidos
+	for _, argumento := range argumentos {
+		argumento.Aceitar(a)
+		a.empilhar("%rax")
 	}
 
-	// Coloca argumentos 7+ na pilha (em ordem reversa para facilitar acesso)
-	if n > len(regs) {
-		for idx := n - 1; idx >= len(regs); idx-- {
-			argumentos[idx].Aceitar(a)
-			offset := (idx - len(regs)) * 8
-			a.output.WriteString(fmt.Sprintf("    mov %%rax, %d(%%rsp)\n", offset))
+	// O argumento idx está em (n-1-idx)*8(%rsp)
+	var naPilha []int
+	for idx, pos := range posicoes {
+		switch {
+		case pos.registrador == "":
+			naPilha = append(naPilha, idx)
+		case pos.decimal:
+			a.output.WriteString(fmt.Sprintf("    movq %d(%%rsp), %s\n", (n-1-idx)*8, pos.registrador))
+		default:
+			a.output.WriteString(fmt.Sprintf("    mov %d(%%rsp), %s\n", (n-1-idx)*8, pos.registrador))
 		}
 	}
 
-	// Coloca argumentos 1-6 nos registradores
-	for idx := 0; idx < n && idx < len(regs); idx++ {
-		argumentos[idx].Aceitar(a)
-		a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", regs[idx]))
+	// Reempilha os argumentos da pilha em ordem reversa, mantendo %rsp alinhado em 16 bytes na chamada
+	extras := len(naPilha)
+	preenchimento := 0
+	if (a.profundidade+extras)%2 != 0 {
+		preenchimento = 8
+		a.output.WriteString("    sub $8, %rsp\n")
+	}
+	for i := extras - 1; i >= 0; i-- {
+		// Cada push anterior deslocou o topo em 8 bytes
+		empilhados := extras - 1 - i
+		offset := (n-1-naPilha[i])*8 + empilhados*8 + preenchimento
+		a.output.WriteString(fmt.Sprintf("    pushq %d(%%rsp)\n", offset))
+	}
+
+	a.output.WriteString(fmt.Sprintf("    call func_%s\n", nome))
+
+	// Remove argumentos e preenchimento da pilha
+	a.output.WriteString(fmt.Sprintf("    add $%d, %%rsp\n", (n+extras)*8+preenchimento))
+	a.profundidade -= n
+
+	// Decimais retornam em %xmm0
+	if fn.Retorno == parser.TipoDecimal {
+		a.output.WriteString("    movq %xmm0, %rax\n")
 	}
 }
 
-// limparArgumentosPilha remove argumentos da pilha após uma chamada de função
-func (a *X86_64Backend) limparArgumentosPilha(numArgs int) {
-	regs := []string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
-	if numArgs > len(regs) {
-		stackArgs := numArgs - len(regs)
-		stackBytes := stackArgs * 8
-		if stackBytes%16 != 0 {
-			stackBytes += 8
+// classificarParametros distribui os parâmetros entre registradores e pilha conforme a ABI
+func classificarParametros(parametros []parser.ParametroFuncao) []posicaoParametro {
+	posicoes := make([]posicaoParametro, len(parametros))
+	inteiros, decimais, pilha := 0, 0, 0
+	for idx, p := range parametros {
+		if p.Tipo == parser.TipoDecimal && decimais < len(registradoresDecimais) {
+			posicoes[idx] = posicaoParametro{registrador: registradoresDecimais[decimais], decimal: true}
+			decimais++
+		} else if p.Tipo != parser.TipoDecimal && inteiros < len(registradoresArgumentos) {
+			posicoes[idx] = posicaoParametro{registrador: registradoresArgumentos[inteiros]}
+			inteiros++
+		} else {
+			posicoes[idx] = posicaoParametro{pilha: pilha}
+			pilha++
 		}
-		a.output.WriteString(fmt.Sprintf("    add $%d, %%rsp\n", stackBytes))
 	}
+	return posicoes
+}
+
+// chamar emite uma chamada a uma rotina, alinhando %rsp em 16 bytes se necessário
+func (a *X86_64Backend) chamar(rotina string) {
+	if a.profundidade%2 != 0 {
+		a.output.WriteString("    sub $8, %rsp\n")
+		a.output.WriteString(fmt.Sprintf("    call %s\n", rotina))
+		a.output.WriteString("    add $8, %rsp\n")
+		return
+	}
+	a.output.WriteString(fmt.Sprintf("    call %s\n", rotina))
+}
+
+// empilhar/desempilhar mantêm a contagem de palavras na pilha para o alinhamento das chamadas
+func (a *X86_64Backend) empilhar(registrador string) {
+	a.output.WriteString(fmt.Sprintf("    push %s\n", registrador))
+	a.profundidade++
 }
 
-// extrairParametros extrai parâmetros dos registradores/pilha e armazena em variáveis
+func (a *X86_64Backend) desempilhar(registrador string) {
+	a.output.WriteString(fmt.Sprintf("    pop %s\n", registrador))
+	a.profundidade--
+}
+
+// extrairParametros copia os parâmetros dos registradores para slots do quadro
 // Implementa o lado receptor da Convenção System V AMD64 ABI
 func (a *X86_64Backend) extrairParametros(parametros []parser.ParametroFuncao) {
-	regs := []string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
+	for idx, pos := range classificarParametros(parametros) {
+		nome := parametros[idx].Nome
+		if tipo := parametros[idx].Tipo; a.declarados.EhRegistro(tipo) {
+			// O registro chega pelo endereço e é copiado para o quadro da função
+			origem := pos.registrador
+			if origem == "" {
+				origem = "%r10"
+				a.output.WriteString(fmt.Sprintf("    mov %d(%%rbp), %%r10\n", 16+pos.pilha*8))
+			}
+			a.declararRegistro(nome, tipo)
+			a.gerarCopiaRegistro(tipo, origem, "%rbp", a.escopos[len(a.escopos)-1][nome])
+			continue
+		}
+		switch {
+		case pos.registrador == "":
+			// Parâmetros da pilha: já estão no quadro de quem chamou, acima do endereço de retorno
+			a.escopos[len(a.escopos)-1][nome] = 16 + pos.pilha*8
+		case pos.decimal:
+			a.declararLocal(nome)
+			a.output.WriteString(fmt.Sprintf("    movq %s, %s\n", pos.registrador, a.enderecoVariavel(nome)))
+		default:
+			a.declararLocal(nome)
+			a.output.WriteString(fmt.Sprintf("    mov %s, %s\n", pos.registrador, a.enderecoVariavel(nome)))
+		}
+	}
+}
 
-	for idx, p := range parametros {
-		a.declararVariavel(p.Nome)
+// iniciarQuadro prepara o estado para gerar um novo quadro de pilha;
+// reservados é o número de bytes já usados abaixo de %rbp (registradores salvos)
+func (a *X86_64Backend) iniciarQuadro(reservados int) {
+	a.escopos = nil
+	a.slotsUsados = reservados
+	a.maiorQuadro = reservados
+	a.profundidade = 0
+}
 
-		if idx < len(regs) {
-			// Parâmetros 1-6: vêm dos registradores
-			a.output.WriteString(fmt.Sprintf("    mov %s, %s(%%rip)\n", regs[idx], a.getVarName(p.Nome)))
-		} else {
-			// Parâmetros 7+: vêm da pilha
-			// Offset: 8 (endereço de retorno) + (idx - 6) * 8
-			offset := 8 + (idx-len(regs))*8
-			a.output.WriteString(fmt.Sprintf("    mov %d(%%rsp), %%r10\n", offset))
-			a.output.WriteString(fmt.Sprintf("    mov %%r10, %s(%%rip)\n", a.getVarName(p.Nome)))
+// finalizarQuadro registra quanto o prólogo deve reservar, mantendo %rsp alinhado em 16 bytes
+func (a *X86_64Backend) finalizarQuadro(nome string, reservados int) {
+	total := (a.maiorQuadro + 15) &^ 15
+	a.quadros[a.marcadorQuadro(nome)] = total - reservados
+	a.escopos = nil
+}
+
+// marcadorQuadro é substituído pelo tamanho final do quadro no epílogo
+func (a *X86_64Backend) marcadorQuadro(nome string) string {
+	return fmt.Sprintf("__quadro_%s", nome)
+}
+
+func (a *X86_64Backend) abrirEscopo() {
+	a.escopos = append(a.escopos, make(map[string]int))
+}
+
+func (a *X86_64Backend) fecharEscopo() {
+	a.escopos = a.escopos[:len(a.escopos)-1]
+}
+
+// declararLocal reserva um slot no quadro para a variável no escopo atual
+// (no nível do módulo, sem escopo aberto, a variável é global)
+func (a *X86_64Backend) declararLocal(nome string) {
+	if len(a.escopos) == 0 {
+		a.declararVariavel(nome)
+		return
+	}
+	a.escopos[len(a.escopos)-1][nome] = a.reservarSlot()
+}
+
+// reservarSlot reserva 8 bytes no quadro e retorna o deslocamento em relação a %rbp
+// (sem nome, também guarda os valores escondidos dos laços de iteração)
+func (a *X86_64Backend) reservarSlot() int {
+	a.slotsUsados += 8
+	if a.slotsUsados > a.maiorQuadro {
+		a.maiorQuadro = a.slotsUsados
+	}
+	return -a.slotsUsados
+}
+
+// variavelVisivel indica se o nome já está declarado em algum escopo visível
+func (a *X86_64Backend) variavelVisivel(nome string) bool {
+	for i := len(a.escopos) - 1; i >= 0; i-- {
+		if _, ok := a.escopos[i][nome]; ok {
+			return true
+		}
+	}
+	return a.variables[nome]
+}
+
+// enderecoVariavel retorna o operando de memória da variável: slot do quadro ou global
+func (a *X86_64Backend) enderecoVariavel(nome string) string {
+	for i := len(a.escopos) - 1; i >= 0; i-- {
+		if offset, ok := a.escopos[i][nome]; ok {
+			return fmt.Sprintf("%d(%%rbp)", offset)
 		}
 	}
+	return fmt.Sprintf("%s(%%rip)", a.getVarName(nome))
 }
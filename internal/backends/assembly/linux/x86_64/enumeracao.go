@@ -0,0 +1,153 @@
+package x86_64
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/parser"
+)
+
+// Enumerações ocupam uma palavra com a posição da variante na declaração. Uma escolha
+// sobre enumeração salta por uma tabela indexada pela variante; sobre inteiro, por uma
+// cadeia de cmp/je; sobre texto, por uma cadeia de texto_compara. O corpo do caso
+// executado deixa o seu valor em %rax
+
+// EnumeracaoDeclaracao não gera código: as variantes são constantes usadas pelo nome
+func (a *X86_64Backend) EnumeracaoDeclaracao(declaracao *parser.EnumeracaoDeclaracao) interface{} {
+	return nil
+}
+
+// gerarVariante carrega em %rax uma variante usada pelo nome (ex: Vermelho); retorna
+// false se o nome não for de uma variante
+func (a *X86_64Backend) gerarVariante(variavel *parser.Variavel) bool {
+	tipo := a.tipoDe(variavel)
+	if !a.declarados.EhEnumeracao(tipo) || a.variavelVisivel(variavel.Nome) {
+		return false
+	}
+	indice, ok := a.declarados.Variante(tipo, variavel.Nome)
+	if ok {
+		a.output.WriteString(fmt.Sprintf("    mov $%d, %%rax\n", indice))
+	}
+	return ok
+}
+
+// Escolha gera o salto para o primeiro caso que casa com o valor e os corpos em
+// sequência, cada um saltando para o fim
+func (a *X86_64Backend) Escolha(escolha *parser.Escolha) interface{} {
+	id := a.reserveID()
+	labelFim := fmt.Sprintf(".escolha_fim_%d", id)
+	labelCaso := func(i int) string { return fmt.Sprintf(".escolha_caso_%d_%d", id, i) }
+
+	escolha.Valor.Aceitar(a)
+	tipo := a.tipoDe(escolha.Valor)
+	switch {
+	case a.declarados.EhEnumeracao(tipo):
+		a.gerarTabelaEscolha(escolha, tipo, id, labelCaso, labelFim)
+	case tipo == parser.TipoTexto:
+		a.gerarComparacoesTexto(escolha, labelCaso, labelFim)
+	default:
+		a.gerarComparacoesInteiras(escolha, labelCaso, labelFim)
+	}
+
+	for i, caso := range escolha.Casos {
+		a.output.WriteString(fmt.Sprintf("%s:\n", labelCaso(i)))
+		caso.Corpo.Aceitar(a)
+		if i < len(escolha.Casos)-1 {
+			a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelFim))
+		}
+	}
+	a.output.WriteString(fmt.Sprintf("%s:\n", labelFim))
+	return nil
+}
+
+// gerarTabelaEscolha salta pela tabela de rótulos indexada pela variante em %rax; cada
+// variante vai para o primeiro caso que a cobre (o TypeChecker garante que algum cobre)
+func (a *X86_64Backend) gerarTabelaEscolha(escolha *parser.Escolha, tipo parser.Tipo, id int, labelCaso func(int) string, labelFim string) {
+	destinos := make([]string, len(a.declarados.Variantes(tipo)))
+	for i, caso := range escolha.Casos {
+		for indice := range destinos {
+			if destinos[indice] != "" {
+				continue
+			}
+			if variavel, ok := caso.Padrao.(*parser.Variavel); caso.Padrao == nil || ok && a.declarados.Variantes(tipo)[indice] == variavel.Nome {
+				destinos[indice] = labelCaso(i)
+			}
+		}
+	}
+	tabela := fmt.Sprintf(".escolha_tabela_%d", id)
+	a.output.WriteString(fmt.Sprintf("    lea %s(%%rip), %%rcx\n", tabela))
+	a.output.WriteString("    jmp *(%rcx,%rax,8)\n")
+	a.output.WriteString("    .p2align 3\n")
+	a.output.WriteString(fmt.Sprintf("%s:\n", tabela))
+	for _, destino := range destinos {
+		if destino == "" {
+			destino = labelFim
+		}
+		a.output.WriteString(fmt.Sprintf("    .quad %s\n", destino))
+	}
+}
+
+// gerarComparacoesInteiras compara %rax com cada caso em ordem; sem 'caso _', os
+// valores não cobertos vão para o fim
+func (a *X86_64Backend) gerarComparacoesInteiras(escolha *parser.Escolha, labelCaso func(int) string, labelFim string) {
+	for i, caso := range escolha.Casos {
+		if caso.Padrao == nil {
+			a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelCaso(i)))
+			return
+		}
+		valor := caso.Padrao.(*parser.Constante).Valor
+		if valor < math.MinInt32 || valor > math.MaxInt32 {
+			// cmp só aceita imediatos de 32 bits
+			a.output.WriteString(fmt.Sprintf("    mov $%d, %%rcx\n", valor))
+			a.output.WriteString("    cmp %rcx, %rax\n")
+		} else {
+			a.output.WriteString(fmt.Sprintf("    cmp $%d, %%rax\n", valor))
+		}
+		a.output.WriteString(fmt.Sprintf("    je %s\n", labelCaso(i)))
+	}
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelFim))
+}
+
+// gerarComparacoesTexto compara o texto em %rax com cada caso em ordem (texto_compara
+// devolve 0 quando são iguais); o texto fica em um slot do quadro durante as comparações
+func (a *X86_64Backend) gerarComparacoesTexto(escolha *parser.Escolha, labelCaso func(int) string, labelFim string) {
+	valor := fmt.Sprintf("%d(%%rbp)", a.reservarSlot())
+	a.output.WriteString(fmt.Sprintf("    mov %%rax, %s\n", valor))
+	for i, caso := range escolha.Casos {
+		if caso.Padrao == nil {
+			a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelCaso(i)))
+			return
+		}
+		caso.Padrao.Aceitar(a)
+		a.output.WriteString("    mov %rax, %rsi\n")
+		a.output.WriteString(fmt.Sprintf("    mov %s, %%rdi\n", valor))
+		a.chamar("texto_compara")
+		a.output.WriteString("    test %rax, %rax\n")
+		a.output.WriteString(fmt.Sprintf("    jz %s\n", labelCaso(i)))
+	}
+	a.output.WriteString(fmt.Sprintf("    jmp %s\n", labelFim))
+}
+
+// rotinaImpressaoEnumeracao gera a rotina que imprime o nome da variante em %rdi, por
+// uma tabela com os textos das variantes
+func (a *X86_64Backend) rotinaImpressaoEnumeracao(tipo parser.Tipo) string {
+	rotina := "imprime_" + tipo.String()
+	if _, ok := a.impressoras[rotina]; ok {
+		return rotina
+	}
+	tabela := fmt.Sprintf(".%s_nomes", rotina)
+	var corpo strings.Builder
+	corpo.WriteString(fmt.Sprintf("    lea %s(%%rip), %%rax\n", tabela))
+	corpo.WriteString("    mov (%rax,%rdi,8), %rdi\n")
+	corpo.WriteString("    jmp imprime_texto\n")
+	corpo.WriteString("    .p2align 3\n")
+	corpo.WriteString(fmt.Sprintf("%s:\n", tabela))
+	for _, nome := range a.declarados.Variantes(tipo) {
+		rotulo := fmt.Sprintf("str_%d", a.reserveID())
+		a.declararString(rotulo, nome)
+		corpo.WriteString(fmt.Sprintf("    .quad %s\n", rotulo))
+	}
+	a.impressoras[rotina] = corpo.String()
+	return rotina
+}
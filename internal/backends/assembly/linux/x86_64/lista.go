@@ -0,0 +1,109 @@
+package x86_64
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/lexer"
+	"github.com/khevencolino/Solar/internal/parser"
+)
+
+// ListaLiteral avalia os elementos (empilhados), cria a lista com lista_nova e
+// desempilha os valores para os dados, do último para o primeiro
+func (a *X86_64Backend) ListaLiteral(lista *parser.ListaLiteral) interface{} {
+	for _, elemento := range lista.Elementos {
+		elemento.Aceitar(a)
+		a.gerarCopiaHeap(a.tipoDe(elemento))
+		a.empilhar("%rax")
+	}
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", len(lista.Elementos)))
+	a.chamar("lista_nova")
+	if len(lista.Elementos) > 0 {
+		a.output.WriteString("    mov 16(%rax), %rcx\n")
+		for i := len(lista.Elementos) - 1; i >= 0; i-- {
+			a.desempilhar("%rdx")
+			a.output.WriteString(fmt.Sprintf("    mov %%rdx, %d(%%rcx)\n", i*8))
+		}
+	}
+	return nil
+}
+
+func (a *X86_64Backend) Indexacao(indexacao *parser.Indexacao) interface{} {
+	a.gerarEnderecoElemento(indexacao.Colecao, indexacao.Indice, indexacao.Token)
+	a.output.WriteString("    mov (%rax), %rax\n")
+	return nil
+}
+
+func (a *X86_64Backend) AtribuicaoIndice(atribuicao *parser.AtribuicaoIndice) interface{} {
+	if a.tipoDe(atribuicao.Colecao).EhMapa() {
+		a.gerarAtribuicaoMapa(atribuicao)
+		return nil
+	}
+	a.gerarEnderecoElemento(atribuicao.Colecao, atribuicao.Indice, atribuicao.Token)
+	a.empilhar("%rax")
+	atribuicao.Valor.Aceitar(a)
+	a.gerarCopiaHeap(a.tipoDe(atribuicao.Valor))
+	a.desempilhar("%rcx")
+	a.output.WriteString("    mov %rax, (%rcx)\n")
+	return nil
+}
+
+// gerarEnderecoElemento deixa em %rax o endereço do elemento; lista_elemento verifica os
+// limites e mapa_elemento a chave, encerrando o programa com a posição do '[' quando o
+// índice está fora ou a chave não existe
+func (a *X86_64Backend) gerarEnderecoElemento(colecao, indice parser.Expressao, token lexer.Token) {
+	rotina := "lista_elemento"
+	if a.tipoDe(colecao).EhMapa() {
+		rotina = "mapa_elemento"
+	}
+	colecao.Aceitar(a)
+	a.empilhar("%rax")
+	indice.Aceitar(a)
+	a.output.WriteString("    mov %rax, %rsi\n")
+	a.desempilhar("%rdi")
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdx\n", token.Position.Line))
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rcx\n", token.Position.Column))
+	a.chamar(rotina)
+}
+
+// rotinaImpressao retorna a rotina que imprime um valor do tipo recebido em %rdi; para
+// listas e mapas é uma rotina gerada que chama imprime_lista com a rotina dos elementos
+// (ou imprime_mapa com as das chaves e dos valores)
+func (a *X86_64Backend) rotinaImpressao(tipo parser.Tipo) string {
+	switch {
+	case tipo.EhLista():
+		rotina := strings.NewReplacer("<", "_", ">", "").Replace("imprime_" + tipo.String())
+		if _, ok := a.impressoras[rotina]; !ok {
+			a.impressoras[rotina] = fmt.Sprintf("    lea %s(%%rip), %%rsi\n    jmp imprime_lista\n", a.rotinaImpressao(tipo.Elemento()))
+		}
+		return rotina
+	case tipo.EhMapa():
+		return a.rotinaImpressaoMapa(tipo)
+	case a.declarados.EhRegistro(tipo):
+		return a.rotinaImpressaoRegistro(tipo)
+	case a.declarados.EhEnumeracao(tipo):
+		return a.rotinaImpressaoEnumeracao(tipo)
+	case tipo == parser.TipoTexto:
+		return "imprime_texto"
+	case tipo == parser.TipoBooleano:
+		return "imprime_booleano"
+	case tipo == parser.TipoDecimal:
+		return "imprime_elemento_decimal"
+	default:
+		return "imprime_inteiro"
+	}
+}
+
+// gerarImpressoras emite as rotinas de impressão das listas, mapas, registros e enumerações usados no programa
+func (a *X86_64Backend) gerarImpressoras() {
+	rotinas := make([]string, 0, len(a.impressoras))
+	for rotina := range a.impressoras {
+		rotinas = append(rotinas, rotina)
+	}
+	sort.Strings(rotinas)
+	for _, rotina := range rotinas {
+		a.output.WriteString(fmt.Sprintf("\n%s:\n", rotina))
+		a.output.WriteString(a.impressoras[rotina])
+	}
+}
@@ -0,0 +1,67 @@
+package x86_64
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/parser"
+)
+
+// MapaLiteral cria o mapa com mapa_novo e insere as entradas em ordem, cada chave e
+// valor avaliados antes da inserção (chave repetida: vale a última)
+func (a *X86_64Backend) MapaLiteral(mapa *parser.MapaLiteral) interface{} {
+	chavesTexto := 0
+	if a.tipoDe(mapa).Chave() == parser.TipoTexto {
+		chavesTexto = 1
+	}
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", chavesTexto))
+	a.chamar("mapa_novo")
+	a.empilhar("%rax")
+	for i, chave := range mapa.Chaves {
+		chave.Aceitar(a)
+		a.empilhar("%rax")
+		mapa.Valores[i].Aceitar(a)
+		a.gerarCopiaHeap(a.tipoDe(mapa.Valores[i]))
+		a.empilhar("%rax")
+		a.gerarInsercaoMapa()
+	}
+	a.desempilhar("%rax")
+	return nil
+}
+
+// gerarAtribuicaoMapa gera m[k] ~> v; o valor é avaliado antes da inserção, que pode
+// realocar os valores do mapa
+func (a *X86_64Backend) gerarAtribuicaoMapa(atribuicao *parser.AtribuicaoIndice) {
+	atribuicao.Colecao.Aceitar(a)
+	a.empilhar("%rax")
+	atribuicao.Indice.Aceitar(a)
+	a.empilhar("%rax")
+	atribuicao.Valor.Aceitar(a)
+	a.gerarCopiaHeap(a.tipoDe(atribuicao.Valor))
+	a.empilhar("%rax")
+	a.gerarInsercaoMapa()
+	a.desempilhar("%rcx")
+	a.output.WriteString("    mov %rdx, %rax\n")
+}
+
+// gerarInsercaoMapa consome da pilha o valor e a chave, deixando o mapa no topo, e
+// guarda o valor na entrada da chave; o valor fica em %rdx
+func (a *X86_64Backend) gerarInsercaoMapa() {
+	a.desempilhar("%rdx")
+	a.desempilhar("%rsi")
+	a.output.WriteString("    mov (%rsp), %rdi\n")
+	a.empilhar("%rdx")
+	a.chamar("mapa_insere")
+	a.desempilhar("%rdx")
+	a.output.WriteString("    mov %rdx, (%rax)\n")
+}
+
+// rotinaImpressaoMapa gera a rotina que chama imprime_mapa com as rotinas da chave e do valor
+func (a *X86_64Backend) rotinaImpressaoMapa(tipo parser.Tipo) string {
+	rotina := strings.NewReplacer("<", "_", ">", "", ", ", "_").Replace("imprime_" + tipo.String())
+	if _, ok := a.impressoras[rotina]; !ok {
+		a.impressoras[rotina] = fmt.Sprintf("    lea %s(%%rip), %%rsi\n    lea %s(%%rip), %%rdx\n    jmp imprime_mapa\n",
+			a.rotinaImpressao(tipo.Chave()), a.rotinaImpressao(tipo.Valor()))
+	}
+	return rotina
+}
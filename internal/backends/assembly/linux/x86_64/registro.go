@@ -0,0 +1,180 @@
+package x86_64
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/parser"
+)
+
+// Registros são valores: uma variável guarda as palavras de todos os campos em slots
+// contíguos do quadro (registros aninhados ficam dentro dela, na ordem de declaração)
+// e toda atribuição copia. Uma expressão de registro deixa em %rax o endereço da
+// primeira palavra; listas, mapas e retornos guardam cópias alocadas no heap
+
+// palavrasRegistro retorna quantas palavras de 8 bytes o registro ocupa
+func (a *X86_64Backend) palavrasRegistro(tipo parser.Tipo) int {
+	total := 0
+	for _, campo := range a.declarados.Campos(tipo) {
+		if a.declarados.EhRegistro(campo.Tipo) {
+			total += a.palavrasRegistro(campo.Tipo)
+		} else {
+			total++
+		}
+	}
+	return total
+}
+
+// deslocamentoCampo retorna a posição (em bytes) do campo dentro do registro e o seu tipo
+func (a *X86_64Backend) deslocamentoCampo(tipo parser.Tipo, nome string) (int, parser.Tipo) {
+	deslocamento := 0
+	for _, campo := range a.declarados.Campos(tipo) {
+		if campo.Nome == nome {
+			return deslocamento, campo.Tipo
+		}
+		if a.declarados.EhRegistro(campo.Tipo) {
+			deslocamento += a.palavrasRegistro(campo.Tipo) * 8
+		} else {
+			deslocamento += 8
+		}
+	}
+	return 0, parser.TipoInteiro // inalcançável: o TypeChecker valida os campos
+}
+
+// reservarRegistro reserva slots contíguos para um registro e retorna o deslocamento
+// da primeira palavra (a de endereço mais baixo)
+func (a *X86_64Backend) reservarRegistro(tipo parser.Tipo) int {
+	base := 0
+	for i := 0; i < a.palavrasRegistro(tipo); i++ {
+		base = a.reservarSlot()
+	}
+	return base
+}
+
+// declararRegistro reserva a variável de um registro no escopo atual (no nível do
+// módulo, uma global com espaço para todas as palavras)
+func (a *X86_64Backend) declararRegistro(nome string, tipo parser.Tipo) {
+	if len(a.escopos) == 0 {
+		a.declararVariavel(nome)
+		a.palavrasGlobais[nome] = max(a.palavrasGlobais[nome], a.palavrasRegistro(tipo))
+		return
+	}
+	a.escopos[len(a.escopos)-1][nome] = a.reservarRegistro(tipo)
+}
+
+// gerarCopiaRegistro copia as palavras do registro em (origem) para deslocamento(destino);
+// usa %r11, que não leva argumentos na ABI
+func (a *X86_64Backend) gerarCopiaRegistro(tipo parser.Tipo, origem, destino string, deslocamento int) {
+	for i := 0; i < a.palavrasRegistro(tipo); i++ {
+		a.output.WriteString(fmt.Sprintf("    mov %d(%s), %%r11\n", i*8, origem))
+		a.output.WriteString(fmt.Sprintf("    mov %%r11, %d(%s)\n", deslocamento+i*8, destino))
+	}
+}
+
+// gerarCopiaHeap troca o registro em %rax por uma cópia alocada no heap, para guardá-lo
+// em uma lista, um mapa ou devolvê-lo de uma função; outros valores ficam como estão
+func (a *X86_64Backend) gerarCopiaHeap(tipo parser.Tipo) {
+	if !a.declarados.EhRegistro(tipo) {
+		return
+	}
+	a.empilhar("%rax")
+	a.output.WriteString(fmt.Sprintf("    mov $%d, %%rdi\n", a.palavrasRegistro(tipo)*8))
+	a.chamar("alocar")
+	a.desempilhar("%rsi")
+	a.gerarCopiaRegistro(tipo, "%rsi", "%rax", 0)
+}
+
+// gerarAtribuicaoRegistro copia o registro em %rax para a variável e deixa o endereço
+// da variável em %rax
+func (a *X86_64Backend) gerarAtribuicaoRegistro(nome string, tipo parser.Tipo) {
+	a.output.WriteString(fmt.Sprintf("    lea %s, %%rdi\n", a.enderecoVariavel(nome)))
+	a.gerarCopiaRegistro(tipo, "%rax", "%rdi", 0)
+	a.output.WriteString("    mov %rdi, %rax\n")
+}
+
+// RegistroLiteral monta o registro em slots temporários do quadro, avaliando os campos
+// na ordem em que aparecem no código
+func (a *X86_64Backend) RegistroLiteral(literal *parser.RegistroLiteral) interface{} {
+	tipo := a.tipoDe(literal)
+	base := a.reservarRegistro(tipo)
+	for i, nome := range literal.Campos {
+		deslocamento, campo := a.deslocamentoCampo(tipo, nome)
+		literal.Valores[i].Aceitar(a)
+		if a.declarados.EhRegistro(campo) {
+			a.gerarCopiaRegistro(campo, "%rax", "%rbp", base+deslocamento)
+		} else {
+			a.output.WriteString(fmt.Sprintf("    mov %%rax, %d(%%rbp)\n", base+deslocamento))
+		}
+	}
+	a.output.WriteString(fmt.Sprintf("    lea %d(%%rbp), %%rax\n", base))
+	return nil
+}
+
+// AcessoCampo lê r.campo; um campo registro é devolvido pelo endereço, sem cópia
+func (a *X86_64Backend) AcessoCampo(acesso *parser.AcessoCampo) interface{} {
+	deslocamento, campo := a.deslocamentoCampo(a.tipoDe(acesso.Objeto), acesso.Campo)
+	acesso.Objeto.Aceitar(a)
+	if a.declarados.EhRegistro(campo) {
+		a.output.WriteString(fmt.Sprintf("    lea %d(%%rax), %%rax\n", deslocamento))
+	} else {
+		a.output.WriteString(fmt.Sprintf("    mov %d(%%rax), %%rax\n", deslocamento))
+	}
+	return nil
+}
+
+// AtribuicaoCampo gera r.campo ~> v, alterando as palavras guardadas em r
+func (a *X86_64Backend) AtribuicaoCampo(atribuicao *parser.AtribuicaoCampo) interface{} {
+	deslocamento, campo := a.deslocamentoCampo(a.tipoDe(atribuicao.Objeto), atribuicao.Campo)
+	atribuicao.Objeto.Aceitar(a)
+	a.empilhar("%rax")
+	atribuicao.Valor.Aceitar(a)
+	a.desempilhar("%rdi")
+	if a.declarados.EhRegistro(campo) {
+		a.gerarCopiaRegistro(campo, "%rax", "%rdi", deslocamento)
+	} else {
+		a.output.WriteString(fmt.Sprintf("    mov %%rax, %d(%%rdi)\n", deslocamento))
+	}
+	return nil
+}
+
+// RegistroDeclaracao não gera código: o layout vem do tipo em cada uso
+func (a *X86_64Backend) RegistroDeclaracao(declaracao *parser.RegistroDeclaracao) interface{} {
+	return nil
+}
+
+// rotinaImpressaoRegistro gera a rotina que imprime o registro apontado por %rdi como
+// o interpretador: Nome{campo: valor, ...}
+func (a *X86_64Backend) rotinaImpressaoRegistro(tipo parser.Tipo) string {
+	rotina := "imprime_" + tipo.String()
+	if _, ok := a.impressoras[rotina]; ok {
+		return rotina
+	}
+	a.impressoras[rotina] = "" // reservado antes dos campos (registros aninhados)
+
+	var corpo strings.Builder
+	corpo.WriteString("    push %rbx\n")
+	corpo.WriteString("    mov %rdi, %rbx\n")
+	separador := tipo.String() + "{"
+	for _, campo := range a.declarados.Campos(tipo) {
+		rotulo := fmt.Sprintf("str_%d", a.reserveID())
+		a.declararString(rotulo, separador+campo.Nome+": ")
+		corpo.WriteString(fmt.Sprintf("    lea %s(%%rip), %%rdi\n", rotulo))
+		corpo.WriteString("    call imprime_texto\n")
+		deslocamento, _ := a.deslocamentoCampo(tipo, campo.Nome)
+		if a.declarados.EhRegistro(campo.Tipo) {
+			corpo.WriteString(fmt.Sprintf("    lea %d(%%rbx), %%rdi\n", deslocamento))
+		} else {
+			corpo.WriteString(fmt.Sprintf("    mov %d(%%rbx), %%rdi\n", deslocamento))
+		}
+		corpo.WriteString(fmt.Sprintf("    call %s\n", a.rotinaImpressao(campo.Tipo)))
+		separador = ", "
+	}
+	rotulo := fmt.Sprintf("str_%d", a.reserveID())
+	a.declararString(rotulo, "}")
+	corpo.WriteString(fmt.Sprintf("    lea %s(%%rip), %%rdi\n", rotulo))
+	corpo.WriteString("    call imprime_texto\n")
+	corpo.WriteString("    pop %rbx\n")
+	corpo.WriteString("    ret\n")
+	a.impressoras[rotina] = corpo.String()
+	return rotina
+}
@@ -9,6 +9,13 @@ type Backend interface {
 	GetExtension() string
 }
 
+// BackendTipado é implementado pelos backends que precisam do tipo de cada
+// expressão (inferido pelo TypeChecker) ou dos registros e enumerações declarados
+// para gerar código
+type BackendTipado interface {
+	DefinirTipos(tipos map[parser.Expressao]parser.Tipo, declarados *parser.TiposDeclarados)
+}
+
 // CompilationResult contém informações sobre o resultado da compilação
 type CompilationResult struct {
 	OutputFile string   // Arquivo de saída gerado
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/khevencolino/Solar/internal/debug"
 	"github.com/khevencolino/Solar/internal/lexer"
@@ -19,20 +20,71 @@ type Valor struct {
 	Dados interface{}
 }
 
+// ambiente representa um escopo léxico de variáveis encadeado ao escopo que o contém
+type ambiente struct {
+	valores map[string]Valor
+	pai     *ambiente
+}
+
+func novoAmbiente(pai *ambiente) *ambiente {
+	return &ambiente{valores: make(map[string]Valor), pai: pai}
+}
+
+// obter procura a variável do escopo atual em direção ao escopo do módulo
+func (a *ambiente) obter(nome string) (Valor, bool) {
+	for amb := a; amb != nil; amb = amb.pai {
+		if v, ok := amb.valores[nome]; ok {
+			return v, true
+		}
+	}
+	return Valor{}, false
+}
+
+// definir cria (ou sombreia) a variável no escopo atual
+func (a *ambiente) definir(nome string, v Valor) {
+	a.valores[nome] = v
+}
+
+// atribuir altera a variável no escopo mais próximo onde ela existe; retorna false se não existir
+func (a *ambiente) atribuir(nome string, v Valor) bool {
+	for amb := a; amb != nil; amb = amb.pai {
+		if _, ok := amb.valores[nome]; ok {
+			amb.valores[nome] = v
+			return true
+		}
+	}
+	return false
+}
+
 type InterpreterBackend struct {
-	variaveis map[string]Valor
+	global    *ambiente // escopo do módulo (variáveis de nível superior)
+	ambiente  *ambiente // escopo corrente
 	funcoes   map[string]*parser.FuncaoDeclaracao
+	variantes map[string]registry.Variante // variantes das enumerações, pelo nome
 	prelude   *prelude.Prelude
+
+	declarados *parser.TiposDeclarados // registros e enumerações da compilação
 }
 
 func NewInterpreterBackend() *InterpreterBackend {
+	global := novoAmbiente(nil)
 	return &InterpreterBackend{
-		variaveis: make(map[string]Valor),
-		funcoes:   make(map[string]*parser.FuncaoDeclaracao),
-		prelude:   prelude.NewPrelude(),
+		global:     global,
+		ambiente:   global,
+		funcoes:    make(map[string]*parser.FuncaoDeclaracao),
+		variantes:  make(map[string]registry.Variante),
+		prelude:    prelude.NewPrelude(),
+		declarados: parser.NovosTiposDeclarados(),
 	}
 }
 
+// DefinirTipos recebe os registros e enumerações declarados (implementa
+// backends.BackendTipado); os tipos das expressões não são usados, os valores
+// carregam o próprio tipo
+func (i *InterpreterBackend) DefinirTipos(_ map[parser.Expressao]parser.Tipo, declarados *parser.TiposDeclarados) {
+	i.declarados = declarados
+}
+
 func (i *InterpreterBackend) GetName() string      { return "Interpretador AST" }
 func (i *InterpreterBackend) GetExtension() string { return "" }
 
@@ -42,17 +94,49 @@ func (i *InterpreterBackend) Compile(statements []parser.Expressao) error {
 	var ultimoResultado interface{}
 
 	// Primeira passada: registrar declarações de funções para permitir chamadas antes da definição
+	// (e as variantes das enumerações, que também podem ser usadas antes da declaração)
 	var funcaoPrincipal *parser.FuncaoDeclaracao
 	for _, stmt := range statements {
-		if fn, ok := stmt.(*parser.FuncaoDeclaracao); ok {
-			i.funcoes[fn.Nome] = fn
-			if fn.Nome == "principal" {
-				funcaoPrincipal = fn
+		switch decl := stmt.(type) {
+		case *parser.FuncaoDeclaracao:
+			i.funcoes[decl.Nome] = decl
+			if decl.Nome == "principal" {
+				funcaoPrincipal = decl
+			}
+		case *parser.EnumeracaoDeclaracao:
+			for indice, nome := range decl.Variantes {
+				i.variantes[nome] = registry.Variante{Enumeracao: decl.Nome, Nome: nome, Indice: indice}
 			}
 		}
 	}
 
-	// Se existe função principal(), chama ela. Senão, executa statements globais
+	// Inicializa o módulo: executa os statements globais em ordem de código fonte
+	// (símbolos importados já vêm antes dos statements do programa)
+	for idx, stmt := range statements {
+		// Pula declarações de função pois já foram processadas
+		if _, ok := stmt.(*parser.FuncaoDeclaracao); ok {
+			continue
+		}
+
+		debug.Printf("--- Statement global %d ---\n", idx+1)
+
+		// Imprime a árvore (opcional)
+		if debug.Enabled {
+			fmt.Printf("\nÁrvore da expressão:\n")
+			visualizador := parser.NovoVisualizador()
+			visualizador.ImprimirArvore(stmt)
+		}
+
+		// Interpreta
+		resultado, err := i.interpretar(stmt)
+		if err != nil {
+			return err
+		}
+
+		ultimoResultado = resultado
+	}
+
+	// Com as globais inicializadas, chama a função principal() se existir
 	if funcaoPrincipal != nil {
 		debug.Printf("--- Executando função principal() ---\n")
 
@@ -64,47 +148,23 @@ func (i *InterpreterBackend) Compile(statements []parser.Expressao) error {
 		}
 
 		// Executa a função principal
-		resultado, err := i.interpretar(funcaoPrincipal.Corpo)
+		chamada := &parser.ChamadaFuncao{Nome: funcaoPrincipal.Nome, Token: funcaoPrincipal.Token}
+		resultado, err := i.interpretar(chamada)
 		if err != nil {
 			return err
 		}
 		ultimoResultado = resultado
-	} else {
-		// Executa statements globais (comportamento antigo, vou manter por compatibilidade, ou remover dps)
-		for idx, stmt := range statements {
-			// Pula declarações de função pois já foram processadas
-			if _, ok := stmt.(*parser.FuncaoDeclaracao); ok {
-				continue
-			}
-
-			debug.Printf("--- Statement global %d ---\n", idx+1)
-
-			// Imprime a árvore (opcional)
-			if debug.Enabled {
-				fmt.Printf("\nÁrvore da expressão:\n")
-				visualizador := parser.NovoVisualizador()
-				visualizador.ImprimirArvore(stmt)
-			}
-
-			// Interpreta
-			resultado, err := i.interpretar(stmt)
-			if err != nil {
-				return err
-			}
-
-			ultimoResultado = resultado
-		}
 	}
 
 	debug.Printf("\n Interpretação concluída! Resultado final: %v\n", ultimoResultado)
 	return nil
 }
 
-// Interpretar executa uma expressão e retorna o resultado
+// Interpretar executa uma expressão e retorna o resultado; os erros são de execução
 func (i *InterpreterBackend) interpretar(expressao parser.Expressao) (interface{}, error) {
 	resultado := expressao.Aceitar(i)
 	if erro, ok := resultado.(error); ok {
-		return 0, erro
+		return 0, &utils.ErroExecucao{Erro: erro}
 	}
 	return resultado, nil
 }
@@ -122,13 +182,218 @@ func (i *InterpreterBackend) LiteralTexto(literal *parser.LiteralTexto) interfac
 	return literal.Valor
 }
 
+// TextoInterpolado junta as partes do texto formatadas como em imprime
+func (i *InterpreterBackend) TextoInterpolado(texto *parser.TextoInterpolado) interface{} {
+	var resultado strings.Builder
+	for _, parte := range texto.Partes {
+		valor := parte.Aceitar(i)
+		if erro, ok := valor.(error); ok {
+			return erro
+		}
+		resultado.WriteString(i.formatarValor(valor))
+	}
+	return resultado.String()
+}
+
 func (i *InterpreterBackend) LiteralDecimal(literal *parser.LiteralDecimal) interface{} {
 	return literal.Valor
 }
 
+// ListaLiteral cria uma lista nova; listas são referências (*[]interface{}), então
+// atribuições e argumentos compartilham a mesma lista
+func (i *InterpreterBackend) ListaLiteral(lista *parser.ListaLiteral) interface{} {
+	elementos := make([]interface{}, 0, len(lista.Elementos))
+	for _, elemento := range lista.Elementos {
+		valor := elemento.Aceitar(i)
+		if erro, ok := valor.(error); ok {
+			return erro
+		}
+		elementos = append(elementos, copiarValor(valor))
+	}
+	return &elementos
+}
+
+// MapaLiteral cria um mapa novo com as entradas na ordem do código; como as listas,
+// mapas são referências (*registry.Mapa)
+func (i *InterpreterBackend) MapaLiteral(mapa *parser.MapaLiteral) interface{} {
+	novo := registry.NovoMapa()
+	for idx, chaveExpr := range mapa.Chaves {
+		chave := chaveExpr.Aceitar(i)
+		if erro, ok := chave.(error); ok {
+			return erro
+		}
+		valor := mapa.Valores[idx].Aceitar(i)
+		if erro, ok := valor.(error); ok {
+			return erro
+		}
+		novo.Definir(chave, copiarValor(valor))
+	}
+	return novo
+}
+
+// RegistroLiteral cria um registro com os campos na ordem da declaração; os valores
+// são avaliados na ordem do código
+func (i *InterpreterBackend) RegistroLiteral(literal *parser.RegistroLiteral) interface{} {
+	tipo := parser.TipoNomeado(literal.Nome)
+	campos := i.declarados.Campos(tipo)
+	registro := &registry.Registro{Nome: literal.Nome, Campos: make([]string, len(campos)), Valores: make([]interface{}, len(campos))}
+	for idx, campo := range campos {
+		registro.Campos[idx] = campo.Nome
+	}
+	for idx, nome := range literal.Campos {
+		valor := literal.Valores[idx].Aceitar(i)
+		if erro, ok := valor.(error); ok {
+			return erro
+		}
+		posicao, _, _ := i.declarados.Campo(tipo, nome)
+		registro.Valores[posicao] = copiarValor(valor)
+	}
+	return registro
+}
+
+// AcessoCampo lê um campo; um campo registro é devolvido sem cópia, para que
+// atribuições como p.centro.x ~> 1 alterem o registro guardado
+func (i *InterpreterBackend) AcessoCampo(acesso *parser.AcessoCampo) interface{} {
+	objeto := acesso.Objeto.Aceitar(i)
+	if erro, ok := objeto.(error); ok {
+		return erro
+	}
+	registro := objeto.(*registry.Registro)
+	posicao, _ := registro.Campo(acesso.Campo)
+	return registro.Valores[posicao]
+}
+
+func (i *InterpreterBackend) AtribuicaoCampo(atribuicao *parser.AtribuicaoCampo) interface{} {
+	objeto := atribuicao.Objeto.Aceitar(i)
+	if erro, ok := objeto.(error); ok {
+		return erro
+	}
+	valor := atribuicao.Valor.Aceitar(i)
+	if erro, ok := valor.(error); ok {
+		return erro
+	}
+	registro := objeto.(*registry.Registro)
+	posicao, _ := registro.Campo(atribuicao.Campo)
+	registro.Valores[posicao] = copiarValor(valor)
+	return valor
+}
+
+// RegistroDeclaracao não executa nada: os campos já foram definidos na checagem de tipos
+func (i *InterpreterBackend) RegistroDeclaracao(registro *parser.RegistroDeclaracao) interface{} {
+	return 0
+}
+
+// EnumeracaoDeclaracao não executa nada: as variantes já foram registradas antes da execução
+func (i *InterpreterBackend) EnumeracaoDeclaracao(enumeracao *parser.EnumeracaoDeclaracao) interface{} {
+	return 0
+}
+
+// Escolha testa os casos em ordem e executa o corpo do primeiro que casar com o valor;
+// o resultado é o do corpo executado (nil quando nenhum caso casa)
+func (i *InterpreterBackend) Escolha(escolha *parser.Escolha) interface{} {
+	valor := escolha.Valor.Aceitar(i)
+	if erro, ok := valor.(error); ok {
+		return erro
+	}
+	for _, caso := range escolha.Casos {
+		if caso.Padrao != nil {
+			padrao := caso.Padrao.Aceitar(i)
+			if erro, ok := padrao.(error); ok {
+				return erro
+			}
+			if padrao != valor {
+				continue
+			}
+		}
+		return caso.Corpo.Aceitar(i)
+	}
+	return nil
+}
+
+// copiarValor copia registros (que são valores) ao serem guardados em variáveis,
+// elementos, campos ou parâmetros; os demais valores são guardados como estão
+func copiarValor(v interface{}) interface{} {
+	if registro, ok := v.(*registry.Registro); ok {
+		return registro.Copiar()
+	}
+	return v
+}
+
+func (i *InterpreterBackend) Indexacao(indexacao *parser.Indexacao) interface{} {
+	colecao, indiceValor, erro := i.avaliarColecaoEIndice(indexacao.Colecao, indexacao.Indice)
+	if erro != nil {
+		return erro
+	}
+	if mapa, ok := colecao.(*registry.Mapa); ok {
+		valor, existe := mapa.Obter(indiceValor)
+		if !existe {
+			return utils.NovoErro("chave não encontrada no mapa", indexacao.Token.Position.Line, indexacao.Token.Position.Column, "")
+		}
+		return valor
+	}
+	elementos, indice, erro := i.verificarIndice(colecao, indiceValor, indexacao.Token)
+	if erro != nil {
+		return erro
+	}
+	return (*elementos)[indice]
+}
+
+func (i *InterpreterBackend) AtribuicaoIndice(atribuicao *parser.AtribuicaoIndice) interface{} {
+	colecao, indiceValor, erro := i.avaliarColecaoEIndice(atribuicao.Colecao, atribuicao.Indice)
+	if erro != nil {
+		return erro
+	}
+	// Em listas o índice é verificado antes de avaliar o valor; em mapas a chave é
+	// criada se não existir
+	mapa, ehMapa := colecao.(*registry.Mapa)
+	var elementos *[]interface{}
+	var indice int
+	if !ehMapa {
+		if elementos, indice, erro = i.verificarIndice(colecao, indiceValor, atribuicao.Token); erro != nil {
+			return erro
+		}
+	}
+	valor := atribuicao.Valor.Aceitar(i)
+	if erro, ok := valor.(error); ok {
+		return erro
+	}
+	if ehMapa {
+		mapa.Definir(indiceValor, copiarValor(valor))
+	} else {
+		(*elementos)[indice] = copiarValor(valor)
+	}
+	return valor
+}
+
+// avaliarColecaoEIndice avalia a lista ou o mapa e o índice ou a chave de um acesso
+func (i *InterpreterBackend) avaliarColecaoEIndice(colecaoExpr, indiceExpr parser.Expressao) (interface{}, interface{}, error) {
+	colecao := colecaoExpr.Aceitar(i)
+	if erro, ok := colecao.(error); ok {
+		return nil, nil, erro
+	}
+	indice := indiceExpr.Aceitar(i)
+	if erro, ok := indice.(error); ok {
+		return nil, nil, erro
+	}
+	return colecao, indice, nil
+}
+
+// verificarIndice verifica os limites de um acesso a elemento de lista
+func (i *InterpreterBackend) verificarIndice(lista, indiceValor interface{}, token lexer.Token) (*[]interface{}, int, error) {
+	elementos := lista.(*[]interface{})
+	indice := indiceValor.(int)
+	if indice < 0 || indice >= len(*elementos) {
+		return nil, 0, utils.NovoErro("índice fora dos limites da lista", token.Position.Line, token.Position.Column, "")
+	}
+	return elementos, indice, nil
+}
+
 func (i *InterpreterBackend) Variavel(variavel *parser.Variavel) interface{} {
-	valor, existe := i.variaveis[variavel.Nome]
+	valor, existe := i.ambiente.obter(variavel.Nome)
 	if !existe {
+		if variante, ok := i.variantes[variavel.Nome]; ok {
+			return variante
+		}
 		return utils.NovoErro(
 			fmt.Sprintf("variável '%s' não definida", variavel.Nome),
 			variavel.Token.Position.Line,
@@ -147,16 +412,8 @@ func (i *InterpreterBackend) Atribuicao(atribuicao *parser.Atribuicao) interface
 	}
 
 	// Detecta tipo dinamicamente (até ter tipagem estática mais forte aqui)
-	switch v := valorInterface.(type) {
-	case int:
-		i.variaveis[atribuicao.Nome] = Valor{Tipo: parser.TipoInteiro, Dados: v}
-	case bool:
-		i.variaveis[atribuicao.Nome] = Valor{Tipo: parser.TipoBooleano, Dados: v}
-	case float64:
-		i.variaveis[atribuicao.Nome] = Valor{Tipo: parser.TipoDecimal, Dados: v}
-	case string:
-		i.variaveis[atribuicao.Nome] = Valor{Tipo: parser.TipoTexto, Dados: v}
-	default:
+	valor, ok := novoValor(copiarValor(valorInterface))
+	if !ok {
 		return utils.NovoErro(
 			"tipo de valor não suportado na atribuição",
 			atribuicao.Token.Position.Line,
@@ -164,10 +421,20 @@ func (i *InterpreterBackend) Atribuicao(atribuicao *parser.Atribuicao) interface
 			fmt.Sprintf("Tipo: %T", valorInterface),
 		)
 	}
+	// Mesma regra do TypeChecker: anotação de tipo declara no escopo atual (sombreando),
+	// sem anotação reatribui a variável visível mais próxima ou declara uma nova local
+	if atribuicao.TipoAnotado != nil || !i.ambiente.atribuir(atribuicao.Nome, valor) {
+		i.ambiente.definir(atribuicao.Nome, valor)
+	}
 	return valorInterface
 }
 
 func (i *InterpreterBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interface{} {
+	// Operadores lógicos só avaliam o lado direito quando necessário (curto-circuito)
+	if operacao.Operador == parser.E_LOGICO || operacao.Operador == parser.OU_LOGICO {
+		return i.operacaoLogica(operacao)
+	}
+
 	// Avalia operandos com helper
 	esqVal, err := i.evaluateOperand(operacao.OperandoEsquerdo)
 	if err != nil {
@@ -178,6 +445,85 @@ func (i *InterpreterBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) i
 		return err2
 	}
 
+	// Sem coerção implícita: ambos os operandos devem ter o mesmo tipo
+	switch esq := esqVal.(type) {
+	case int:
+		if dir, ok := dirVal.(int); ok {
+			return i.operacaoInteira(operacao, esq, dir)
+		}
+	case float64:
+		if dir, ok := dirVal.(float64); ok {
+			return i.operacaoDecimal(operacao, esq, dir)
+		}
+	case string:
+		if dir, ok := dirVal.(string); ok {
+			return i.operacaoTexto(operacao, esq, dir)
+		}
+	case registry.Variante:
+		// Variantes só podem ser comparadas por igualdade
+		if dir, ok := dirVal.(registry.Variante); ok {
+			switch operacao.Operador {
+			case parser.IGUALDADE:
+				return esq == dir
+			case parser.DIFERENCA:
+				return esq != dir
+			}
+		}
+	}
+	return utils.NovoErro(
+		"tipos incompatíveis",
+		operacao.Token.Position.Line,
+		operacao.Token.Position.Column,
+		fmt.Sprintf("%T %s %T", esqVal, operacao.Operador.String(), dirVal),
+	)
+}
+
+// operacaoLogica avalia 'e'/'ou' com curto-circuito
+func (i *InterpreterBackend) operacaoLogica(operacao *parser.OperacaoBinaria) interface{} {
+	esq := operacao.OperandoEsquerdo.Aceitar(i)
+	if erro, ok := esq.(error); ok {
+		return erro
+	}
+	// 'e' com esquerdo falso e 'ou' com esquerdo verdadeiro já têm o resultado
+	if i.isTruthy(esq) == (operacao.Operador == parser.OU_LOGICO) {
+		return i.isTruthy(esq)
+	}
+	dir := operacao.OperandoDireito.Aceitar(i)
+	if erro, ok := dir.(error); ok {
+		return erro
+	}
+	return i.isTruthy(dir)
+}
+
+// OperacaoUnaria aplica um operador prefixo
+func (i *InterpreterBackend) OperacaoUnaria(operacao *parser.OperacaoUnaria) interface{} {
+	valor := operacao.Operando.Aceitar(i)
+	if erro, ok := valor.(error); ok {
+		return erro
+	}
+	switch operacao.Operador {
+	case parser.NAO_LOGICO:
+		return !i.isTruthy(valor)
+	case parser.NEGACAO:
+		switch v := valor.(type) {
+		case int:
+			return -v
+		case float64:
+			return -v
+		}
+		return utils.NovoErro("operando não numérico", operacao.Token.Position.Line, operacao.Token.Position.Column, fmt.Sprintf("Tipo: %T", valor))
+	case parser.NAO_BIT:
+		if v, ok := valor.(int); ok {
+			return ^v
+		}
+		return utils.NovoErro("operando não inteiro", operacao.Token.Position.Line, operacao.Token.Position.Column, fmt.Sprintf("Tipo: %T", valor))
+	default:
+		return utils.NovoErro("operador desconhecido", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
+	}
+}
+
+// operacaoInteira aplica um operador binário sobre dois inteiros
+func (i *InterpreterBackend) operacaoInteira(operacao *parser.OperacaoBinaria, esqVal, dirVal int) interface{} {
 	switch operacao.Operador {
 	case parser.ADICAO:
 		return esqVal + dirVal
@@ -186,37 +532,143 @@ func (i *InterpreterBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) i
 	case parser.MULTIPLICACAO:
 		return esqVal * dirVal
 	case parser.DIVISAO:
+		// Trunca em direção a zero: -7 / 2 == -3
+		if dirVal == 0 {
+			return utils.NovoErro("divisão por zero", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
+		}
+		return esqVal / dirVal
+	case parser.RESTO:
+		// Resto com o sinal do dividendo: (a / b) * b + a % b == a
 		if dirVal == 0 {
 			return utils.NovoErro("divisão por zero", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
 		}
+		return esqVal % dirVal
+	case parser.POWER:
+		return potenciaInteira(esqVal, dirVal)
+	case parser.E_BIT:
+		return esqVal & dirVal
+	case parser.OU_BIT:
+		return esqVal | dirVal
+	case parser.XOU_BIT:
+		return esqVal ^ dirVal
+	case parser.DESLOCAMENTO_ESQUERDA, parser.DESLOCAMENTO_DIREITA:
+		// Deslocamentos de 64 ou mais resultam em 0 (<<) ou só no bit de sinal (>>, aritmético)
+		if dirVal < 0 {
+			return utils.NovoErro("deslocamento negativo", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
+		}
+		if operacao.Operador == parser.DESLOCAMENTO_ESQUERDA {
+			return esqVal << dirVal
+		}
+		return esqVal >> dirVal
+	case parser.IGUALDADE:
+		return esqVal == dirVal
+	case parser.DIFERENCA:
+		return esqVal != dirVal
+	case parser.MENOR_QUE:
+		return esqVal < dirVal
+	case parser.MAIOR_QUE:
+		return esqVal > dirVal
+	case parser.MENOR_IGUAL:
+		return esqVal <= dirVal
+	case parser.MAIOR_IGUAL:
+		return esqVal >= dirVal
+	default:
+		return utils.NovoErro("operador desconhecido", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
+	}
+}
+
+// potenciaInteira calcula base^expoente por quadrados sucessivos (transborda como a
+// multiplicação); com expoente negativo 1/base^n trunca para 0, exceto para as bases 1 e -1
+func potenciaInteira(base, expoente int) int {
+	if expoente < 0 {
+		switch {
+		case base == 1:
+			return 1
+		case base == -1 && expoente%2 != 0:
+			return -1
+		case base == -1:
+			return 1
+		}
+		return 0
+	}
+	resultado := 1
+	for expoente > 0 {
+		if expoente&1 != 0 {
+			resultado *= base
+		}
+		base *= base
+		expoente >>= 1
+	}
+	return resultado
+}
+
+// operacaoDecimal aplica um operador binário sobre dois decimais (IEEE 754, precisão dupla)
+func (i *InterpreterBackend) operacaoDecimal(operacao *parser.OperacaoBinaria, esqVal, dirVal float64) interface{} {
+	switch operacao.Operador {
+	case parser.ADICAO:
+		return esqVal + dirVal
+	case parser.SUBTRACAO:
+		return esqVal - dirVal
+	case parser.MULTIPLICACAO:
+		return esqVal * dirVal
+	case parser.DIVISAO:
+		// Divisão decimal segue IEEE 754: x/0 resulta em ±Inf ou NaN
 		return esqVal / dirVal
+	case parser.RESTO:
+		// Como fmod: sinal do dividendo, x % 0 resulta em NaN
+		return math.Mod(esqVal, dirVal)
 	case parser.POWER:
-		return int(math.Pow(float64(esqVal), float64(dirVal)))
+		// Os backends nativos usam o pow da libm: com expoente fracionário o resultado
+		// pode diferir do de math.Pow no último dígito
+		return math.Pow(esqVal, dirVal)
+	case parser.IGUALDADE:
+		return esqVal == dirVal
+	case parser.DIFERENCA:
+		return esqVal != dirVal
+	case parser.MENOR_QUE:
+		return esqVal < dirVal
+	case parser.MAIOR_QUE:
+		return esqVal > dirVal
+	case parser.MENOR_IGUAL:
+		return esqVal <= dirVal
+	case parser.MAIOR_IGUAL:
+		return esqVal >= dirVal
+	default:
+		return utils.NovoErro("operador desconhecido", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
+	}
+}
+
+// operacaoTexto aplica um operador binário sobre dois textos: '+' concatena e as
+// comparações seguem a ordem lexicográfica dos bytes UTF-8
+func (i *InterpreterBackend) operacaoTexto(operacao *parser.OperacaoBinaria, esqVal, dirVal string) interface{} {
+	switch operacao.Operador {
+	case parser.ADICAO:
+		return esqVal + dirVal
 	case parser.IGUALDADE:
-		return i.compareInts(esqVal == dirVal)
+		return esqVal == dirVal
 	case parser.DIFERENCA:
-		return i.compareInts(esqVal != dirVal)
+		return esqVal != dirVal
 	case parser.MENOR_QUE:
-		return i.compareInts(esqVal < dirVal)
+		return esqVal < dirVal
 	case parser.MAIOR_QUE:
-		return i.compareInts(esqVal > dirVal)
+		return esqVal > dirVal
 	case parser.MENOR_IGUAL:
-		return i.compareInts(esqVal <= dirVal)
+		return esqVal <= dirVal
 	case parser.MAIOR_IGUAL:
-		return i.compareInts(esqVal >= dirVal)
+		return esqVal >= dirVal
 	default:
 		return utils.NovoErro("operador desconhecido", operacao.Token.Position.Line, operacao.Token.Position.Column, "")
 	}
 }
 
-// evaluateOperand avalia uma expressão e garante retorno int (simplifiquei, talvez altero na prox)
-func (i *InterpreterBackend) evaluateOperand(expr parser.Expressao) (int, error) {
+// evaluateOperand avalia uma expressão e garante retorno numérico (int ou float64) ou texto
+func (i *InterpreterBackend) evaluateOperand(expr parser.Expressao) (interface{}, error) {
 	v := expr.Aceitar(i)
 	if erro, ok := v.(error); ok {
-		return 0, erro
+		return nil, erro
 	}
 	switch val := v.(type) {
-	case int:
+	case int, float64, string, registry.Variante:
 		return val, nil
 	case bool:
 		// Permite usar booleano em contexto numérico (true=1,false=0)
@@ -225,7 +677,7 @@ func (i *InterpreterBackend) evaluateOperand(expr parser.Expressao) (int, error)
 		}
 		return 0, nil
 	default:
-		return 0, utils.NovoErro(
+		return nil, utils.NovoErro(
 			"operando não numérico",
 			0, 0,
 			fmt.Sprintf("Tipo: %T", v),
@@ -233,14 +685,6 @@ func (i *InterpreterBackend) evaluateOperand(expr parser.Expressao) (int, error)
 	}
 }
 
-// compareInts converte bool para 1/0
-func (i *InterpreterBackend) compareInts(cond bool) int {
-	if cond {
-		return 1
-	}
-	return 0
-}
-
 // ChamadaFuncao implementa chamadas de função builtin
 func (i *InterpreterBackend) ChamadaFuncao(chamada *parser.ChamadaFuncao) interface{} {
 	// 1. Verifica prelude primeiro (funções sempre disponíveis)
@@ -273,14 +717,15 @@ func (i *InterpreterBackend) ChamadaFuncao(chamada *parser.ChamadaFuncao) interf
 		)
 	}
 
-	// Avalia todos os argumentos primeiro
+	// Avalia todos os argumentos primeiro (anexar guarda o registro recebido, então
+	// registros são copiados)
 	argumentos := make([]interface{}, len(chamada.Argumentos))
 	for idx, argumento := range chamada.Argumentos {
 		valorInterface := argumento.Aceitar(i)
 		if erro, ok := valorInterface.(error); ok {
 			return erro
 		}
-		argumentos[idx] = valorInterface
+		argumentos[idx] = copiarValor(valorInterface)
 	}
 
 	// Valida argumentos (apenas verificação de quantidade em tempo de compilação)
@@ -313,7 +758,7 @@ func (i *InterpreterBackend) executarFuncaoBuiltin(nome string, tipo registry.Ti
 	switch tipo {
 	case registry.FUNCAO_IMPRIME:
 		return i.executarImprime(args)
-	case registry.FUNCAO_PURA:
+	case registry.FUNCAO_PURA, registry.FUNCAO_RUNTIME:
 		// Para funções puras, use a implementação do registro
 		resultado, err := registry.RegistroGlobal.ExecutarFuncao(nome, args)
 		if err != nil {
@@ -362,6 +807,29 @@ func (i *InterpreterBackend) formatarValor(v interface{}) string {
 		return strconv.FormatFloat(val, 'g', -1, 64)
 	case string:
 		return val
+	case *[]interface{}:
+		// Listas: [1, 2, 3], com os elementos formatados pela mesma regra
+		elementos := make([]string, len(*val))
+		for idx, elemento := range *val {
+			elementos[idx] = i.formatarValor(elemento)
+		}
+		return "[" + strings.Join(elementos, ", ") + "]"
+	case *registry.Mapa:
+		// Mapas: {a: 1, b: 2}, em ordem de inserção
+		entradas := make([]string, len(val.Chaves))
+		for idx, chave := range val.Chaves {
+			entradas[idx] = i.formatarValor(chave) + ": " + i.formatarValor(val.Valores[chave])
+		}
+		return "{" + strings.Join(entradas, ", ") + "}"
+	case *registry.Registro:
+		// Registros: Ponto{x: 1, y: 2}, na ordem da declaração
+		campos := make([]string, len(val.Campos))
+		for idx, campo := range val.Campos {
+			campos[idx] = campo + ": " + i.formatarValor(val.Valores[idx])
+		}
+		return val.Nome + "{" + strings.Join(campos, ", ") + "}"
+	case registry.Variante:
+		return val.Nome
 	default:
 		return fmt.Sprintf("%v", val)
 	}
@@ -377,6 +845,16 @@ func (i *InterpreterBackend) ComandoSe(comando *parser.ComandoSe) interface{} {
 	if verdade {
 		return comando.BlocoSe.Aceitar(i)
 	}
+	// Ramos "senao se" em ordem: executa o primeiro cuja condição é verdadeira
+	for _, ramo := range comando.SenaoSe {
+		condRaw := ramo.Condicao.Aceitar(i)
+		if erro, ok := condRaw.(error); ok {
+			return erro
+		}
+		if i.isTruthy(condRaw) {
+			return ramo.Bloco.Aceitar(i)
+		}
+	}
 	if comando.BlocoSenao != nil {
 		return comando.BlocoSenao.Aceitar(i)
 	}
@@ -401,6 +879,15 @@ func (i *InterpreterBackend) ComandoEnquanto(cmd *parser.ComandoEnquanto) interf
 		if rv, ok := r.(retornoValor); ok {
 			return rv
 		}
+		if sinal, ok := r.(sinalLaco); ok {
+			if !sinal.pertenceA(cmd.Rotulo) {
+				return sinal // laço externo
+			}
+			if sinal.continuar {
+				continue
+			}
+			break
+		}
 		ultimo = r
 	}
 	return ultimo
@@ -408,6 +895,11 @@ func (i *InterpreterBackend) ComandoEnquanto(cmd *parser.ComandoEnquanto) interf
 
 // Para (for)
 func (i *InterpreterBackend) ComandoPara(cmd *parser.ComandoPara) interface{} {
+	// A variável de controle vive em um escopo que envolve o laço
+	anterior := i.ambiente
+	i.ambiente = novoAmbiente(anterior)
+	defer func() { i.ambiente = anterior }()
+
 	if cmd.Inicializacao != nil {
 		v := cmd.Inicializacao.Aceitar(i)
 		if erro, ok := v.(error); ok {
@@ -434,7 +926,17 @@ func (i *InterpreterBackend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 		if rv, ok := r.(retornoValor); ok {
 			return rv
 		}
-		ultimo = r
+		if sinal, ok := r.(sinalLaco); ok {
+			if !sinal.pertenceA(cmd.Rotulo) {
+				return sinal // laço externo
+			}
+			if !sinal.continuar {
+				break
+			}
+		} else {
+			ultimo = r
+		}
+		// 'continuar' também executa a pós-iteração
 		if cmd.PosIteracao != nil {
 			p := cmd.PosIteracao.Aceitar(i)
 			if erro, ok := p.(error); ok {
@@ -445,35 +947,124 @@ func (i *InterpreterBackend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 	return ultimo
 }
 
+// ParaCada percorre os elementos de uma lista (o tamanho é lido uma única vez) ou as
+// entradas que um mapa tinha no início do laço, em ordem de inserção
+func (i *InterpreterBackend) ComandoParaCada(cmd *parser.ComandoParaCada) interface{} {
+	colecao := cmd.Colecao.Aceitar(i)
+	if erro, ok := colecao.(error); ok {
+		return erro
+	}
+	variaveis := []string{cmd.Variavel}
+	if mapa, ok := colecao.(*registry.Mapa); ok {
+		chaves := append([]interface{}{}, mapa.Chaves...)
+		valores := make([]interface{}, len(chaves))
+		for idx, chave := range chaves {
+			valores[idx] = mapa.Valores[chave]
+		}
+		if cmd.Valor != "" {
+			variaveis = append(variaveis, cmd.Valor)
+		}
+		indice := 0
+		return i.iterar(variaveis, cmd.Rotulo, cmd.Corpo, func() ([]interface{}, bool) {
+			if indice >= len(chaves) {
+				return nil, false
+			}
+			indice++
+			return []interface{}{chaves[indice-1], valores[indice-1]}, true
+		})
+	}
+	elementos := colecao.(*[]interface{})
+	tamanho, indice := len(*elementos), 0
+	return i.iterar(variaveis, cmd.Rotulo, cmd.Corpo, func() ([]interface{}, bool) {
+		if indice >= tamanho {
+			return nil, false
+		}
+		indice++
+		return []interface{}{(*elementos)[indice-1]}, true
+	})
+}
+
+// ParaIntervalo percorre um intervalo de inteiros; início, fim e passo são avaliados
+// uma única vez e um passo negativo conta para baixo
+func (i *InterpreterBackend) ComandoParaIntervalo(cmd *parser.ComandoParaIntervalo) interface{} {
+	limites := []int{0, 0, 1} // início, fim, passo
+	for idx, expr := range []parser.Expressao{cmd.Inicio, cmd.Fim, cmd.Passo} {
+		if expr == nil {
+			continue
+		}
+		v := expr.Aceitar(i)
+		if erro, ok := v.(error); ok {
+			return erro
+		}
+		limites[idx] = v.(int)
+	}
+	atual, fim, passo := limites[0], limites[1], limites[2]
+	if passo == 0 {
+		return utils.NovoErro("passo do laço 'para' não pode ser zero", cmd.Token.Position.Line, cmd.Token.Position.Column, "")
+	}
+	acabou := false
+	return i.iterar([]string{cmd.Variavel}, cmd.Rotulo, cmd.Corpo, func() ([]interface{}, bool) {
+		if acabou || (passo > 0 && atual > fim) || (passo < 0 && atual < fim) || (atual == fim && !cmd.Inclusivo) {
+			return nil, false
+		}
+		valor := atual
+		atual += passo
+		// Se a soma transbordar, o próximo valor passaria do fim: o laço acaba
+		acabou = (passo > 0) != (atual > valor)
+		return []interface{}{valor}, true
+	})
+}
+
+// iterar executa o corpo de um laço de iteração para cada grupo de valores de proximo
+// (que retorna false ao acabar), com as variáveis do laço em um escopo novo a cada iteração
+func (i *InterpreterBackend) iterar(variaveis []string, rotulo string, corpo *parser.Bloco, proximo func() ([]interface{}, bool)) interface{} {
+	anterior := i.ambiente
+	defer func() { i.ambiente = anterior }()
+
+	var ultimo interface{} = 0
+	for {
+		valores, ok := proximo()
+		if !ok {
+			break
+		}
+		i.ambiente = novoAmbiente(anterior)
+		for idx, variavel := range variaveis {
+			v, _ := novoValor(copiarValor(valores[idx]))
+			i.ambiente.definir(variavel, v)
+		}
+
+		r := corpo.Aceitar(i)
+		if erro, ok := r.(error); ok {
+			return erro
+		}
+		if rv, ok := r.(retornoValor); ok {
+			return rv
+		}
+		if sinal, ok := r.(sinalLaco); ok {
+			if !sinal.pertenceA(rotulo) {
+				return sinal // laço externo
+			}
+			if sinal.continuar {
+				continue
+			}
+			break
+		}
+		ultimo = r
+	}
+	return ultimo
+}
+
 // Bloco implementa um bloco de comandos
 func (i *InterpreterBackend) Bloco(bloco *parser.Bloco) interface{} {
 	var ultimoResultado interface{} = 0
 
+	// Cada bloco abre um escopo próprio
+	anterior := i.ambiente
+	i.ambiente = novoAmbiente(anterior)
+	defer func() { i.ambiente = anterior }()
+
 	// Executa todos os comandos do bloco
 	for _, comando := range bloco.Comandos {
-		// Se for um retorno, propaga uma interrupção especial
-		if ret, ok := comando.(*parser.Retorno); ok {
-			if ret.Valor == nil {
-				return retornoValor{valor: 0}
-			}
-			val := ret.Valor.Aceitar(i)
-			if erro, ok := val.(error); ok {
-				return erro
-			}
-			// Converte retorno não-int para int por enquanto (bool -> 0/1) até ter suporte tipado
-			switch x := val.(type) {
-			case int:
-				return retornoValor{valor: x}
-			case bool:
-				if x {
-					return retornoValor{valor: 1}
-				}
-				return retornoValor{valor: 0}
-			default:
-				return retornoValor{valor: 0}
-			}
-		}
-
 		resultado := comando.Aceitar(i)
 		if erro, ok := resultado.(error); ok {
 			return erro
@@ -482,6 +1073,10 @@ func (i *InterpreterBackend) Bloco(bloco *parser.Bloco) interface{} {
 		if rv, ok := resultado.(retornoValor); ok {
 			return rv
 		}
+		// 'parar'/'continuar' interrompem o bloco até o laço alvo
+		if sinal, ok := resultado.(sinalLaco); ok {
+			return sinal
+		}
 		ultimoResultado = resultado
 	}
 
@@ -497,23 +1092,22 @@ func (i *InterpreterBackend) FuncaoDeclaracao(fn *parser.FuncaoDeclaracao) inter
 // Suporte a retorno (em nível de execução de bloco)
 func (i *InterpreterBackend) Retorno(ret *parser.Retorno) interface{} {
 	if ret.Valor == nil {
-		return retornoValor{valor: 0}
+		return retornoValor{valor: Valor{Tipo: parser.TipoVazio}}
 	}
 	val := ret.Valor.Aceitar(i)
 	if erro, ok := val.(error); ok {
 		return erro
 	}
-	switch x := val.(type) {
-	case int:
-		return retornoValor{valor: x}
-	case bool:
-		if x {
-			return retornoValor{valor: 1}
-		}
-		return retornoValor{valor: 0}
-	default:
-		return retornoValor{valor: 0}
+	valor, ok := novoValor(val)
+	if !ok {
+		return utils.NovoErro(
+			"tipo de valor não suportado no retorno",
+			ret.Token.Position.Line,
+			ret.Token.Position.Column,
+			fmt.Sprintf("Tipo: %T", val),
+		)
 	}
+	return retornoValor{valor: valor}
 }
 
 // Suporte a importação (processadas antes da interpretação)
@@ -524,7 +1118,54 @@ func (i *InterpreterBackend) Importacao(imp *parser.Importacao) interface{} {
 }
 
 // Estrutura para propagar retorno através do visitor
-type retornoValor struct{ valor int }
+type retornoValor struct{ valor Valor }
+
+// sinalLaco propaga 'parar'/'continuar' até o laço alvo
+type sinalLaco struct {
+	continuar bool
+	rotulo    string
+}
+
+// pertenceA indica se o sinal é para o laço com o rótulo dado (sem rótulo: o mais interno)
+func (s sinalLaco) pertenceA(rotulo string) bool {
+	return s.rotulo == "" || s.rotulo == rotulo
+}
+
+func (i *InterpreterBackend) Parar(parar *parser.Parar) interface{} {
+	return sinalLaco{rotulo: parar.Rotulo}
+}
+
+func (i *InterpreterBackend) Continuar(continuar *parser.Continuar) interface{} {
+	return sinalLaco{continuar: true, rotulo: continuar.Rotulo}
+}
+
+// novoValor empacota um valor produzido pelo visitor em um Valor tipado (nil representa vazio)
+func novoValor(v interface{}) (Valor, bool) {
+	switch x := v.(type) {
+	case int:
+		return Valor{Tipo: parser.TipoInteiro, Dados: x}, true
+	case bool:
+		return Valor{Tipo: parser.TipoBooleano, Dados: x}, true
+	case float64:
+		return Valor{Tipo: parser.TipoDecimal, Dados: x}, true
+	case string:
+		return Valor{Tipo: parser.TipoTexto, Dados: x}, true
+	case *[]interface{}:
+		// Listas e mapas não guardam o tipo dos elementos em tempo de execução: o tipo
+		// aqui só diz que o valor é uma lista (ou um mapa), sem os tipos internos
+		return Valor{Tipo: parser.TipoLista(parser.TipoVazio), Dados: x}, true
+	case *registry.Mapa:
+		return Valor{Tipo: parser.TipoMapa(parser.TipoVazio, parser.TipoVazio), Dados: x}, true
+	case *registry.Registro:
+		return Valor{Tipo: parser.TipoNomeado(x.Nome), Dados: x}, true
+	case registry.Variante:
+		return Valor{Tipo: parser.TipoNomeado(x.Enumeracao), Dados: x}, true
+	case nil:
+		return Valor{Tipo: parser.TipoVazio}, true
+	default:
+		return Valor{}, false
+	}
+}
 
 // Executa função definida pelo usuário com escopo local
 func (i *InterpreterBackend) executarFuncaoUsuario(fn *parser.FuncaoDeclaracao, chamada *parser.ChamadaFuncao) interface{} {
@@ -538,51 +1179,60 @@ func (i *InterpreterBackend) executarFuncaoUsuario(fn *parser.FuncaoDeclaracao,
 		)
 	}
 
-	// Salva contexto de variáveis e cria escopo local
-	antigo := i.variaveis
-	local := make(map[string]Valor)
-	i.variaveis = local
-
-	// Avalia e vincula parâmetros
+	// Avalia os argumentos no escopo de quem chama e vincula aos parâmetros
+	// em um escopo novo cujo pai é o escopo do módulo (escopo léxico)
+	local := novoAmbiente(i.global)
 	for idx, param := range fn.Parametros {
 		v := chamada.Argumentos[idx].Aceitar(i)
 		if erro, ok := v.(error); ok {
-			i.variaveis = antigo
 			return erro
 		}
-		// Armazena dinamicamente conforme tipo recebido
-		switch x := v.(type) {
-		case int:
-			local[param.Nome] = Valor{Tipo: parser.TipoInteiro, Dados: x}
-		case bool:
-			local[param.Nome] = Valor{Tipo: parser.TipoBooleano, Dados: x}
-		case float64:
-			local[param.Nome] = Valor{Tipo: parser.TipoDecimal, Dados: x}
-		case string:
-			local[param.Nome] = Valor{Tipo: parser.TipoTexto, Dados: x}
-		default:
-			local[param.Nome] = Valor{Tipo: parser.TipoVazio, Dados: 0}
+		// Armazena dinamicamente conforme tipo recebido (registros por cópia)
+		valor, ok := novoValor(copiarValor(v))
+		if !ok {
+			valor = Valor{Tipo: parser.TipoVazio}
 		}
+		local.definir(param.Nome, valor)
 	}
 
-	// Executa corpo
+	// Executa corpo no escopo da função e restaura o escopo de quem chamou
+	anterior := i.ambiente
+	i.ambiente = local
 	resultado := fn.Corpo.Aceitar(i)
+	i.ambiente = anterior
 
-	// Restaura escopo
-	i.variaveis = antigo
-
-	// Trata retorno explícito
-	if rv, ok := resultado.(retornoValor); ok {
-		return rv.valor
-	}
-
-	// Retorno implícito: valor da última expressão do bloco (0 se vazio)
+	var retorno Valor
 	switch r := resultado.(type) {
-	case int:
+	case error:
 		return r
+	case retornoValor:
+		// Retorno explícito
+		retorno = r.valor
 	default:
-		return 0
+		if fn.Retorno == parser.TipoVazio {
+			retorno = Valor{Tipo: parser.TipoVazio}
+			break
+		}
+		// Retorno implícito: valor da última expressão do bloco
+		valor, ok := novoValor(r)
+		if !ok {
+			valor = Valor{Tipo: parser.TipoVazio}
+		}
+		retorno = valor
+	}
+
+	// O retorno de listas e mapas não é comparado: os valores não têm o tipo dos
+	// elementos (ver novoValor), e o TypeChecker já verificou o retorno deles
+	composto := fn.Retorno.EhLista() || fn.Retorno.EhMapa()
+	if !composto && retorno.Tipo != fn.Retorno {
+		return utils.NovoErro(
+			"tipo de retorno incompatível",
+			chamada.Token.Position.Line,
+			chamada.Token.Position.Column,
+			fmt.Sprintf("função '%s' declara retorno %s, retornou %s", fn.Nome, fn.Retorno.String(), retorno.Tipo.String()),
+		)
 	}
+	return retorno.Dados
 }
 
 // isTruthy aplica a regra de verdade: int != 0, bool == valor, outros => falso
@@ -2,6 +2,7 @@ package llvm
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/llir/llvm/ir/value"
 
 	"github.com/khevencolino/Solar/internal/debug"
+	"github.com/khevencolino/Solar/internal/lexer"
 	"github.com/khevencolino/Solar/internal/parser"
 	"github.com/khevencolino/Solar/internal/registry"
 	"github.com/khevencolino/Solar/internal/utils"
@@ -24,10 +26,31 @@ type LLVMBackend struct {
 	variables  map[string]value.Value
 	varStack   []map[string]value.Value
 	userFuncs  map[string]*ir.Func
+	mainFunc   *ir.Func // função main, onde vivem os statements globais
 	tmpCount   int
 	strCount   int
 	printfFn   *ir.Func              // cache para printf
 	fmtGlobals map[string]*ir.Global // cache para strings de formato
+	globais    map[string]int        // nomes de globais já usados (para nomes únicos)
+	externas   map[string]*ir.Func   // funções da libc/libm declaradas sob demanda
+	escreveDec *ir.Func              // rotina que escreve decimais como o interpretador
+	rotinas    map[string]*ir.Func   // rotinas de texto geradas sob demanda
+	stdout     *ir.Global            // FILE* da saída padrão (libc)
+	erroExec   *ir.Func              // rotina de erro de execução (mensagem em stderr e exit(1))
+	lacos      []destinoLaco         // laços envolventes, do mais externo ao mais interno
+	blocoCount int                   // contador para nomes únicos de blocos
+
+	estruturas map[parser.Tipo]*types.StructType // estruturas dos registros já usados
+
+	tipos      map[parser.Expressao]parser.Tipo // tipos inferidos na checagem
+	declarados *parser.TiposDeclarados          // registros e enumerações da compilação
+}
+
+// destinoLaco guarda os blocos de saída e de continuação de um laço envolvente
+type destinoLaco struct {
+	rotulo    string // rótulo do laço no código fonte ("" se não houver)
+	parar     *ir.Block
+	continuar *ir.Block
 }
 
 func NewLLVMBackend() *LLVMBackend {
@@ -38,17 +61,81 @@ func NewLLVMBackend() *LLVMBackend {
 		tmpCount:   0,
 		strCount:   0,
 		fmtGlobals: make(map[string]*ir.Global),
+		globais:    make(map[string]int),
+		externas:   make(map[string]*ir.Func),
+		rotinas:    make(map[string]*ir.Func),
+		estruturas: make(map[parser.Tipo]*types.StructType),
+		declarados: parser.NovosTiposDeclarados(),
+	}
+}
+
+// ptrI8 é o tipo dos textos (char*)
+var ptrI8 = types.NewPointer(types.I8)
+
+// tipoLLVM converte um tipo da linguagem para o tipo LLVM correspondente
+func (l *LLVMBackend) tipoLLVM(t parser.Tipo) types.Type {
+	if t.EhLista() {
+		return ptrLista
+	}
+	if t.EhMapa() {
+		return ptrMapa
+	}
+	if l.declarados.EhRegistro(t) {
+		return types.NewPointer(l.estruturaRegistro(t))
+	}
+	switch t {
+	case parser.TipoDecimal:
+		return types.Double
+	case parser.TipoTexto:
+		return ptrI8
+	case parser.TipoBooleano:
+		return types.I1
+	case parser.TipoVazio:
+		return types.Void
+	default:
+		return types.I64
+	}
+}
+
+// valorZero retorna o valor inicial de um tipo LLVM (usado em globais e retornos padrão)
+func valorZero(t types.Type) constant.Constant {
+	switch tp := t.(type) {
+	case *types.FloatType:
+		return constant.NewFloat(tp, 0)
+	case *types.PointerType:
+		return constant.NewNull(tp)
+	case *types.IntType:
+		return constant.NewInt(tp, 0)
+	default:
+		return constant.NewZeroInitializer(t)
 	}
 }
 
 func (l *LLVMBackend) GetName() string      { return "LLVM IR" }
 func (l *LLVMBackend) GetExtension() string { return ".ll" }
 
+// DefinirTipos recebe os tipos inferidos na checagem e os registros e enumerações
+// declarados (implementa backends.BackendTipado)
+func (l *LLVMBackend) DefinirTipos(tipos map[parser.Expressao]parser.Tipo, declarados *parser.TiposDeclarados) {
+	l.tipos = tipos
+	l.declarados = declarados
+}
+
+// tipoDe retorna o tipo inferido da expressão (inteiro se desconhecido)
+func (l *LLVMBackend) tipoDe(expr parser.Expressao) parser.Tipo {
+	if tp, ok := l.tipos[expr]; ok {
+		return tp
+	}
+	return parser.TipoInteiro
+}
+
 func (l *LLVMBackend) Compile(statements []parser.Expressao) error {
 	debug.Printf("Compilando para LLVM IR...\n")
 
 	// Inicializa módulo LLVM
 	l.module = ir.NewModule()
+	l.module.NewTypeDef("lista", tipoLista)
+	l.module.NewTypeDef("mapa", tipoMapa)
 
 	// Declara função printf para impressão e guarda referência
 	l.printfFn = l.module.NewFunc("printf", types.I32, ir.NewParam("format", types.NewPointer(types.I8)))
@@ -65,37 +152,38 @@ func (l *LLVMBackend) Compile(statements []parser.Expressao) error {
 		}
 	}
 
-	// Segunda passada: definir corpos das funções do usuário
-	for _, st := range statements {
-		if fn, ok := st.(*parser.FuncaoDeclaracao); ok {
-			l.definirFuncaoUsuario(fn)
-		}
-	}
-
 	// Declara função main
 	l.function = l.module.NewFunc("main", types.I32)
-	l.block = l.function.NewBlock("")
+	l.mainFunc = l.function
+	l.block = l.function.NewBlock("entry")
+
+	// Inicializa o módulo: statements globais em ordem de código fonte
+	// (símbolos importados já vêm antes dos statements do programa)
+	for i, stmt := range statements {
+		// Pula declarações de função, definidas depois que as globais existirem
+		if _, ok := stmt.(*parser.FuncaoDeclaracao); !ok {
+			debug.Printf("  Processando statement global %d...\n", i+1)
+			l.processarExpressao(stmt)
+		}
+	}
 
-	// Se existe função principal(), chama ela. Senão, executa statements globais
+	// Com as globais inicializadas, chama a função principal() se existir
 	if funcaoPrincipal != nil {
 		debug.Printf("  Chamando função principal()...\n")
-		// Chama a função principal()
 		principalFunc := l.userFuncs[funcaoPrincipal.Nome]
 		l.block.NewCall(principalFunc)
-	} else {
-		// Processa statements globais (comportamento antigo)
-		for i, stmt := range statements {
-			// Pula declarações de função pois já foram processadas
-			if _, ok := stmt.(*parser.FuncaoDeclaracao); !ok {
-				debug.Printf("  Processando statement global %d...\n", i+1)
-				l.processarExpressao(stmt)
-			}
-		}
 	}
 
 	// Retorna 0
 	l.block.NewRet(constant.NewInt(types.I32, 0))
 
+	// Segunda passada: definir corpos das funções do usuário (enxergam as globais)
+	for _, st := range statements {
+		if fn, ok := st.(*parser.FuncaoDeclaracao); ok {
+			l.definirFuncaoUsuario(fn)
+		}
+	}
+
 	// Escreve arquivo LLVM IR
 	arquivoSaida := "programa.ll"
 	if err := utils.EscreverArquivo(arquivoSaida, l.module.String()); err != nil {
@@ -133,10 +221,7 @@ func (l *LLVMBackend) Constante(constante *parser.Constante) interface{} {
 }
 
 func (l *LLVMBackend) Booleano(b *parser.Booleano) interface{} {
-	if b.Valor {
-		return l.i64(1)
-	}
-	return l.i64(0)
+	return constant.NewBool(b.Valor)
 }
 
 func (l *LLVMBackend) LiteralTexto(literal *parser.LiteralTexto) interface{} {
@@ -159,36 +244,108 @@ func (l *LLVMBackend) LiteralDecimal(literal *parser.LiteralDecimal) interface{}
 
 func (l *LLVMBackend) Variavel(variavel *parser.Variavel) interface{} {
 	if val, ok := l.getVar(variavel.Nome); ok {
-		// Se é um ponteiro (alloca), carrega o valor
-		if alloca, isAlloca := val.(*ir.InstAlloca); isAlloca {
-			return l.block.NewLoad(types.I64, alloca)
+		// Um registro é usado pelo endereço da estrutura guardada na variável
+		if l.declarados.EhRegistro(l.tipoDe(variavel)) {
+			return val
+		}
+		// Se é um ponteiro (alloca ou global), carrega o valor
+		if tipo := tipoArmazenado(val); tipo != nil {
+			return l.block.NewLoad(tipo, val)
 		}
 		return val
 	}
+	if variante, ok := l.varianteEnumeracao(variavel); ok {
+		return variante
+	}
 	fmt.Printf("Variável '%s' não definida\n", variavel.Nome)
 	return l.i64(0)
 }
 
 func (l *LLVMBackend) Atribuicao(atribuicao *parser.Atribuicao) interface{} {
 	valor := l.processarExpressaoValue(atribuicao.Valor)
+	// A variável de um registro guarda a estrutura inteira: a atribuição copia a origem
+	registro := l.declarados.EhRegistro(l.tipoDe(atribuicao.Valor))
+	if registro {
+		valor = l.block.NewLoad(l.estruturaRegistro(l.tipoDe(atribuicao.Valor)), valor)
+	}
 
-	// Verifica se a variável já existe
-	if existente, ok := l.getVar(atribuicao.Nome); ok {
-		// Se é um alloca existente, armazena nele
-		if alloca, isAlloca := existente.(*ir.InstAlloca); isAlloca {
-			l.block.NewStore(valor, alloca)
-			return valor
+	// Verifica se a variável já existe (anotação de tipo declara nova variável,
+	// exceto quando a variável já pertence ao escopo atual com o mesmo tipo)
+	_, noEscopoAtual := l.variables[atribuicao.Nome]
+	if existente, ok := l.getVar(atribuicao.Nome); ok && (atribuicao.TipoAnotado == nil || noEscopoAtual) {
+		// Se é um alloca ou global existente, armazena nele
+		if tipo := tipoArmazenado(existente); tipo != nil && tipo.Equal(valor.Type()) {
+			l.block.NewStore(valor, existente)
+			return resultadoAtribuicao(valor, existente, registro)
 		}
 	}
 
+	// Variáveis do escopo do módulo viram globais, visíveis dentro das funções
+	if l.ehEscopoGlobal() {
+		global := l.module.NewGlobalDef(l.nomeGlobal("var_"+atribuicao.Nome), valorZero(valor.Type()))
+		l.block.NewStore(valor, global)
+		l.setVar(atribuicao.Nome, global)
+		return resultadoAtribuicao(valor, global, registro)
+	}
+
 	// Cria nova variável usando alloca
-	alloca := l.block.NewAlloca(types.I64)
+	alloca := l.novaAlloca(valor.Type())
 	l.block.NewStore(valor, alloca)
 	l.setVar(atribuicao.Nome, alloca)
+	return resultadoAtribuicao(valor, alloca, registro)
+}
+
+// resultadoAtribuicao é o valor de uma atribuição; para um registro, o endereço da variável
+func resultadoAtribuicao(valor, variavel value.Value, registro bool) value.Value {
+	if registro {
+		return variavel
+	}
 	return valor
 }
 
+// tipoArmazenado retorna o tipo do valor guardado em uma variável (alloca ou global),
+// ou nil se o valor não é um endereço de variável
+func tipoArmazenado(v value.Value) types.Type {
+	switch ptr := v.(type) {
+	case *ir.InstAlloca:
+		return ptr.ElemType
+	case *ir.Global:
+		return ptr.ContentType
+	}
+	return nil
+}
+
+// novaAlloca reserva a variável no bloco de entrada da função, para que
+// laços não aumentem a pilha a cada iteração
+func (l *LLVMBackend) novaAlloca(tipo types.Type) *ir.InstAlloca {
+	alloca := ir.NewAlloca(tipo)
+	entrada := l.function.Blocks[0]
+	entrada.Insts = append([]ir.Instruction{alloca}, entrada.Insts...)
+	return alloca
+}
+
+// nomeGlobal garante nomes únicos para globais (uma variável do módulo pode ser
+// redeclarada com outro tipo)
+func (l *LLVMBackend) nomeGlobal(nome string) string {
+	n := l.globais[nome]
+	l.globais[nome]++
+	if n == 0 {
+		return nome
+	}
+	return fmt.Sprintf("%s.%d", nome, n)
+}
+
+// ehEscopoGlobal indica se o código atual está no escopo do módulo (fora de funções e blocos)
+func (l *LLVMBackend) ehEscopoGlobal() bool {
+	return l.function == l.mainFunc && len(l.varStack) == 0
+}
+
 func (l *LLVMBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interface{} {
+	// Operadores lógicos avaliam o lado direito em um bloco próprio (curto-circuito)
+	if operacao.Operador == parser.E_LOGICO || operacao.Operador == parser.OU_LOGICO {
+		return l.operacaoLogica(operacao)
+	}
+
 	esquerda := l.processarExpressaoValue(operacao.OperandoEsquerdo)
 	direita := l.processarExpressaoValue(operacao.OperandoDireito)
 
@@ -197,6 +354,14 @@ func (l *LLVMBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interfac
 		return l.i64(0)
 	}
 
+	// Os dois operandos têm o mesmo tipo (garantido pelo TypeChecker)
+	if esquerda.Type().Equal(types.Double) {
+		return l.operacaoDecimal(operacao.Operador, esquerda, direita)
+	}
+	if esquerda.Type().Equal(ptrI8) {
+		return l.operacaoTexto(operacao.Operador, esquerda, direita)
+	}
+
 	switch operacao.Operador {
 	case parser.ADICAO:
 		return l.block.NewAdd(esquerda, direita)
@@ -208,12 +373,28 @@ func (l *LLVMBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interfac
 		return l.block.NewMul(esquerda, direita)
 
 	case parser.DIVISAO:
-		return l.divisaoSegura(esquerda, direita)
+		return l.divisaoSegura(esquerda, direita, operacao.Token, false)
+
+	case parser.RESTO:
+		return l.divisaoSegura(esquerda, direita, operacao.Token, true)
 
 	case parser.POWER:
 		// Implementação simples de potência usando loop
 		return l.implementarPotencia(esquerda, direita)
 
+	// Operações bit a bit
+	case parser.E_BIT:
+		return l.block.NewAnd(esquerda, direita)
+
+	case parser.OU_BIT:
+		return l.block.NewOr(esquerda, direita)
+
+	case parser.XOU_BIT:
+		return l.block.NewXor(esquerda, direita)
+
+	case parser.DESLOCAMENTO_ESQUERDA, parser.DESLOCAMENTO_DIREITA:
+		return l.deslocamentoSeguro(esquerda, direita, operacao.Operador, operacao.Token)
+
 	// Operações de comparação
 	case parser.IGUALDADE, parser.DIFERENCA, parser.MENOR_QUE, parser.MAIOR_QUE, parser.MENOR_IGUAL, parser.MAIOR_IGUAL:
 		pred := map[parser.TipoOperador]enum.IPred{
@@ -224,15 +405,110 @@ func (l *LLVMBackend) OperacaoBinaria(operacao *parser.OperacaoBinaria) interfac
 			parser.MENOR_IGUAL: enum.IPredSLE,
 			parser.MAIOR_IGUAL: enum.IPredSGE,
 		}[operacao.Operador]
-		cmp := l.block.NewICmp(pred, esquerda, direita)
-		return l.block.NewZExt(cmp, types.I64)
+		return l.block.NewICmp(pred, esquerda, direita)
+
+	default:
+		fmt.Printf("Operador não suportado: %s\n", operacao.Operador.String())
+		return l.i64(0)
+	}
+}
+
+// operacaoLogica gera 'e'/'ou' com curto-circuito: o resultado vem de um phi entre
+// o valor já decidido pelo lado esquerdo e o valor do lado direito
+func (l *LLVMBackend) operacaoLogica(operacao *parser.OperacaoBinaria) value.Value {
+	ehOu := operacao.Operador == parser.OU_LOGICO
+	esquerda := l.condicao(l.processarExpressao(operacao.OperandoEsquerdo))
+	origem := l.block
+
+	direitoBlock := l.function.NewBlock("")
+	fimBlock := l.function.NewBlock("")
+	if ehOu {
+		l.block.NewCondBr(esquerda, fimBlock, direitoBlock)
+	} else {
+		l.block.NewCondBr(esquerda, direitoBlock, fimBlock)
+	}
+
+	l.block = direitoBlock
+	direita := l.condicao(l.processarExpressao(operacao.OperandoDireito))
+	direitoFim := l.block // o lado direito pode ter criado blocos
+	l.block.NewBr(fimBlock)
 
+	l.block = fimBlock
+	return l.block.NewPhi(ir.NewIncoming(constant.NewBool(ehOu), origem), ir.NewIncoming(direita, direitoFim))
+}
+
+func (l *LLVMBackend) OperacaoUnaria(operacao *parser.OperacaoUnaria) interface{} {
+	operando := l.processarExpressao(operacao.Operando)
+	switch operacao.Operador {
+	case parser.NAO_LOGICO:
+		return l.block.NewXor(l.condicao(operando), constant.NewBool(true))
+	case parser.NEGACAO:
+		if operando.Type().Equal(types.Double) {
+			return l.block.NewFNeg(operando)
+		}
+		return l.block.NewSub(l.i64(0), operando)
+	case parser.NAO_BIT:
+		return l.block.NewXor(operando, l.i64(-1))
 	default:
 		fmt.Printf("Operador não suportado: %s\n", operacao.Operador.String())
 		return l.i64(0)
 	}
 }
 
+// operacaoDecimal gera aritmética e comparações de ponto flutuante
+func (l *LLVMBackend) operacaoDecimal(operador parser.TipoOperador, esquerda, direita value.Value) value.Value {
+	switch operador {
+	case parser.ADICAO:
+		return l.block.NewFAdd(esquerda, direita)
+	case parser.SUBTRACAO:
+		return l.block.NewFSub(esquerda, direita)
+	case parser.MULTIPLICACAO:
+		return l.block.NewFMul(esquerda, direita)
+	case parser.DIVISAO:
+		return l.block.NewFDiv(esquerda, direita)
+	case parser.RESTO:
+		return l.block.NewFRem(esquerda, direita)
+	case parser.POWER:
+		// pow da libm, como no backend x86-64; pode diferir de math.Pow (interpretador) no último dígito
+		pow := l.funcaoExterna("pow", types.Double, false, types.Double, types.Double)
+		return l.block.NewCall(pow, esquerda, direita)
+	default:
+		// Comparações ordenadas: falsas quando há NaN, exceto "diferente"
+		pred := map[parser.TipoOperador]enum.FPred{
+			parser.IGUALDADE:   enum.FPredOEQ,
+			parser.DIFERENCA:   enum.FPredUNE,
+			parser.MENOR_QUE:   enum.FPredOLT,
+			parser.MAIOR_QUE:   enum.FPredOGT,
+			parser.MENOR_IGUAL: enum.FPredOLE,
+			parser.MAIOR_IGUAL: enum.FPredOGE,
+		}[operador]
+		return l.block.NewFCmp(pred, esquerda, direita)
+	}
+}
+
+// condicao converte o valor de uma condição para i1 (inteiros: diferente de zero)
+func (l *LLVMBackend) condicao(v value.Value) value.Value {
+	if v.Type().Equal(types.I1) {
+		return v
+	}
+	return l.block.NewICmp(enum.IPredNE, v, l.i64(0))
+}
+
+// funcaoExterna declara (uma única vez) uma função da libc/libm
+func (l *LLVMBackend) funcaoExterna(nome string, retorno types.Type, variadica bool, params ...types.Type) *ir.Func {
+	if f, ok := l.externas[nome]; ok {
+		return f
+	}
+	ps := make([]*ir.Param, len(params))
+	for i, p := range params {
+		ps[i] = ir.NewParam("", p)
+	}
+	f := l.module.NewFunc(nome, retorno, ps...)
+	f.Sig.Variadic = variadica
+	l.externas[nome] = f
+	return f
+}
+
 func (l *LLVMBackend) processarFuncao(fn *parser.ChamadaFuncao) value.Value {
 	// Chamada de função de usuário
 	if uf, ok := l.userFuncs[fn.Nome]; ok {
@@ -248,11 +524,15 @@ func (l *LLVMBackend) processarFuncao(fn *parser.ChamadaFuncao) value.Value {
 	if assinatura, ok := registry.RegistroGlobal.ObterAssinatura(fn.Nome); ok {
 		switch assinatura.TipoFuncao {
 		case registry.FUNCAO_IMPRIME:
-			// Implementa função imprime diretamente
-			for _, arg := range fn.Argumentos {
+			// Implementa função imprime diretamente: argumentos na mesma linha, separados por espaço
+			for i, arg := range fn.Argumentos {
 				valor := l.processarExpressao(arg)
-				l.imprimirValor(valor)
+				if i > 0 {
+					l.printf(" ")
+				}
+				l.imprimirValor(valor, l.tipoDe(arg))
 			}
+			l.printf("\n")
 			return l.i64(0)
 
 		case registry.FUNCAO_PURA:
@@ -277,6 +557,18 @@ func (l *LLVMBackend) processarFuncao(fn *parser.ChamadaFuncao) value.Value {
 				return constant.NewInt(types.I64, 0)
 			}
 			return l.i64(int64(resultado.(int)))
+
+		case registry.FUNCAO_RUNTIME:
+			var args []value.Value
+			for _, arg := range fn.Argumentos {
+				args = append(args, l.processarExpressao(arg))
+			}
+			if tipo := l.tipoDe(fn.Argumentos[0]); tipo.EhLista() {
+				return l.chamarRotinaLista(assinatura.RotinaLista, tipo, args)
+			} else if tipo.EhMapa() {
+				return l.chamarRotinaMapa(assinatura.RotinaMapa, tipo, args)
+			}
+			return l.chamarRotinaTexto(assinatura.Rotina, args)
 		}
 	}
 
@@ -284,86 +576,227 @@ func (l *LLVMBackend) processarFuncao(fn *parser.ChamadaFuncao) value.Value {
 	return l.i64(0)
 }
 
-func (l *LLVMBackend) imprimirValor(valor value.Value) {
-	var formatStr string
-	var printValue value.Value
+func (l *LLVMBackend) imprimirValor(valor value.Value, tipo parser.Tipo) {
+	l.escreverValor(l.saidaPadrao(), valor, tipo)
+}
+
+// escreverValor escreve um valor em um FILE* (saída padrão ou texto em construção)
+// com a mesma formatação do interpretador
+func (l *LLVMBackend) escreverValor(fluxo, valor value.Value, tipo parser.Tipo) {
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
 
 	// Determina o formato baseado no tipo do valor
 	valorType := valor.Type()
 	switch {
-	case valorType == types.Double:
-		// Números decimais (double)
-		formatStr = "%g\n"
-		printValue = valor
-	case valorType.Equal(types.NewPointer(types.I8)):
+	case tipo.EhLista() && tipo.Elemento() == parser.TipoVazio:
+		// Lista literal vazia sem tipo de elemento
+		l.block.NewCall(fprintf, fluxo, l.textoConstante("[]"))
+	case tipo.EhLista():
+		l.block.NewCall(l.rotinaEscreveLista(tipo), fluxo, valor)
+	case tipo.EhMapa() && tipo.Valor() == parser.TipoVazio:
+		// Mapa literal vazio sem tipos de chave e valor
+		l.block.NewCall(fprintf, fluxo, l.textoConstante("{}"))
+	case tipo.EhMapa():
+		l.block.NewCall(l.rotinaEscreveMapa(tipo), fluxo, valor)
+	case l.declarados.EhRegistro(tipo):
+		l.block.NewCall(l.rotinaEscreveRegistro(tipo), fluxo, valor)
+	case l.declarados.EhEnumeracao(tipo):
+		l.block.NewCall(l.rotinaEscreveEnumeracao(tipo), fluxo, valor)
+	case valorType.Equal(types.Double):
+		// Números decimais: mesmo formato do interpretador (%g do Go)
+		l.block.NewCall(l.rotinaEscreveDecimal(), fluxo, valor)
+	case valorType.Equal(ptrI8):
 		// Strings (ponteiro para char)
-		formatStr = "%s\n"
-		printValue = valor
-	case valorType == types.I64:
-		// Inteiros (incluindo booleanos convertidos)
-		formatStr = "%ld\n"
-		printValue = valor
+		l.block.NewCall(fprintf, fluxo, l.textoConstante("%s"), valor)
+	case valorType.Equal(types.I1):
+		// Booleanos
+		texto := l.block.NewSelect(valor, l.textoConstante("verdadeiro"), l.textoConstante("falso"))
+		l.block.NewCall(fprintf, fluxo, l.textoConstante("%s"), texto)
 	default:
-		// Conversão padrão para inteiro
-		formatStr = "%ld\n"
-		printValue = l.block.NewSExt(valor, types.I64)
+		// Inteiros
+		l.block.NewCall(fprintf, fluxo, l.textoConstante("%ld"), valor)
 	}
+}
+
+// saidaPadrao carrega o FILE* de stdout
+func (l *LLVMBackend) saidaPadrao() value.Value {
+	if l.stdout == nil {
+		l.stdout = l.module.NewGlobal("stdout", ptrI8)
+		l.stdout.Linkage = enum.LinkageExternal
+	}
+	return l.block.NewLoad(ptrI8, l.stdout)
+}
 
-	// Reuso de globals de formato para evitar duplicações
-	formatGlobal, ok := l.fmtGlobals[formatStr]
+// TextoInterpolado monta o texto em um FILE* de memória (open_memstream): cada parte é
+// escrita com escreverValor e fclose entrega o buffer terminado em \0, alocado com malloc
+func (l *LLVMBackend) TextoInterpolado(texto *parser.TextoInterpolado) interface{} {
+	openMemstream := l.funcaoExterna("open_memstream", ptrI8, false, types.NewPointer(ptrI8), types.NewPointer(types.I64))
+	fclose := l.funcaoExterna("fclose", types.I32, false, ptrI8)
+
+	buffer := l.novaAlloca(ptrI8)
+	tamanho := l.novaAlloca(types.I64)
+	fluxo := l.block.NewCall(openMemstream, buffer, tamanho)
+	for _, parte := range texto.Partes {
+		l.escreverValor(fluxo, l.processarExpressao(parte), l.tipoDe(parte))
+	}
+	l.block.NewCall(fclose, fluxo)
+	return l.block.NewLoad(ptrI8, buffer)
+}
+
+// printf chama printf com uma string de formato global (reutilizada entre chamadas)
+func (l *LLVMBackend) printf(formato string, args ...value.Value) {
+	l.block.NewCall(l.printfFn, append([]value.Value{l.textoConstante(formato)}, args...)...)
+}
+
+// textoConstante retorna um ponteiro (expressão constante) para uma string global terminada em \0
+func (l *LLVMBackend) textoConstante(texto string) constant.Constant {
+	global, ok := l.fmtGlobals[texto]
 	if !ok {
 		l.tmpCount++
-		formatGlobal = l.module.NewGlobalDef(fmt.Sprintf("fmt%d", l.tmpCount), constant.NewCharArrayFromString(formatStr))
-		formatGlobal.Immutable = true
-		l.fmtGlobals[formatStr] = formatGlobal
+		global = l.module.NewGlobalDef(fmt.Sprintf("fmt%d", l.tmpCount), constant.NewCharArrayFromString(texto+"\x00"))
+		global.Immutable = true
+		l.fmtGlobals[texto] = global
+	}
+	return constant.NewGetElementPtr(global.ContentType, global, l.i64(0), l.i64(0))
+}
+
+// rotinaEscreveDecimal gera (uma vez) a função que escreve em um FILE* um double com a menor
+// quantidade de dígitos que o identifica, como o %g do Go: procura a menor precisão
+// cujo "%.*e" volta ao mesmo valor por strtod e usa notação científica quando o
+// expoente é < -4 ou >= 6
+func (l *LLVMBackend) rotinaEscreveDecimal() *ir.Func {
+	if l.escreveDec != nil {
+		return l.escreveDec
 	}
-	formatPtr := l.block.NewGetElementPtr(types.NewArray(uint64(len(formatStr)), types.I8), formatGlobal, l.i64(0), l.i64(0))
-	l.block.NewCall(l.printfFn, formatPtr, printValue)
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+	snprintf := l.funcaoExterna("snprintf", types.I32, true, ptrI8, types.I64, ptrI8)
+	strtod := l.funcaoExterna("strtod", types.Double, false, ptrI8, types.NewPointer(ptrI8))
+	strchr := l.funcaoExterna("strchr", ptrI8, false, ptrI8, types.I32)
+	atoi := l.funcaoExterna("atoi", types.I32, false, ptrI8)
+
+	fluxo := ir.NewParam("fluxo", ptrI8)
+	v := ir.NewParam("v", types.Double)
+	f := l.module.NewFunc("solar_escreve_decimal", types.Void, fluxo, v)
+	l.escreveDec = f
+
+	// Gera o corpo com os helpers do backend, restaurando o ponto de inserção
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada := f.NewBlock("entry")
+	naoNaN := f.NewBlock("nao_nan")
+	naoInf := f.NewBlock("nao_inf")
+	zero := f.NewBlock("zero")
+	busca := f.NewBlock("busca")
+	proxima := f.NewBlock("proxima")
+	achou := f.NewBlock("achou")
+	cientifico := f.NewBlock("cientifico")
+	fixo := f.NewBlock("fixo")
+	especial := f.NewBlock("especial")
+
+	l.block = entrada
+	buf := entrada.NewAlloca(types.NewArray(32, types.I8))
+	precisao := entrada.NewAlloca(types.I32)
+	bufPtr := entrada.NewGetElementPtr(buf.ElemType, buf, l.i64(0), l.i64(0))
+	entrada.NewCondBr(entrada.NewFCmp(enum.FPredUNO, v, v), especial, naoNaN)
+
+	// NaN, +Inf e -Inf
+	especial.NewCall(fprintf, fluxo, l.textoConstante("NaN"))
+	especial.NewRet(nil)
+	infinito := f.NewBlock("infinito")
+	naoNaN.NewCondBr(naoNaN.NewFCmp(enum.FPredOEQ, naoNaN.NewCall(l.funcaoExterna("fabs", types.Double, false, types.Double), v),
+		constant.NewFloat(types.Double, math.Inf(1))), infinito, naoInf)
+	positivo := infinito.NewFCmp(enum.FPredOGT, v, constant.NewFloat(types.Double, 0))
+	infinito.NewCall(fprintf, fluxo, infinito.NewSelect(positivo, l.textoConstante("+Inf"), l.textoConstante("-Inf")))
+	infinito.NewRet(nil)
+
+	// Zero (com sinal)
+	naoInf.NewCondBr(naoInf.NewFCmp(enum.FPredOEQ, v, constant.NewFloat(types.Double, 0)), zero, busca)
+	negativo := zero.NewICmp(enum.IPredSLT, zero.NewBitCast(v, types.I64), l.i64(0))
+	zero.NewCall(fprintf, fluxo, zero.NewSelect(negativo, l.textoConstante("-0"), l.textoConstante("0")))
+	zero.NewRet(nil)
+
+	// Menor precisão (1 a 17 dígitos) que identifica o valor
+	busca.NewStore(constant.NewInt(types.I32, 1), precisao)
+	loop := f.NewBlock("loop")
+	busca.NewBr(loop)
+	p := loop.NewLoad(types.I32, precisao)
+	loop.NewCall(snprintf, bufPtr, l.i64(32), l.textoConstante("%.*e"), loop.NewSub(p, constant.NewInt(types.I32, 1)), v)
+	lido := loop.NewCall(strtod, bufPtr, constant.NewNull(types.NewPointer(ptrI8)))
+	exato := loop.NewFCmp(enum.FPredOEQ, lido, v)
+	limite := loop.NewICmp(enum.IPredSGE, p, constant.NewInt(types.I32, 17))
+	loop.NewCondBr(loop.NewOr(exato, limite), achou, proxima)
+	proxima.NewStore(proxima.NewAdd(p, constant.NewInt(types.I32, 1)), precisao)
+	proxima.NewBr(loop)
+
+	// Expoente decimal do resultado de "%.*e"
+	e := achou.NewCall(strchr, bufPtr, constant.NewInt(types.I32, 'e'))
+	expoente := achou.NewCall(atoi, achou.NewGetElementPtr(types.I8, e, l.i64(1)))
+	pequeno := achou.NewICmp(enum.IPredSLT, expoente, constant.NewInt(types.I32, -4))
+	grande := achou.NewICmp(enum.IPredSGE, expoente, constant.NewInt(types.I32, 6))
+	achou.NewCondBr(achou.NewOr(pequeno, grande), cientifico, fixo)
+
+	// "%.*e" já tem o formato do Go (d.ddde+XX)
+	cientifico.NewCall(fprintf, fluxo, l.textoConstante("%s"), bufPtr)
+	cientifico.NewRet(nil)
+
+	// Notação fixa com max(p-1-expoente, 0) casas decimais
+	diferenca := fixo.NewSub(fixo.NewSub(p, constant.NewInt(types.I32, 1)), expoente)
+	casas := fixo.NewSelect(fixo.NewICmp(enum.IPredSLT, diferenca, constant.NewInt(types.I32, 0)), constant.NewInt(types.I32, 0), diferenca)
+	fixo.NewCall(fprintf, fluxo, l.textoConstante("%.*f"), casas, v)
+	fixo.NewRet(nil)
+
+	return f
 }
 
-// processarComandoSe processa comandos if/else
+// processarComandoSe processa comandos if/else; cada ramo 'senao se' testa sua
+// condição no bloco de falha do anterior e todos seguem para um único bloco final
 func (l *LLVMBackend) processarComandoSe(comando *parser.ComandoSe) value.Value {
-	// Avalia a condição
-	condicao := l.processarExpressao(comando.Condicao)
+	// O bloco final entra na função depois dos ramos
+	mergeBlock := ir.NewBlock("")
+
+	condicoes := []parser.Expressao{comando.Condicao}
+	blocos := []*parser.Bloco{comando.BlocoSe}
+	for _, ramo := range comando.SenaoSe {
+		condicoes = append(condicoes, ramo.Condicao)
+		blocos = append(blocos, ramo.Bloco)
+	}
 
-	// Converte para i1 (boolean)
-	cond := l.block.NewICmp(enum.IPredNE, condicao, l.i64(0))
+	for i, condicao := range condicoes {
+		// Avalia a condição
+		cond := l.condicao(l.processarExpressao(condicao))
 
-	// Cria blocos
-	thenBlock := l.function.NewBlock("")
-	var elseBlock *ir.Block
-	mergeBlock := l.function.NewBlock("")
+		// Sem próximo ramo nem senao, a falha vai direto para o fim
+		thenBlock := l.function.NewBlock("")
+		falhaBlock := mergeBlock
+		if i < len(condicoes)-1 || comando.BlocoSenao != nil {
+			falhaBlock = l.function.NewBlock("")
+		}
+		l.block.NewCondBr(cond, thenBlock, falhaBlock)
 
-	if comando.BlocoSenao != nil {
-		elseBlock = l.function.NewBlock("")
-		l.block.NewCondBr(cond, thenBlock, elseBlock)
-	} else {
-		l.block.NewCondBr(cond, thenBlock, mergeBlock)
+		// Processa o bloco do ramo (um 'retornar' já terminou o bloco)
+		l.block = thenBlock
+		l.processarBloco(blocos[i])
+		if l.block.Term == nil {
+			l.block.NewBr(mergeBlock)
+		}
+		l.block = falhaBlock
 	}
 
-	// Processa bloco "se"
-	l.block = thenBlock
-	thenValue := l.processarBloco(comando.BlocoSe)
-	l.block.NewBr(mergeBlock)
-
-	var elseValue value.Value
 	if comando.BlocoSenao != nil {
 		// Processa bloco "senao"
-		l.block = elseBlock
-		elseValue = l.processarBloco(comando.BlocoSenao)
-		l.block.NewBr(mergeBlock)
-	} else {
-		elseValue = l.i64(0)
+		l.processarBloco(comando.BlocoSenao)
+		if l.block.Term == nil {
+			l.block.NewBr(mergeBlock)
+		}
 	}
 
 	// Merge block
+	mergeBlock.Parent = l.function
+	l.function.Blocks = append(l.function.Blocks, mergeBlock)
 	l.block = mergeBlock
-	if comando.BlocoSenao != nil {
-		phi := mergeBlock.NewPhi(ir.NewIncoming(thenValue, thenBlock), ir.NewIncoming(elseValue, elseBlock))
-		return phi
-	}
-
-	return thenValue
+	return nil
 }
 
 // processarBloco processa um bloco de comandos
@@ -389,22 +822,22 @@ func (l *LLVMBackend) processarBloco(bloco *parser.Bloco) value.Value {
 }
 
 func (l *LLVMBackend) processarEnquanto(cmd *parser.ComandoEnquanto) value.Value {
-	funcBlock := l.function
 	// Cria blocos
-	condBlock := funcBlock.NewBlock("while.cond")
-	bodyBlock := funcBlock.NewBlock("while.body")
-	endBlock := funcBlock.NewBlock("while.end")
+	condBlock := l.novoBloco("while.cond")
+	bodyBlock := l.novoBloco("while.body")
+	endBlock := l.novoBloco("while.end")
 
 	// Branch para condição
 	l.block.NewBr(condBlock)
 	l.block = condBlock
-	condVal := l.processarExpressao(cmd.Condicao)
-	condI1 := l.block.NewICmp(enum.IPredNE, condVal, l.i64(0))
+	condI1 := l.condicao(l.processarExpressao(cmd.Condicao))
 	l.block.NewCondBr(condI1, bodyBlock, endBlock)
 
-	// Corpo
+	// Corpo ('continuar' volta para a condição)
 	l.block = bodyBlock
+	l.lacos = append(l.lacos, destinoLaco{rotulo: cmd.Rotulo, parar: endBlock, continuar: condBlock})
 	last := l.processarBloco(cmd.Corpo)
+	l.lacos = l.lacos[:len(l.lacos)-1]
 	// Se corpo não retornou, volta para cond
 	if l.block.Term == nil {
 		l.block.NewBr(condBlock)
@@ -415,32 +848,36 @@ func (l *LLVMBackend) processarEnquanto(cmd *parser.ComandoEnquanto) value.Value
 }
 
 func (l *LLVMBackend) processarPara(cmd *parser.ComandoPara) value.Value {
-	funcBlock := l.function
+	// A variável de controle vive em um escopo que envolve o laço
+	l.pushScope()
+	defer l.popScope()
+
 	// init
 	if cmd.Inicializacao != nil {
 		l.processarExpressao(cmd.Inicializacao)
 	}
 	// Blocos
-	condBlock := funcBlock.NewBlock("for.cond")
-	bodyBlock := funcBlock.NewBlock("for.body")
-	stepBlock := funcBlock.NewBlock("for.step")
-	endBlock := funcBlock.NewBlock("for.end")
+	condBlock := l.novoBloco("for.cond")
+	bodyBlock := l.novoBloco("for.body")
+	stepBlock := l.novoBloco("for.step")
+	endBlock := l.novoBloco("for.end")
 
 	l.block.NewBr(condBlock)
 	l.block = condBlock
 	// condição (vazia => true)
 	var condI1 value.Value
 	if cmd.Condicao != nil {
-		condVal := l.processarExpressao(cmd.Condicao)
-		condI1 = l.block.NewICmp(enum.IPredNE, condVal, l.i64(0))
+		condI1 = l.condicao(l.processarExpressao(cmd.Condicao))
 	} else {
 		condI1 = constant.NewInt(types.I1, 1)
 	}
 	l.block.NewCondBr(condI1, bodyBlock, endBlock)
 
-	// body
+	// body ('continuar' segue para o passo)
 	l.block = bodyBlock
+	l.lacos = append(l.lacos, destinoLaco{rotulo: cmd.Rotulo, parar: endBlock, continuar: stepBlock})
 	last := l.processarBloco(cmd.Corpo)
+	l.lacos = l.lacos[:len(l.lacos)-1]
 	if l.block.Term == nil {
 		l.block.NewBr(stepBlock)
 	}
@@ -458,15 +895,157 @@ func (l *LLVMBackend) processarPara(cmd *parser.ComandoPara) value.Value {
 	return last
 }
 
+// processarParaCada percorre a lista com um índice escondido; o tamanho é lido uma
+// única vez e os dados a cada iteração (anexar pode realocá-los). Um mapa é percorrido
+// por cópias das suas chaves e valores feitas no início do laço
+func (l *LLVMBackend) processarParaCada(cmd *parser.ComandoParaCada) value.Value {
+	tipoColecao := l.tipoDe(cmd.Colecao)
+	colecao := l.processarExpressao(cmd.Colecao)
+	listas := []value.Value{colecao}
+	elementos := []parser.Tipo{tipoColecao.Elemento()}
+	variaveis := []string{cmd.Variavel}
+	if tipoColecao.EhMapa() {
+		listas[0] = l.block.NewCall(l.rotinaMapaLista(campoMapaChaves), colecao)
+		elementos[0] = tipoColecao.Chave()
+		if cmd.Valor != "" {
+			listas = append(listas, l.block.NewCall(l.rotinaMapaLista(campoMapaValores), colecao))
+			elementos = append(elementos, tipoColecao.Valor())
+			variaveis = append(variaveis, cmd.Valor)
+		}
+	}
+	tamanho := l.block.NewLoad(types.I64, l.campo(listas[0], campoTamanho))
+	indice := l.novaAlloca(types.I64)
+	l.block.NewStore(l.i64(0), indice)
+
+	condBlock := l.novoBloco("foreach.cond")
+	bodyBlock := l.novoBloco("foreach.body")
+	stepBlock := l.novoBloco("foreach.step")
+	endBlock := l.novoBloco("foreach.end")
+
+	l.block.NewBr(condBlock)
+	l.block = condBlock
+	i := l.block.NewLoad(types.I64, indice)
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredSLT, i, tamanho), bodyBlock, endBlock)
+
+	l.block = bodyBlock
+	valores := make([]value.Value, len(listas))
+	for j, lista := range listas {
+		tipo := l.tipoLLVM(elementos[j])
+		dados := l.block.NewBitCast(l.block.NewLoad(ptrI8, l.campo(lista, campoDados)), types.NewPointer(tipo))
+		valores[j] = l.block.NewLoad(tipo, l.block.NewGetElementPtr(tipo, dados, i))
+	}
+	last := l.processarCorpoIteracao(variaveis, valores, elementos, cmd.Rotulo, cmd.Corpo, endBlock, stepBlock)
+
+	l.block = stepBlock
+	l.block.NewStore(l.block.NewAdd(i, l.i64(1)), indice)
+	l.block.NewBr(condBlock)
+
+	l.block = endBlock
+	return last
+}
+
+// processarParaIntervalo avalia início, fim e passo uma única vez e conta com um
+// contador escondido; o sentido da comparação com o fim segue o sinal do passo
+func (l *LLVMBackend) processarParaIntervalo(cmd *parser.ComandoParaIntervalo) value.Value {
+	inicio := l.processarExpressao(cmd.Inicio)
+	fim := l.processarExpressao(cmd.Fim)
+	passo := value.Value(l.i64(1))
+	if cmd.Passo != nil {
+		passo = l.processarExpressao(cmd.Passo)
+		zero := l.novoBloco("for.passo.zero")
+		valido := l.novoBloco("for.passo.ok")
+		l.block.NewCondBr(l.block.NewICmp(enum.IPredEQ, passo, l.i64(0)), zero, valido)
+		l.block = zero
+		l.erroExecucao("passo do laço 'para' não pode ser zero", cmd.Token)
+		l.block = valido
+	}
+	contador := l.novaAlloca(types.I64)
+	l.block.NewStore(inicio, contador)
+
+	condBlock := l.novoBloco("for.cond")
+	bodyBlock := l.novoBloco("for.body")
+	stepBlock := l.novoBloco("for.step")
+	endBlock := l.novoBloco("for.end")
+
+	l.block.NewBr(condBlock)
+	l.block = condBlock
+	atual := l.block.NewLoad(types.I64, contador)
+	crescente, decrescente := enum.IPredSLT, enum.IPredSGT
+	if cmd.Inclusivo {
+		crescente, decrescente = enum.IPredSLE, enum.IPredSGE
+	}
+	continua := l.block.NewSelect(l.block.NewICmp(enum.IPredSGT, passo, l.i64(0)),
+		l.block.NewICmp(crescente, atual, fim), l.block.NewICmp(decrescente, atual, fim))
+	l.block.NewCondBr(continua, bodyBlock, endBlock)
+
+	l.block = bodyBlock
+	last := l.processarCorpoIteracao([]string{cmd.Variavel}, []value.Value{atual}, []parser.Tipo{parser.TipoInteiro}, cmd.Rotulo, cmd.Corpo, endBlock, stepBlock)
+
+	// Se a soma transbordar, o próximo valor passaria do fim: o laço acaba
+	l.block = stepBlock
+	proximo := l.block.NewAdd(atual, passo)
+	l.block.NewStore(proximo, contador)
+	transbordou := l.block.NewSelect(l.block.NewICmp(enum.IPredSGT, passo, l.i64(0)),
+		l.block.NewICmp(enum.IPredSLT, proximo, atual), l.block.NewICmp(enum.IPredSGT, proximo, atual))
+	l.block.NewCondBr(transbordou, endBlock, condBlock)
+
+	l.block = endBlock
+	return last
+}
+
+// processarCorpoIteracao gera o corpo de um laço de iteração com as variáveis do laço
+// (cópias dos valores da iteração) em um escopo só dele; 'continuar' segue para o passo
+func (l *LLVMBackend) processarCorpoIteracao(variaveis []string, valores []value.Value, tipos []parser.Tipo, rotulo string, corpo *parser.Bloco, parar, continuar *ir.Block) value.Value {
+	l.pushScope()
+	defer l.popScope()
+	for i, variavel := range variaveis {
+		l.declararLocal(variavel, valores[i], tipos[i])
+	}
+
+	l.lacos = append(l.lacos, destinoLaco{rotulo: rotulo, parar: parar, continuar: continuar})
+	last := l.processarBloco(corpo)
+	l.lacos = l.lacos[:len(l.lacos)-1]
+	if l.block.Term == nil {
+		l.block.NewBr(continuar)
+	}
+	return last
+}
+
+// novoBloco cria um bloco com nome único na função atual (ex: while.cond.3)
+func (l *LLVMBackend) novoBloco(nome string) *ir.Block {
+	l.blocoCount++
+	return l.function.NewBlock(fmt.Sprintf("%s.%d", nome, l.blocoCount))
+}
+
+// destino retorna o laço alvo de 'parar'/'continuar' (sem rótulo: o mais interno)
+func (l *LLVMBackend) destino(rotulo string) destinoLaco {
+	for i := len(l.lacos) - 1; i >= 0; i-- {
+		if rotulo == "" || l.lacos[i].rotulo == rotulo {
+			return l.lacos[i]
+		}
+	}
+	return destinoLaco{} // inalcançável: o TypeChecker valida os rótulos
+}
+
+// Parar e Continuar terminam o bloco atual; processarBloco ignora o que vem depois
+func (l *LLVMBackend) Parar(parar *parser.Parar) interface{} {
+	l.block.NewBr(l.destino(parar.Rotulo).parar)
+	return nil
+}
+
+func (l *LLVMBackend) Continuar(continuar *parser.Continuar) interface{} {
+	l.block.NewBr(l.destino(continuar.Rotulo).continuar)
+	return nil
+}
+
 // Suporte a funções do usuário
 func (l *LLVMBackend) declararFuncaoUsuario(fn *parser.FuncaoDeclaracao) {
-	// Assinaturas somente de inteiros i64
-	// TODO: tipos variados
+	// Assinatura com os tipos declarados dos parâmetros e do retorno
 	params := make([]*ir.Param, len(fn.Parametros))
 	for i, p := range fn.Parametros {
-		params[i] = ir.NewParam(p.Nome, types.I64)
+		params[i] = ir.NewParam(p.Nome, l.tipoLLVM(p.Tipo))
 	}
-	f := l.module.NewFunc(fn.Nome, types.I64, params...)
+	f := l.module.NewFunc(fn.Nome, l.tipoLLVM(fn.Retorno), params...)
 	l.userFuncs[fn.Nome] = f
 }
 
@@ -483,16 +1062,25 @@ func (l *LLVMBackend) definirFuncaoUsuario(fn *parser.FuncaoDeclaracao) {
 	entry := f.NewBlock("entry")
 	l.block = entry
 
-	// Novo escopo e bind de parâmetros
+	// Novo escopo e bind de parâmetros (em allocas, para que possam ser reatribuídos)
 	l.pushScope()
-	for _, p := range f.Params {
-		l.variables[p.Name()] = p
+	for i, p := range f.Params {
+		l.declararLocal(p.Name(), p, fn.Parametros[i].Tipo)
 	}
 
 	// Processa corpo: retorno implícito = última expressão
 	result := l.processarBloco(fn.Corpo)
 	if l.block.Term == nil {
-		l.block.NewRet(result)
+		retorno := f.Sig.RetType
+		switch {
+		case retorno.Equal(types.Void):
+			l.block.NewRet(nil)
+		case result != nil && result.Type().Equal(retorno):
+			l.block.NewRet(l.valorParaGuardar(result, fn.Retorno))
+		default:
+			// Todos os caminhos já retornaram (bloco inalcançável)
+			l.block.NewRet(valorZero(retorno))
+		}
 	}
 	l.popScope()
 
@@ -553,6 +1141,14 @@ func (l *LLVMBackend) ComandoPara(cmd *parser.ComandoPara) interface{} {
 	return l.processarPara(cmd)
 }
 
+func (l *LLVMBackend) ComandoParaCada(cmd *parser.ComandoParaCada) interface{} {
+	return l.processarParaCada(cmd)
+}
+
+func (l *LLVMBackend) ComandoParaIntervalo(cmd *parser.ComandoParaIntervalo) interface{} {
+	return l.processarParaIntervalo(cmd)
+}
+
 func (l *LLVMBackend) Bloco(bloco *parser.Bloco) interface{} {
 	return l.processarBloco(bloco)
 }
@@ -564,16 +1160,13 @@ func (l *LLVMBackend) FuncaoDeclaracao(fn *parser.FuncaoDeclaracao) interface{}
 
 func (l *LLVMBackend) Retorno(ret *parser.Retorno) interface{} {
 	if ret.Valor != nil {
-		v := l.processarExpressaoValue(ret.Valor)
-		if l.function != nil {
-			l.block.NewRet(v)
-		}
+		v := l.valorParaGuardar(l.processarExpressaoValue(ret.Valor), l.tipoDe(ret.Valor))
+		l.block.NewRet(v)
 		return v
 	}
-	if l.function != nil {
-		l.block.NewRet(l.i64(0))
-	}
-	return l.i64(0)
+	// Retorno vazio: função void
+	l.block.NewRet(nil)
+	return nil
 }
 
 // Suporte a importação
@@ -584,12 +1177,12 @@ func (l *LLVMBackend) Importacao(imp *parser.Importacao) interface{} {
 
 // Implementa potência usando loop iterativo
 func (l *LLVMBackend) implementarPotencia(base, exp value.Value) value.Value {
-	chk := l.function.NewBlock("pow_chk")
-	loop := l.function.NewBlock("pow_loop")
-	end := l.function.NewBlock("pow_end")
-	resAlloca := l.block.NewAlloca(types.I64)
-	expAlloca := l.block.NewAlloca(types.I64)
-	baseAlloca := l.block.NewAlloca(types.I64)
+	chk := l.function.NewBlock("")
+	loop := l.function.NewBlock("")
+	end := l.function.NewBlock("")
+	resAlloca := l.novaAlloca(types.I64)
+	expAlloca := l.novaAlloca(types.I64)
+	baseAlloca := l.novaAlloca(types.I64)
 	l.block.NewStore(l.i64(1), resAlloca)
 	l.block.NewStore(exp, expAlloca)
 	l.block.NewStore(base, baseAlloca)
@@ -604,8 +1197,8 @@ func (l *LLVMBackend) implementarPotencia(base, exp value.Value) value.Value {
 	curExp2 := l.block.NewLoad(types.I64, expAlloca)
 	one := l.i64(1)
 	isOdd := l.block.NewICmp(enum.IPredNE, l.block.NewAnd(curExp2, one), l.i64(0))
-	mulBlock := l.function.NewBlock("pow_mul")
-	cont := l.function.NewBlock("pow_cont")
+	mulBlock := l.function.NewBlock("")
+	cont := l.function.NewBlock("")
 	l.block.NewCondBr(isOdd, mulBlock, cont)
 	// mul path
 	l.block = mulBlock
@@ -620,9 +1213,14 @@ func (l *LLVMBackend) implementarPotencia(base, exp value.Value) value.Value {
 	curExp3 := l.block.NewLoad(types.I64, expAlloca)
 	l.block.NewStore(l.block.NewAShr(curExp3, one), expAlloca) // shift right aritmético
 	l.block.NewBr(chk)
-	// end
+	// end: com expoente negativo 1/base^n trunca para 0, exceto para as bases 1 e -1
 	l.block = end
-	return l.block.NewLoad(types.I64, resAlloca)
+	resultado := l.block.NewLoad(types.I64, resAlloca)
+	impar := l.block.NewICmp(enum.IPredNE, l.block.NewAnd(exp, one), l.i64(0))
+	menosUm := l.block.NewSelect(impar, l.i64(-1), l.i64(1))
+	negativo := l.block.NewSelect(l.block.NewICmp(enum.IPredEQ, base, one), one,
+		l.block.NewSelect(l.block.NewICmp(enum.IPredEQ, base, l.i64(-1)), menosUm, l.i64(0)))
+	return l.block.NewSelect(l.block.NewICmp(enum.IPredSLT, exp, l.i64(0)), negativo, resultado)
 }
 
 // Tenta compilar o LLVM IR para um executável usando clang
@@ -664,21 +1262,78 @@ func (l *LLVMBackend) getNextStringName() string {
 // i64 cria constante inteira de 64 bits.
 func (l *LLVMBackend) i64(v int64) *constant.Int { return constant.NewInt(types.I64, v) }
 
-// divisaoSegura gera código de divisão com proteção contra divisor zero (retorna 0 se divisor==0).
-func (l *LLVMBackend) divisaoSegura(a, b value.Value) value.Value {
-	zero := l.i64(0)
-	cond := l.block.NewICmp(enum.IPredEQ, b, zero)
-	divZero := l.function.NewBlock("div_zero")
-	divOk := l.function.NewBlock("div_ok")
-	merge := l.function.NewBlock("div_merge")
-	l.block.NewCondBr(cond, divZero, divOk)
-	// zero path
-	divZero.NewBr(merge)
-	// ok path
+// divisaoSegura divide inteiros (quociente truncado ou resto com o sinal do dividendo)
+// encerrando o programa com a posição do operador quando o divisor é zero; com divisor
+// -1 o quociente é 0 - x e o resto é 0 (sdiv/srem do menor inteiro por -1 são indefinidos)
+func (l *LLVMBackend) divisaoSegura(a, b value.Value, token lexer.Token, resto bool) value.Value {
+	divZero := l.function.NewBlock("")
+	divOk := l.function.NewBlock("")
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredEQ, b, l.i64(0)), divZero, divOk)
+
+	// zero: erro de execução
+	l.block = divZero
+	l.erroExecucao("divisão por zero", token)
+
 	l.block = divOk
-	divRes := l.block.NewSDiv(a, b)
-	l.block.NewBr(merge)
-	// merge
-	l.block = merge
-	return merge.NewPhi(ir.NewIncoming(zero, divZero), ir.NewIncoming(divRes, divOk))
+	menosUm := l.block.NewICmp(enum.IPredEQ, b, l.i64(-1))
+	divisor := l.block.NewSelect(menosUm, l.i64(1), b)
+	if resto {
+		return l.block.NewSelect(menosUm, l.i64(0), l.block.NewSRem(a, divisor))
+	}
+	quociente := l.block.NewSDiv(a, divisor)
+	return l.block.NewSelect(menosUm, l.block.NewSub(l.i64(0), a), quociente)
+}
+
+// deslocamentoSeguro gera '<<' e '>>' com contagem negativa como erro de execução; contagens
+// de 64 ou mais (veneno em shl/ashr) resultam em 0 para '<<' e no bit de sinal para '>>'
+func (l *LLVMBackend) deslocamentoSeguro(a, b value.Value, operador parser.TipoOperador, token lexer.Token) value.Value {
+	negativo := l.function.NewBlock("")
+	ok := l.function.NewBlock("")
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredSLT, b, l.i64(0)), negativo, ok)
+
+	l.block = negativo
+	l.erroExecucao("deslocamento negativo", token)
+
+	l.block = ok
+	cabe := l.block.NewICmp(enum.IPredULT, b, l.i64(64))
+	contagem := l.block.NewSelect(cabe, b, l.i64(63))
+	if operador == parser.DESLOCAMENTO_DIREITA {
+		return l.block.NewAShr(a, contagem)
+	}
+	return l.block.NewSelect(cabe, l.block.NewShl(a, contagem), l.i64(0))
+}
+
+// erroExecucao termina o bloco atual com a chamada da rotina de erro de execução
+func (l *LLVMBackend) erroExecucao(mensagem string, token lexer.Token) {
+	l.block.NewCall(l.rotinaErroExecucao(), l.textoConstante(mensagem),
+		l.i64(int64(token.Position.Line)), l.i64(int64(token.Position.Column)))
+	l.block.NewUnreachable()
+}
+
+// rotinaErroExecucao gera (uma vez) a função que escreve
+// "Erro de execução: <mensagem> em linha L, coluna C" em stderr e encerra com código 1
+func (l *LLVMBackend) rotinaErroExecucao() *ir.Func {
+	if l.erroExec != nil {
+		return l.erroExec
+	}
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+	fflush := l.funcaoExterna("fflush", types.I32, false, ptrI8)
+	exit := l.funcaoExterna("exit", types.Void, false, types.I32)
+	stderr := l.module.NewGlobal("stderr", ptrI8)
+	stderr.Linkage = enum.LinkageExternal
+
+	mensagem := ir.NewParam("mensagem", ptrI8)
+	linha := ir.NewParam("linha", types.I64)
+	coluna := ir.NewParam("coluna", types.I64)
+	f := l.module.NewFunc("solar_erro_execucao", types.Void, mensagem, linha, coluna)
+	l.erroExec = f
+
+	entrada := f.NewBlock("entry")
+	// Saída já impressa aparece antes da mensagem de erro
+	entrada.NewCall(fflush, constant.NewNull(ptrI8))
+	entrada.NewCall(fprintf, entrada.NewLoad(ptrI8, stderr),
+		l.textoConstante("Erro de execução: %s em linha %ld, coluna %ld\n"), mensagem, linha, coluna)
+	entrada.NewCall(exit, constant.NewInt(types.I32, 1))
+	entrada.NewUnreachable()
+	return f
 }
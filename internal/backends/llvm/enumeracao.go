@@ -0,0 +1,152 @@
+package llvm
+
+import (
+	"fmt"
+
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+
+	"github.com/khevencolino/Solar/internal/parser"
+)
+
+// Enumerações são i64: cada variante vale a sua posição na declaração. Uma escolha sobre
+// enumeração ou inteiro vira um switch (que o LLVM transforma em tabela de saltos quando
+// os valores são densos); sobre texto, uma cadeia de comparações com strcmp
+
+// EnumeracaoDeclaracao não gera código: as variantes são constantes usadas pelo nome
+func (l *LLVMBackend) EnumeracaoDeclaracao(declaracao *parser.EnumeracaoDeclaracao) interface{} {
+	return nil
+}
+
+// varianteEnumeracao retorna a constante de uma variante usada pelo nome (ex: Vermelho)
+func (l *LLVMBackend) varianteEnumeracao(variavel *parser.Variavel) (value.Value, bool) {
+	tipo := l.tipoDe(variavel)
+	if !l.declarados.EhEnumeracao(tipo) {
+		return nil, false
+	}
+	indice, ok := l.declarados.Variante(tipo, variavel.Nome)
+	return l.i64(int64(indice)), ok
+}
+
+// Escolha gera os casos em blocos próprios; o valor do caso executado (quando a escolha
+// produz um valor) é guardado em uma alloca lida no bloco final
+func (l *LLVMBackend) Escolha(escolha *parser.Escolha) interface{} {
+	tipoValor := l.tipoDe(escolha.Valor)
+	valor := l.processarExpressaoValue(escolha.Valor)
+
+	var resultado *ir.InstAlloca
+	if tipo := l.tipoDe(escolha); tipo != parser.TipoVazio {
+		resultado = l.novaAlloca(l.tipoLLVM(tipo))
+	}
+
+	// O bloco final entra na função depois dos casos
+	l.blocoCount++
+	fim := ir.NewBlock(fmt.Sprintf("escolha.fim.%d", l.blocoCount))
+	corpos := make([]*ir.Block, len(escolha.Casos))
+	for i := range escolha.Casos {
+		corpos[i] = l.novoBloco("escolha.caso")
+	}
+
+	if tipoValor == parser.TipoTexto {
+		l.compararCasosTexto(escolha, valor, corpos, fim)
+	} else {
+		l.saltarCasos(escolha, valor, corpos, fim)
+	}
+
+	for i, caso := range escolha.Casos {
+		l.block = corpos[i]
+		var corpo value.Value
+		if bloco, ok := caso.Corpo.(*parser.Bloco); ok {
+			corpo = l.processarBloco(bloco)
+		} else {
+			corpo = l.processarExpressaoValue(caso.Corpo)
+		}
+		// Um 'retornar', 'parar' ou 'continuar' já terminou o bloco
+		if l.block.Term != nil {
+			continue
+		}
+		if resultado != nil {
+			l.block.NewStore(corpo, resultado)
+		}
+		l.block.NewBr(fim)
+	}
+
+	fim.Parent = l.function
+	l.function.Blocks = append(l.function.Blocks, fim)
+	l.block = fim
+	if resultado == nil {
+		return nil
+	}
+	return l.block.NewLoad(resultado.ElemType, resultado)
+}
+
+// saltarCasos gera o switch de uma escolha sobre enumeração ou inteiro; sem 'caso _',
+// os valores não cobertos vão direto para o fim
+func (l *LLVMBackend) saltarCasos(escolha *parser.Escolha, valor value.Value, corpos []*ir.Block, fim *ir.Block) {
+	padrao := fim
+	var casos []*ir.Case
+	for i, caso := range escolha.Casos {
+		if caso.Padrao == nil {
+			padrao = corpos[i]
+			break
+		}
+		casos = append(casos, ir.NewCase(l.constanteCaso(caso.Padrao), corpos[i]))
+	}
+	l.block.NewSwitch(valor, padrao, casos...)
+}
+
+// constanteCaso retorna o valor de um padrão de enumeração ou inteiro
+func (l *LLVMBackend) constanteCaso(padrao parser.Expressao) *constant.Int {
+	switch p := padrao.(type) {
+	case *parser.Variavel:
+		indice, _ := l.declarados.Variante(l.tipoDe(p), p.Nome)
+		return l.i64(int64(indice))
+	case *parser.Constante:
+		return l.i64(int64(p.Valor))
+	}
+	return l.i64(0)
+}
+
+// compararCasosTexto gera a cadeia de comparações de uma escolha sobre texto, testando
+// os casos na ordem em que aparecem
+func (l *LLVMBackend) compararCasosTexto(escolha *parser.Escolha, valor value.Value, corpos []*ir.Block, fim *ir.Block) {
+	for i, caso := range escolha.Casos {
+		if caso.Padrao == nil {
+			l.block.NewBr(corpos[i])
+			return
+		}
+		igual := l.operacaoTexto(parser.IGUALDADE, valor, l.processarExpressaoValue(caso.Padrao))
+		proximo := fim
+		if i < len(escolha.Casos)-1 {
+			proximo = l.novoBloco("escolha.teste")
+		}
+		l.block.NewCondBr(igual, corpos[i], proximo)
+		l.block = proximo
+	}
+}
+
+// rotinaEscreveEnumeracao gera (uma por enumeração) a função que escreve o nome da
+// variante em um FILE*, como o interpretador
+func (l *LLVMBackend) rotinaEscreveEnumeracao(tipo parser.Tipo) *ir.Func {
+	fluxo, variante := ir.NewParam("fluxo", ptrI8), ir.NewParam("variante", types.I64)
+	f, nova := l.novaRotina("escreve_"+tipo.String(), types.Void, fluxo, variante)
+	if !nova {
+		return f
+	}
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+
+	entrada := f.NewBlock("entry")
+	fim := f.NewBlock("fim")
+	fim.NewRet(nil)
+	casos := make([]*ir.Case, len(l.declarados.Variantes(tipo)))
+	for i, nome := range l.declarados.Variantes(tipo) {
+		bloco := f.NewBlock("")
+		bloco.NewCall(fprintf, fluxo, l.textoConstante(nome))
+		bloco.NewBr(fim)
+		casos[i] = ir.NewCase(l.i64(int64(i)), bloco)
+	}
+	entrada.NewSwitch(variante, fim, casos...)
+	return f
+}
@@ -0,0 +1,194 @@
+package llvm
+
+import (
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/lexer"
+	"github.com/khevencolino/Solar/internal/parser"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// tipoLista é o cabeçalho das listas: tamanho, capacidade e os dados (8 bytes por
+// elemento, qualquer que seja o tipo). Uma lista é um ponteiro para o cabeçalho, então
+// atribuições e argumentos compartilham a mesma lista, como no interpretador
+var tipoLista = types.NewStruct(types.I64, types.I64, ptrI8)
+
+// ptrLista é o tipo LLVM de todas as listas
+var ptrLista = types.NewPointer(tipoLista)
+
+// Campos do cabeçalho
+const (
+	campoTamanho    = 0
+	campoCapacidade = 1
+	campoDados      = 2
+)
+
+// ListaLiteral aloca o cabeçalho e os dados com malloc e guarda os elementos em ordem
+func (l *LLVMBackend) ListaLiteral(lista *parser.ListaLiteral) interface{} {
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	elemento := l.tipoDe(lista).Elemento()
+
+	valores := make([]value.Value, len(lista.Elementos))
+	for i, e := range lista.Elementos {
+		valores[i] = l.valorParaGuardar(l.processarExpressao(e), l.tipoDe(e))
+	}
+
+	n := l.i64(int64(len(lista.Elementos)))
+	cabecalho := l.block.NewBitCast(l.block.NewCall(malloc, l.i64(24)), ptrLista)
+	dados := l.block.NewCall(malloc, l.i64(int64(8*len(lista.Elementos))))
+	l.block.NewStore(n, l.campo(cabecalho, campoTamanho))
+	l.block.NewStore(n, l.campo(cabecalho, campoCapacidade))
+	l.block.NewStore(dados, l.campo(cabecalho, campoDados))
+
+	if len(valores) > 0 {
+		tipo := l.tipoLLVM(elemento)
+		base := l.block.NewBitCast(dados, types.NewPointer(tipo))
+		for i, v := range valores {
+			l.block.NewStore(v, l.block.NewGetElementPtr(tipo, base, l.i64(int64(i))))
+		}
+	}
+	return cabecalho
+}
+
+func (l *LLVMBackend) Indexacao(indexacao *parser.Indexacao) interface{} {
+	if tipo := l.tipoDe(indexacao.Colecao); tipo.EhMapa() {
+		return l.indexarMapa(indexacao, tipo)
+	}
+	elemento := l.tipoLLVM(l.tipoDe(indexacao))
+	endereco := l.enderecoElemento(indexacao.Colecao, indexacao.Indice, elemento, indexacao.Token)
+	return l.block.NewLoad(elemento, endereco)
+}
+
+func (l *LLVMBackend) AtribuicaoIndice(atribuicao *parser.AtribuicaoIndice) interface{} {
+	if tipo := l.tipoDe(atribuicao.Colecao); tipo.EhMapa() {
+		return l.atribuirMapa(atribuicao, tipo)
+	}
+	elemento := l.tipoLLVM(l.tipoDe(atribuicao.Colecao).Elemento())
+	endereco := l.enderecoElemento(atribuicao.Colecao, atribuicao.Indice, elemento, atribuicao.Token)
+	valor := l.valorParaGuardar(l.processarExpressao(atribuicao.Valor), l.tipoDe(atribuicao.Valor))
+	l.block.NewStore(valor, endereco)
+	return valor
+}
+
+// enderecoElemento avalia a lista e o índice e retorna o endereço do elemento; um índice
+// fora de [0, tamanho) (a comparação sem sinal também pega os negativos) é erro de execução
+func (l *LLVMBackend) enderecoElemento(listaExpr, indiceExpr parser.Expressao, elemento types.Type, token lexer.Token) value.Value {
+	lista := l.processarExpressao(listaExpr)
+	indice := l.processarExpressao(indiceExpr)
+
+	fora := l.function.NewBlock("")
+	dentro := l.function.NewBlock("")
+	tamanho := l.block.NewLoad(types.I64, l.campo(lista, campoTamanho))
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredUGE, indice, tamanho), fora, dentro)
+
+	l.block = fora
+	l.erroExecucao("índice fora dos limites da lista", token)
+
+	l.block = dentro
+	dados := l.block.NewLoad(ptrI8, l.campo(lista, campoDados))
+	base := l.block.NewBitCast(dados, types.NewPointer(elemento))
+	return l.block.NewGetElementPtr(elemento, base, indice)
+}
+
+// campo retorna o endereço de um campo do cabeçalho da lista
+func (l *LLVMBackend) campo(lista value.Value, indice int64) value.Value {
+	return l.block.NewGetElementPtr(tipoLista, lista, constant.NewInt(types.I32, 0), constant.NewInt(types.I32, indice))
+}
+
+// chamarRotinaLista gera a chamada de uma builtin aplicada a uma lista (args[0])
+func (l *LLVMBackend) chamarRotinaLista(rotina string, tipo parser.Tipo, args []value.Value) value.Value {
+	switch rotina {
+	case "lista_tamanho":
+		return l.block.NewLoad(types.I64, l.campo(args[0], campoTamanho))
+	case "lista_anexa":
+		elemento := l.tipoLLVM(tipo.Elemento())
+		espaco := l.block.NewCall(l.rotinaAnexa(), args[0])
+		valor := l.valorParaGuardar(args[1], tipo.Elemento())
+		l.block.NewStore(valor, l.block.NewBitCast(espaco, types.NewPointer(elemento)))
+	}
+	return nil
+}
+
+// rotinaAnexa gera solar_lista_anexa(lista): aumenta o tamanho em um e retorna o endereço
+// do novo elemento; sem espaço, a capacidade dobra (no mínimo 4) com realloc
+func (l *LLVMBackend) rotinaAnexa() *ir.Func {
+	lista := ir.NewParam("lista", ptrLista)
+	f, nova := l.novaRotina("lista_anexa", ptrI8, lista)
+	if !nova {
+		return f
+	}
+	realloc := l.funcaoExterna("realloc", ptrI8, false, ptrI8, types.I64)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, cresce, fim := f.NewBlock("entry"), f.NewBlock("cresce"), f.NewBlock("fim")
+
+	l.block = entrada
+	tamanho := entrada.NewLoad(types.I64, l.campo(lista, campoTamanho))
+	capacidade := entrada.NewLoad(types.I64, l.campo(lista, campoCapacidade))
+	entrada.NewCondBr(entrada.NewICmp(enum.IPredEQ, tamanho, capacidade), cresce, fim)
+
+	l.block = cresce
+	dobro := cresce.NewMul(capacidade, l.i64(2))
+	novaCapacidade := cresce.NewSelect(cresce.NewICmp(enum.IPredSLT, dobro, l.i64(4)), l.i64(4), dobro)
+	antigos := cresce.NewLoad(ptrI8, l.campo(lista, campoDados))
+	cresce.NewStore(cresce.NewCall(realloc, antigos, cresce.NewMul(novaCapacidade, l.i64(8))), l.campo(lista, campoDados))
+	cresce.NewStore(novaCapacidade, l.campo(lista, campoCapacidade))
+	cresce.NewBr(fim)
+
+	l.block = fim
+	fim.NewStore(fim.NewAdd(tamanho, l.i64(1)), l.campo(lista, campoTamanho))
+	dados := fim.NewLoad(ptrI8, l.campo(lista, campoDados))
+	fim.NewRet(fim.NewGetElementPtr(types.I8, dados, fim.NewMul(tamanho, l.i64(8))))
+	return f
+}
+
+// rotinaEscreveLista gera (uma por tipo de lista) a função que escreve a lista em um
+// FILE* como o interpretador: [a, b, c], cada elemento com escreverValor
+func (l *LLVMBackend) rotinaEscreveLista(tipo parser.Tipo) *ir.Func {
+	fluxo, lista := ir.NewParam("fluxo", ptrI8), ir.NewParam("lista", ptrLista)
+	nome := strings.NewReplacer("<", "_", ">", "").Replace("escreve_" + tipo.String())
+	f, nova := l.novaRotina(nome, types.Void, fluxo, lista)
+	if !nova {
+		return f
+	}
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+	elemento := l.tipoLLVM(tipo.Elemento())
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, teste, separador, escreve, fim := f.NewBlock("entry"), f.NewBlock("teste"), f.NewBlock("separador"), f.NewBlock("escreve"), f.NewBlock("fim")
+
+	indice := entrada.NewAlloca(types.I64)
+	entrada.NewStore(l.i64(0), indice)
+	entrada.NewCall(fprintf, fluxo, l.textoConstante("["))
+	entrada.NewBr(teste)
+
+	l.block = teste
+	i := teste.NewLoad(types.I64, indice)
+	tamanho := teste.NewLoad(types.I64, l.campo(lista, campoTamanho))
+	teste.NewCondBr(teste.NewICmp(enum.IPredSLT, i, tamanho), separador, fim)
+
+	primeiro := separador.NewICmp(enum.IPredEQ, i, l.i64(0))
+	separador.NewCall(fprintf, fluxo, separador.NewSelect(primeiro, l.textoConstante(""), l.textoConstante(", ")))
+	separador.NewBr(escreve)
+
+	l.block = escreve
+	dados := escreve.NewLoad(ptrI8, l.campo(lista, campoDados))
+	base := escreve.NewBitCast(dados, types.NewPointer(elemento))
+	l.escreverValor(fluxo, escreve.NewLoad(elemento, escreve.NewGetElementPtr(elemento, base, i)), tipo.Elemento())
+	l.block.NewStore(l.block.NewAdd(i, l.i64(1)), indice)
+	l.block.NewBr(teste)
+
+	fim.NewCall(fprintf, fluxo, l.textoConstante("]"))
+	fim.NewRet(nil)
+	return f
+}
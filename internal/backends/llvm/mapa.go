@@ -0,0 +1,485 @@
+package llvm
+
+import (
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/parser"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// tipoMapa é o cabeçalho dos mapas: tamanho, capacidade, chaves e valores (8 bytes por
+// entrada, em ordem de inserção), a tabela de espalhamento e o número de posições dela
+// (sempre potência de 2). Cada posição guarda o índice da entrada + 1 (0 = livre) e as
+// colisões seguem para a próxima posição
+var tipoMapa = types.NewStruct(types.I64, types.I64, ptrI8, ptrI8, ptrI64, types.I64)
+
+// ptrMapa é o tipo LLVM de todos os mapas
+var ptrMapa = types.NewPointer(tipoMapa)
+
+var ptrI64 = types.NewPointer(types.I64)
+
+// Campos do cabeçalho do mapa
+const (
+	campoMapaTamanho    = 0
+	campoMapaCapacidade = 1
+	campoMapaChaves     = 2
+	campoMapaValores    = 3
+	campoMapaTabela     = 4
+	campoMapaPosicoes   = 5
+)
+
+// MapaLiteral cria um mapa vazio e insere as entradas em ordem (chave repetida: vale a última)
+func (l *LLVMBackend) MapaLiteral(mapa *parser.MapaLiteral) interface{} {
+	tipo := l.tipoDe(mapa)
+	novo := l.block.NewCall(l.rotinaMapaNovo())
+	for i, chave := range mapa.Chaves {
+		k := l.processarExpressao(chave)
+		v := l.valorParaGuardar(l.processarExpressao(mapa.Valores[i]), tipo.Valor())
+		l.guardarNoMapa(novo, tipo, k, v)
+	}
+	return novo
+}
+
+// indexarMapa lê m[k]; chave ausente é erro de execução
+func (l *LLVMBackend) indexarMapa(indexacao *parser.Indexacao, tipo parser.Tipo) value.Value {
+	mapa := l.processarExpressao(indexacao.Colecao)
+	chave := l.processarExpressao(indexacao.Indice)
+	indice := l.block.NewCall(l.rotinaMapaBusca(tipo.Chave()), mapa, l.chaveMapa(chave, tipo.Chave()))
+
+	ausente := l.function.NewBlock("")
+	presente := l.function.NewBlock("")
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredSLT, indice, l.i64(0)), ausente, presente)
+
+	l.block = ausente
+	l.erroExecucao("chave não encontrada no mapa", indexacao.Token)
+
+	l.block = presente
+	valor := l.tipoLLVM(tipo.Valor())
+	valores := l.block.NewBitCast(l.block.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaValores)), types.NewPointer(valor))
+	return l.block.NewLoad(valor, l.block.NewGetElementPtr(valor, valores, indice))
+}
+
+// atribuirMapa gera m[k] ~> v: insere a chave (no fim da ordem) ou troca o valor
+func (l *LLVMBackend) atribuirMapa(atribuicao *parser.AtribuicaoIndice, tipo parser.Tipo) value.Value {
+	mapa := l.processarExpressao(atribuicao.Colecao)
+	chave := l.processarExpressao(atribuicao.Indice)
+	valor := l.valorParaGuardar(l.processarExpressao(atribuicao.Valor), tipo.Valor())
+	l.guardarNoMapa(mapa, tipo, chave, valor)
+	return valor
+}
+
+// guardarNoMapa insere ou atualiza a entrada da chave com o valor
+func (l *LLVMBackend) guardarNoMapa(mapa value.Value, tipo parser.Tipo, chave, valor value.Value) {
+	espaco := l.block.NewCall(l.rotinaMapaInsere(tipo.Chave()), mapa, l.chaveMapa(chave, tipo.Chave()))
+	l.block.NewStore(valor, l.block.NewBitCast(espaco, types.NewPointer(valor.Type())))
+}
+
+// chaveMapa converte a chave para a forma das rotinas: textos seguem como i8*, inteiros
+// e booleanos como i64
+func (l *LLVMBackend) chaveMapa(chave value.Value, tipo parser.Tipo) value.Value {
+	if tipo == parser.TipoBooleano {
+		return l.block.NewZExt(chave, types.I64)
+	}
+	return chave
+}
+
+// campoMapa retorna o endereço de um campo do cabeçalho do mapa
+func (l *LLVMBackend) campoMapa(mapa value.Value, indice int64) value.Value {
+	return l.block.NewGetElementPtr(tipoMapa, mapa, constant.NewInt(types.I32, 0), constant.NewInt(types.I32, indice))
+}
+
+// chamarRotinaMapa gera a chamada de uma builtin aplicada a um mapa (args[0])
+func (l *LLVMBackend) chamarRotinaMapa(rotina string, tipo parser.Tipo, args []value.Value) value.Value {
+	switch rotina {
+	case "mapa_tamanho":
+		return l.block.NewLoad(types.I64, l.campoMapa(args[0], campoMapaTamanho))
+	case "mapa_contem":
+		indice := l.block.NewCall(l.rotinaMapaBusca(tipo.Chave()), args[0], l.chaveMapa(args[1], tipo.Chave()))
+		return l.block.NewICmp(enum.IPredSGE, indice, l.i64(0))
+	case "mapa_remove":
+		l.block.NewCall(l.rotinaMapaRemove(tipo.Chave()), args[0], l.chaveMapa(args[1], tipo.Chave()))
+	case "mapa_chaves":
+		return l.block.NewCall(l.rotinaMapaLista(campoMapaChaves), args[0])
+	}
+	return nil
+}
+
+// tipoChaveMapa retorna o nome da variante das rotinas e o tipo LLVM da chave
+// guardada: textos são comparados com strcmp, o resto como i64
+func tipoChaveMapa(chave parser.Tipo) (string, types.Type) {
+	if chave == parser.TipoTexto {
+		return "texto", ptrI8
+	}
+	return "inteiro", types.I64
+}
+
+// rotinaMapaNovo gera solar_mapa_novo(): um mapa vazio com 8 posições na tabela
+func (l *LLVMBackend) rotinaMapaNovo() *ir.Func {
+	f, nova := l.novaRotina("mapa_novo", ptrMapa)
+	if !nova {
+		return f
+	}
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	calloc := l.funcaoExterna("calloc", ptrI8, false, types.I64, types.I64)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+	l.block = f.NewBlock("entry")
+
+	mapa := l.block.NewBitCast(l.block.NewCall(malloc, l.i64(48)), ptrMapa)
+	l.block.NewStore(l.i64(0), l.campoMapa(mapa, campoMapaTamanho))
+	l.block.NewStore(l.i64(0), l.campoMapa(mapa, campoMapaCapacidade))
+	l.block.NewStore(constant.NewNull(ptrI8), l.campoMapa(mapa, campoMapaChaves))
+	l.block.NewStore(constant.NewNull(ptrI8), l.campoMapa(mapa, campoMapaValores))
+	tabela := l.block.NewBitCast(l.block.NewCall(calloc, l.i64(8), l.i64(8)), ptrI64)
+	l.block.NewStore(tabela, l.campoMapa(mapa, campoMapaTabela))
+	l.block.NewStore(l.i64(8), l.campoMapa(mapa, campoMapaPosicoes))
+	l.block.NewRet(mapa)
+	return f
+}
+
+// rotinaMapaEspalha gera a função de espalhamento da chave: multiplicação pela constante
+// de Fibonacci para inteiros e FNV-1a para textos
+func (l *LLVMBackend) rotinaMapaEspalha(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	k := ir.NewParam("chave", tipo)
+	f, nova := l.novaRotina("mapa_espalha_"+variante, types.I64, k)
+	if !nova {
+		return f
+	}
+	entrada := f.NewBlock("entry")
+	if variante == "inteiro" {
+		h := entrada.NewMul(k, l.i64(-7046029254386353131)) // 0x9E3779B97F4A7C15
+		entrada.NewRet(entrada.NewXor(h, entrada.NewLShr(h, l.i64(32))))
+		return f
+	}
+
+	teste, corpo, fim := f.NewBlock("teste"), f.NewBlock("corpo"), f.NewBlock("fim")
+	hash := entrada.NewAlloca(types.I64)
+	atual := entrada.NewAlloca(ptrI8)
+	entrada.NewStore(l.i64(-3750763034362895579), hash) // 0xcbf29ce484222325
+	entrada.NewStore(k, atual)
+	entrada.NewBr(teste)
+
+	p := teste.NewLoad(ptrI8, atual)
+	c := teste.NewLoad(types.I8, p)
+	teste.NewCondBr(teste.NewICmp(enum.IPredEQ, c, constant.NewInt(types.I8, 0)), fim, corpo)
+
+	h := corpo.NewXor(corpo.NewLoad(types.I64, hash), corpo.NewZExt(c, types.I64))
+	corpo.NewStore(corpo.NewMul(h, l.i64(1099511628211)), hash)
+	corpo.NewStore(corpo.NewGetElementPtr(types.I8, p, l.i64(1)), atual)
+	corpo.NewBr(teste)
+
+	fim.NewRet(fim.NewLoad(types.I64, hash))
+	return f
+}
+
+// rotinaMapaProcura gera solar_mapa_procura(mapa, chave): a posição da tabela que aponta
+// para a entrada da chave ou, se ela não existe, a posição livre onde ela entraria
+func (l *LLVMBackend) rotinaMapaProcura(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	mapa, k := ir.NewParam("mapa", ptrMapa), ir.NewParam("chave", tipo)
+	f, nova := l.novaRotina("mapa_procura_"+variante, types.I64, mapa, k)
+	if !nova {
+		return f
+	}
+	espalha := l.rotinaMapaEspalha(chave)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, teste, compara, proxima, achou := f.NewBlock("entry"), f.NewBlock("teste"), f.NewBlock("compara"), f.NewBlock("proxima"), f.NewBlock("achou")
+
+	l.block = entrada
+	mascara := entrada.NewSub(entrada.NewLoad(types.I64, l.campoMapa(mapa, campoMapaPosicoes)), l.i64(1))
+	posicao := entrada.NewAlloca(types.I64)
+	entrada.NewStore(entrada.NewAnd(entrada.NewCall(espalha, k), mascara), posicao)
+	entrada.NewBr(teste)
+
+	l.block = teste
+	pos := teste.NewLoad(types.I64, posicao)
+	tabela := teste.NewLoad(ptrI64, l.campoMapa(mapa, campoMapaTabela))
+	entradaAtual := teste.NewLoad(types.I64, teste.NewGetElementPtr(types.I64, tabela, pos))
+	teste.NewCondBr(teste.NewICmp(enum.IPredEQ, entradaAtual, l.i64(0)), achou, compara)
+
+	l.block = compara
+	chaves := compara.NewBitCast(compara.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaChaves)), types.NewPointer(tipo))
+	existente := compara.NewLoad(tipo, compara.NewGetElementPtr(tipo, chaves, compara.NewSub(entradaAtual, l.i64(1))))
+	var iguais value.Value
+	if variante == "texto" {
+		strcmp := l.funcaoExterna("strcmp", types.I32, false, ptrI8, ptrI8)
+		iguais = compara.NewICmp(enum.IPredEQ, compara.NewCall(strcmp, existente, k), constant.NewInt(types.I32, 0))
+	} else {
+		iguais = compara.NewICmp(enum.IPredEQ, existente, k)
+	}
+	compara.NewCondBr(iguais, achou, proxima)
+
+	proxima.NewStore(proxima.NewAnd(proxima.NewAdd(pos, l.i64(1)), mascara), posicao)
+	proxima.NewBr(teste)
+
+	achou.NewRet(pos)
+	return f
+}
+
+// rotinaMapaBusca gera solar_mapa_busca(mapa, chave): o índice da entrada da chave ou -1
+func (l *LLVMBackend) rotinaMapaBusca(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	mapa, k := ir.NewParam("mapa", ptrMapa), ir.NewParam("chave", tipo)
+	f, nova := l.novaRotina("mapa_busca_"+variante, types.I64, mapa, k)
+	if !nova {
+		return f
+	}
+	procura := l.rotinaMapaProcura(chave)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+	l.block = f.NewBlock("entry")
+
+	pos := l.block.NewCall(procura, mapa, k)
+	tabela := l.block.NewLoad(ptrI64, l.campoMapa(mapa, campoMapaTabela))
+	entrada := l.block.NewLoad(types.I64, l.block.NewGetElementPtr(types.I64, tabela, pos))
+	l.block.NewRet(l.block.NewSub(entrada, l.i64(1)))
+	return f
+}
+
+// rotinaMapaInsere gera solar_mapa_insere(mapa, chave): o endereço do valor da chave,
+// criando a entrada no fim da ordem se ela não existe. As entradas crescem como as
+// listas (capacidade dobra, no mínimo 4) e a tabela dobra quando passa da metade
+func (l *LLVMBackend) rotinaMapaInsere(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	mapa, k := ir.NewParam("mapa", ptrMapa), ir.NewParam("chave", tipo)
+	f, nova := l.novaRotina("mapa_insere_"+variante, ptrI8, mapa, k)
+	if !nova {
+		return f
+	}
+	procura := l.rotinaMapaProcura(chave)
+	reindexa := l.rotinaMapaReindexa(chave)
+	realloc := l.funcaoExterna("realloc", ptrI8, false, ptrI8, types.I64)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, existente, novaEntrada := f.NewBlock("entry"), f.NewBlock("existente"), f.NewBlock("nova")
+	cresce, guarda, refaz, fim := f.NewBlock("cresce"), f.NewBlock("guarda"), f.NewBlock("refaz"), f.NewBlock("fim")
+
+	l.block = entrada
+	pos := entrada.NewCall(procura, mapa, k)
+	tabela := entrada.NewLoad(ptrI64, l.campoMapa(mapa, campoMapaTabela))
+	posicao := entrada.NewGetElementPtr(types.I64, tabela, pos)
+	indice := entrada.NewLoad(types.I64, posicao)
+	entrada.NewCondBr(entrada.NewICmp(enum.IPredEQ, indice, l.i64(0)), novaEntrada, existente)
+
+	l.block = existente
+	valores := existente.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaValores))
+	existente.NewRet(existente.NewGetElementPtr(types.I8, valores, existente.NewMul(existente.NewSub(indice, l.i64(1)), l.i64(8))))
+
+	l.block = novaEntrada
+	tamanho := novaEntrada.NewLoad(types.I64, l.campoMapa(mapa, campoMapaTamanho))
+	capacidade := novaEntrada.NewLoad(types.I64, l.campoMapa(mapa, campoMapaCapacidade))
+	novaEntrada.NewCondBr(novaEntrada.NewICmp(enum.IPredEQ, tamanho, capacidade), cresce, guarda)
+
+	l.block = cresce
+	dobro := cresce.NewMul(capacidade, l.i64(2))
+	novaCapacidade := cresce.NewSelect(cresce.NewICmp(enum.IPredSLT, dobro, l.i64(4)), l.i64(4), dobro)
+	bytes := cresce.NewMul(novaCapacidade, l.i64(8))
+	for _, campo := range []int64{campoMapaChaves, campoMapaValores} {
+		antigos := cresce.NewLoad(ptrI8, l.campoMapa(mapa, campo))
+		cresce.NewStore(cresce.NewCall(realloc, antigos, bytes), l.campoMapa(mapa, campo))
+	}
+	cresce.NewStore(novaCapacidade, l.campoMapa(mapa, campoMapaCapacidade))
+	cresce.NewBr(guarda)
+
+	l.block = guarda
+	chaves := guarda.NewBitCast(guarda.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaChaves)), types.NewPointer(tipo))
+	guarda.NewStore(k, guarda.NewGetElementPtr(tipo, chaves, tamanho))
+	novoTamanho := guarda.NewAdd(tamanho, l.i64(1))
+	guarda.NewStore(novoTamanho, posicao)
+	guarda.NewStore(novoTamanho, l.campoMapa(mapa, campoMapaTamanho))
+	posicoes := guarda.NewLoad(types.I64, l.campoMapa(mapa, campoMapaPosicoes))
+	guarda.NewCondBr(guarda.NewICmp(enum.IPredSGT, guarda.NewMul(novoTamanho, l.i64(2)), posicoes), refaz, fim)
+
+	refaz.NewCall(reindexa, mapa, refaz.NewMul(posicoes, l.i64(2)))
+	refaz.NewBr(fim)
+
+	l.block = fim
+	valores = fim.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaValores))
+	fim.NewRet(fim.NewGetElementPtr(types.I8, valores, fim.NewMul(tamanho, l.i64(8))))
+	return f
+}
+
+// rotinaMapaReindexa gera solar_mapa_reindexa(mapa, posicoes): troca a tabela por uma
+// nova, vazia, com o número de posições dado e recoloca todas as entradas
+func (l *LLVMBackend) rotinaMapaReindexa(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	mapa, posicoes := ir.NewParam("mapa", ptrMapa), ir.NewParam("posicoes", types.I64)
+	f, nova := l.novaRotina("mapa_reindexa_"+variante, types.Void, mapa, posicoes)
+	if !nova {
+		return f
+	}
+	procura := l.rotinaMapaProcura(chave)
+	calloc := l.funcaoExterna("calloc", ptrI8, false, types.I64, types.I64)
+	free := l.funcaoExterna("free", types.Void, false, ptrI8)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, teste, corpo, fim := f.NewBlock("entry"), f.NewBlock("teste"), f.NewBlock("corpo"), f.NewBlock("fim")
+
+	l.block = entrada
+	antiga := entrada.NewLoad(ptrI64, l.campoMapa(mapa, campoMapaTabela))
+	entrada.NewCall(free, entrada.NewBitCast(antiga, ptrI8))
+	tabela := entrada.NewBitCast(entrada.NewCall(calloc, posicoes, l.i64(8)), ptrI64)
+	entrada.NewStore(tabela, l.campoMapa(mapa, campoMapaTabela))
+	entrada.NewStore(posicoes, l.campoMapa(mapa, campoMapaPosicoes))
+	tamanho := entrada.NewLoad(types.I64, l.campoMapa(mapa, campoMapaTamanho))
+	indice := entrada.NewAlloca(types.I64)
+	entrada.NewStore(l.i64(0), indice)
+	entrada.NewBr(teste)
+
+	i := teste.NewLoad(types.I64, indice)
+	teste.NewCondBr(teste.NewICmp(enum.IPredSLT, i, tamanho), corpo, fim)
+
+	l.block = corpo
+	chaves := corpo.NewBitCast(corpo.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaChaves)), types.NewPointer(tipo))
+	pos := corpo.NewCall(procura, mapa, corpo.NewLoad(tipo, corpo.NewGetElementPtr(tipo, chaves, i)))
+	proximo := corpo.NewAdd(i, l.i64(1))
+	corpo.NewStore(proximo, corpo.NewGetElementPtr(types.I64, tabela, pos))
+	corpo.NewStore(proximo, indice)
+	corpo.NewBr(teste)
+
+	fim.NewRet(nil)
+	return f
+}
+
+// rotinaMapaRemove gera solar_mapa_remove(mapa, chave): remove a entrada (se existir)
+// deslocando as seguintes para manter a ordem de inserção e refaz a tabela
+func (l *LLVMBackend) rotinaMapaRemove(chave parser.Tipo) *ir.Func {
+	variante, tipo := tipoChaveMapa(chave)
+	mapa, k := ir.NewParam("mapa", ptrMapa), ir.NewParam("chave", tipo)
+	f, nova := l.novaRotina("mapa_remove_"+variante, types.Void, mapa, k)
+	if !nova {
+		return f
+	}
+	busca := l.rotinaMapaBusca(chave)
+	reindexa := l.rotinaMapaReindexa(chave)
+	memmove := l.funcaoExterna("memmove", ptrI8, false, ptrI8, ptrI8, types.I64)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, remove, fim := f.NewBlock("entry"), f.NewBlock("remove"), f.NewBlock("fim")
+
+	indice := entrada.NewCall(busca, mapa, k)
+	entrada.NewCondBr(entrada.NewICmp(enum.IPredSLT, indice, l.i64(0)), fim, remove)
+
+	l.block = remove
+	tamanho := remove.NewLoad(types.I64, l.campoMapa(mapa, campoMapaTamanho))
+	resto := remove.NewMul(remove.NewSub(remove.NewSub(tamanho, indice), l.i64(1)), l.i64(8))
+	destino := remove.NewMul(indice, l.i64(8))
+	origem := remove.NewAdd(destino, l.i64(8))
+	for _, campo := range []int64{campoMapaChaves, campoMapaValores} {
+		dados := remove.NewLoad(ptrI8, l.campoMapa(mapa, campo))
+		remove.NewCall(memmove, remove.NewGetElementPtr(types.I8, dados, destino), remove.NewGetElementPtr(types.I8, dados, origem), resto)
+	}
+	remove.NewStore(remove.NewSub(tamanho, l.i64(1)), l.campoMapa(mapa, campoMapaTamanho))
+	remove.NewCall(reindexa, mapa, remove.NewLoad(types.I64, l.campoMapa(mapa, campoMapaPosicoes)))
+	remove.NewBr(fim)
+
+	fim.NewRet(nil)
+	return f
+}
+
+// rotinaMapaLista gera solar_mapa_chaves / solar_mapa_valores: uma lista nova com uma
+// cópia das chaves ou dos valores, em ordem de inserção
+func (l *LLVMBackend) rotinaMapaLista(campo int64) *ir.Func {
+	nome := "mapa_chaves"
+	if campo == campoMapaValores {
+		nome = "mapa_valores"
+	}
+	mapa := ir.NewParam("mapa", ptrMapa)
+	f, nova := l.novaRotina(nome, ptrLista, mapa)
+	if !nova {
+		return f
+	}
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	memcpy := l.funcaoExterna("memcpy", ptrI8, false, ptrI8, ptrI8, types.I64)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+	l.block = f.NewBlock("entry")
+
+	tamanho := l.block.NewLoad(types.I64, l.campoMapa(mapa, campoMapaTamanho))
+	bytes := l.block.NewMul(tamanho, l.i64(8))
+	lista := l.block.NewBitCast(l.block.NewCall(malloc, l.i64(24)), ptrLista)
+	dados := l.block.NewCall(malloc, bytes)
+	origem := l.block.NewLoad(ptrI8, l.campoMapa(mapa, campo))
+	vazio := l.function.NewBlock("vazio")
+	copia := l.function.NewBlock("copia")
+	l.block.NewStore(tamanho, l.campo(lista, campoTamanho))
+	l.block.NewStore(tamanho, l.campo(lista, campoCapacidade))
+	l.block.NewStore(dados, l.campo(lista, campoDados))
+	// Sem entradas, chaves e valores ainda são nulos: nada a copiar
+	l.block.NewCondBr(l.block.NewICmp(enum.IPredEQ, tamanho, l.i64(0)), vazio, copia)
+
+	copia.NewCall(memcpy, dados, origem, bytes)
+	copia.NewRet(lista)
+	vazio.NewRet(lista)
+	return f
+}
+
+// rotinaEscreveMapa gera (uma por tipo de mapa) a função que escreve o mapa em um FILE*
+// como o interpretador: {k: v, ...}, em ordem de inserção
+func (l *LLVMBackend) rotinaEscreveMapa(tipo parser.Tipo) *ir.Func {
+	fluxo, mapa := ir.NewParam("fluxo", ptrI8), ir.NewParam("mapa", ptrMapa)
+	nome := strings.NewReplacer("<", "_", ">", "", ", ", "_").Replace("escreve_" + tipo.String())
+	f, nova := l.novaRotina(nome, types.Void, fluxo, mapa)
+	if !nova {
+		return f
+	}
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+	chave, valor := l.tipoLLVM(tipo.Chave()), l.tipoLLVM(tipo.Valor())
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, teste, separador, escreve, fim := f.NewBlock("entry"), f.NewBlock("teste"), f.NewBlock("separador"), f.NewBlock("escreve"), f.NewBlock("fim")
+
+	indice := entrada.NewAlloca(types.I64)
+	entrada.NewStore(l.i64(0), indice)
+	entrada.NewCall(fprintf, fluxo, l.textoConstante("{"))
+	entrada.NewBr(teste)
+
+	l.block = teste
+	i := teste.NewLoad(types.I64, indice)
+	tamanho := teste.NewLoad(types.I64, l.campoMapa(mapa, campoMapaTamanho))
+	teste.NewCondBr(teste.NewICmp(enum.IPredSLT, i, tamanho), separador, fim)
+
+	primeiro := separador.NewICmp(enum.IPredEQ, i, l.i64(0))
+	separador.NewCall(fprintf, fluxo, separador.NewSelect(primeiro, l.textoConstante(""), l.textoConstante(", ")))
+	separador.NewBr(escreve)
+
+	l.block = escreve
+	chaves := escreve.NewBitCast(escreve.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaChaves)), types.NewPointer(chave))
+	l.escreverValor(fluxo, escreve.NewLoad(chave, escreve.NewGetElementPtr(chave, chaves, i)), tipo.Chave())
+	l.block.NewCall(fprintf, fluxo, l.textoConstante(": "))
+	valores := l.block.NewBitCast(l.block.NewLoad(ptrI8, l.campoMapa(mapa, campoMapaValores)), types.NewPointer(valor))
+	l.escreverValor(fluxo, l.block.NewLoad(valor, l.block.NewGetElementPtr(valor, valores, i)), tipo.Valor())
+	l.block.NewStore(l.block.NewAdd(i, l.i64(1)), indice)
+	l.block.NewBr(teste)
+
+	fim.NewCall(fprintf, fluxo, l.textoConstante("}"))
+	fim.NewRet(nil)
+	return f
+}
@@ -0,0 +1,145 @@
+package llvm
+
+import (
+	"github.com/khevencolino/Solar/internal/parser"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// Registros são valores: uma variável guarda a estrutura inteira (registros aninhados
+// ficam dentro dela) e toda atribuição copia. Uma expressão de registro é o endereço
+// da estrutura; listas, mapas e retornos guardam cópias alocadas com malloc
+
+// estruturaRegistro retorna (criando na primeira vez) o tipo nomeado %registro.Nome
+func (l *LLVMBackend) estruturaRegistro(tipo parser.Tipo) *types.StructType {
+	if st, ok := l.estruturas[tipo]; ok {
+		return st
+	}
+	campos := make([]types.Type, len(l.declarados.Campos(tipo)))
+	for i, campo := range l.declarados.Campos(tipo) {
+		if l.declarados.EhRegistro(campo.Tipo) {
+			campos[i] = l.estruturaRegistro(campo.Tipo)
+		} else {
+			campos[i] = l.tipoLLVM(campo.Tipo)
+		}
+	}
+	st := types.NewStruct(campos...)
+	l.module.NewTypeDef("registro."+tipo.String(), st)
+	l.estruturas[tipo] = st
+	return st
+}
+
+// RegistroLiteral monta o registro em uma estrutura temporária da função, avaliando os
+// campos na ordem em que aparecem no código
+func (l *LLVMBackend) RegistroLiteral(literal *parser.RegistroLiteral) interface{} {
+	tipo := l.tipoDe(literal)
+	st := l.estruturaRegistro(tipo)
+	registro := l.novaAlloca(st)
+	for i, nome := range literal.Campos {
+		indice, campo, _ := l.declarados.Campo(tipo, nome)
+		valor := l.processarExpressao(literal.Valores[i])
+		l.guardarCampo(l.enderecoCampo(registro, st, indice), valor, campo)
+	}
+	return registro
+}
+
+// AcessoCampo lê r.campo; um campo registro é devolvido pelo endereço, sem cópia
+func (l *LLVMBackend) AcessoCampo(acesso *parser.AcessoCampo) interface{} {
+	tipo := l.tipoDe(acesso.Objeto)
+	st := l.estruturaRegistro(tipo)
+	indice, campo, _ := l.declarados.Campo(tipo, acesso.Campo)
+	endereco := l.enderecoCampo(l.processarExpressao(acesso.Objeto), st, indice)
+	if l.declarados.EhRegistro(campo) {
+		return endereco
+	}
+	return l.block.NewLoad(st.Fields[indice], endereco)
+}
+
+// AtribuicaoCampo gera r.campo ~> v, alterando a estrutura guardada em r
+func (l *LLVMBackend) AtribuicaoCampo(atribuicao *parser.AtribuicaoCampo) interface{} {
+	tipo := l.tipoDe(atribuicao.Objeto)
+	st := l.estruturaRegistro(tipo)
+	indice, campo, _ := l.declarados.Campo(tipo, atribuicao.Campo)
+	endereco := l.enderecoCampo(l.processarExpressao(atribuicao.Objeto), st, indice)
+	valor := l.processarExpressao(atribuicao.Valor)
+	l.guardarCampo(endereco, valor, campo)
+	return valor
+}
+
+// RegistroDeclaracao não gera código: a estrutura é criada no primeiro uso do tipo
+func (l *LLVMBackend) RegistroDeclaracao(declaracao *parser.RegistroDeclaracao) interface{} {
+	return nil
+}
+
+// enderecoCampo retorna o endereço do campo de índice i da estrutura
+func (l *LLVMBackend) enderecoCampo(registro value.Value, st *types.StructType, i int) value.Value {
+	return l.block.NewGetElementPtr(st, registro, constant.NewInt(types.I32, 0), constant.NewInt(types.I32, int64(i)))
+}
+
+// guardarCampo guarda o valor no endereço; um registro tem a estrutura copiada
+func (l *LLVMBackend) guardarCampo(endereco, valor value.Value, tipo parser.Tipo) {
+	if l.declarados.EhRegistro(tipo) {
+		valor = l.block.NewLoad(l.estruturaRegistro(tipo), valor)
+	}
+	l.block.NewStore(valor, endereco)
+}
+
+// valorParaGuardar prepara um valor que vai para uma lista, um mapa ou um retorno:
+// registros viram uma cópia alocada com malloc, os demais valores seguem como estão
+func (l *LLVMBackend) valorParaGuardar(valor value.Value, tipo parser.Tipo) value.Value {
+	if !l.declarados.EhRegistro(tipo) {
+		return valor
+	}
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	st := l.estruturaRegistro(tipo)
+	ptr := types.NewPointer(st)
+	// sizeof da estrutura: endereço do elemento 1 a partir de null
+	tamanho := constant.NewPtrToInt(constant.NewGetElementPtr(st, constant.NewNull(ptr), l.i64(1)), types.I64)
+	copia := l.block.NewBitCast(l.block.NewCall(malloc, tamanho), ptr)
+	l.block.NewStore(l.block.NewLoad(st, valor), copia)
+	return copia
+}
+
+// declararLocal cria uma variável local com o valor inicial (parâmetros e variáveis
+// de laço); um registro é copiado para a estrutura da própria variável
+func (l *LLVMBackend) declararLocal(nome string, valor value.Value, tipo parser.Tipo) {
+	if l.declarados.EhRegistro(tipo) {
+		valor = l.block.NewLoad(l.estruturaRegistro(tipo), valor)
+	}
+	alloca := l.novaAlloca(valor.Type())
+	l.block.NewStore(valor, alloca)
+	l.setVar(nome, alloca)
+}
+
+// rotinaEscreveRegistro gera (uma por registro) a função que escreve o registro em um
+// FILE* como o interpretador: Nome{campo: valor, ...}
+func (l *LLVMBackend) rotinaEscreveRegistro(tipo parser.Tipo) *ir.Func {
+	st := l.estruturaRegistro(tipo)
+	fluxo, registro := ir.NewParam("fluxo", ptrI8), ir.NewParam("registro", types.NewPointer(st))
+	f, nova := l.novaRotina("escreve_"+tipo.String(), types.Void, fluxo, registro)
+	if !nova {
+		return f
+	}
+	fprintf := l.funcaoExterna("fprintf", types.I32, true, ptrI8, ptrI8)
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+	l.block = f.NewBlock("entry")
+
+	separador := tipo.String() + "{"
+	for i, campo := range l.declarados.Campos(tipo) {
+		l.block.NewCall(fprintf, fluxo, l.textoConstante(separador+campo.Nome+": "))
+		var valor value.Value = l.enderecoCampo(registro, st, i)
+		if !l.declarados.EhRegistro(campo.Tipo) {
+			valor = l.block.NewLoad(st.Fields[i], valor)
+		}
+		l.escreverValor(fluxo, valor, campo.Tipo)
+		separador = ", "
+	}
+	l.block.NewCall(fprintf, fluxo, l.textoConstante("}"))
+	l.block.NewRet(nil)
+	return f
+}
@@ -0,0 +1,299 @@
+package llvm
+
+import (
+	"github.com/khevencolino/Solar/internal/parser"
+	"github.com/llir/llvm/ir"
+	"github.com/llir/llvm/ir/constant"
+	"github.com/llir/llvm/ir/enum"
+	"github.com/llir/llvm/ir/types"
+	"github.com/llir/llvm/ir/value"
+)
+
+// operacaoTexto gera '+' (concatenação) e as comparações entre textos; a ordem é a
+// lexicográfica dos bytes, a mesma do strcmp
+func (l *LLVMBackend) operacaoTexto(operador parser.TipoOperador, esquerda, direita value.Value) value.Value {
+	if operador == parser.ADICAO {
+		return l.block.NewCall(l.rotinaConcatena(), esquerda, direita)
+	}
+	strcmp := l.funcaoExterna("strcmp", types.I32, false, ptrI8, ptrI8)
+	pred := map[parser.TipoOperador]enum.IPred{
+		parser.IGUALDADE:   enum.IPredEQ,
+		parser.DIFERENCA:   enum.IPredNE,
+		parser.MENOR_QUE:   enum.IPredSLT,
+		parser.MAIOR_QUE:   enum.IPredSGT,
+		parser.MENOR_IGUAL: enum.IPredSLE,
+		parser.MAIOR_IGUAL: enum.IPredSGE,
+	}[operador]
+	return l.block.NewICmp(pred, l.block.NewCall(strcmp, esquerda, direita), constant.NewInt(types.I32, 0))
+}
+
+// chamarRotinaTexto gera a chamada de uma builtin de texto pelo nome da sua rotina no runtime
+func (l *LLVMBackend) chamarRotinaTexto(rotina string, args []value.Value) value.Value {
+	switch rotina {
+	case "texto_tamanho":
+		return l.block.NewCall(l.rotinaTamanho(), args...)
+	case "texto_maiusculas":
+		return l.block.NewCall(l.rotinaCaixa(rotina, true), args...)
+	case "texto_minusculas":
+		return l.block.NewCall(l.rotinaCaixa(rotina, false), args...)
+	case "texto_contem":
+		strstr := l.funcaoExterna("strstr", ptrI8, false, ptrI8, ptrI8)
+		return l.block.NewICmp(enum.IPredNE, l.block.NewCall(strstr, args...), constant.NewNull(ptrI8))
+	case "texto_substitui":
+		return l.block.NewCall(l.rotinaSubstitui(), args...)
+	case "texto_divide":
+		return l.block.NewCall(l.rotinaDivide(), args...)
+	}
+	return nil
+}
+
+// novaRotina declara uma rotina de texto gerada pelo backend; o segundo retorno é false
+// quando ela já foi gerada e o corpo não deve ser construído de novo
+func (l *LLVMBackend) novaRotina(nome string, retorno types.Type, params ...*ir.Param) (*ir.Func, bool) {
+	if f, ok := l.rotinas[nome]; ok {
+		return f, false
+	}
+	f := l.module.NewFunc("solar_"+nome, retorno, params...)
+	l.rotinas[nome] = f
+	return f, true
+}
+
+// rotinaConcatena gera solar_texto_concatena(a, b): um novo texto com a seguido de b
+func (l *LLVMBackend) rotinaConcatena() *ir.Func {
+	a, b := ir.NewParam("a", ptrI8), ir.NewParam("b", ptrI8)
+	f, nova := l.novaRotina("texto_concatena", ptrI8, a, b)
+	if !nova {
+		return f
+	}
+	strlen := l.funcaoExterna("strlen", types.I64, false, ptrI8)
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	memcpy := l.funcaoExterna("memcpy", ptrI8, false, ptrI8, ptrI8, types.I64)
+
+	entrada := f.NewBlock("entry")
+	tamA := entrada.NewCall(strlen, a)
+	tamB := entrada.NewCall(strlen, b)
+	resultado := entrada.NewCall(malloc, entrada.NewAdd(entrada.NewAdd(tamA, tamB), l.i64(1)))
+	entrada.NewCall(memcpy, resultado, a, tamA)
+	// Copia b junto com o \0 final
+	entrada.NewCall(memcpy, entrada.NewGetElementPtr(types.I8, resultado, tamA), b, entrada.NewAdd(tamB, l.i64(1)))
+	entrada.NewRet(resultado)
+	return f
+}
+
+// rotinaTamanho gera solar_texto_tamanho(t): a quantidade de caracteres, contando os
+// bytes UTF-8 que não são de continuação (10xxxxxx)
+func (l *LLVMBackend) rotinaTamanho() *ir.Func {
+	t := ir.NewParam("t", ptrI8)
+	f, nova := l.novaRotina("texto_tamanho", types.I64, t)
+	if !nova {
+		return f
+	}
+	entrada, loop, fim := f.NewBlock("entry"), f.NewBlock("loop"), f.NewBlock("fim")
+
+	indice := entrada.NewAlloca(types.I64)
+	total := entrada.NewAlloca(types.I64)
+	entrada.NewStore(l.i64(0), indice)
+	entrada.NewStore(l.i64(0), total)
+	entrada.NewBr(loop)
+
+	i := loop.NewLoad(types.I64, indice)
+	c := loop.NewLoad(types.I8, loop.NewGetElementPtr(types.I8, t, i))
+	continuacao := loop.NewICmp(enum.IPredEQ, loop.NewAnd(c, constant.NewInt(types.I8, -64)), constant.NewInt(types.I8, -128))
+	n := loop.NewLoad(types.I64, total)
+	loop.NewStore(loop.NewAdd(n, loop.NewSelect(continuacao, l.i64(0), l.i64(1))), total)
+	loop.NewStore(loop.NewAdd(i, l.i64(1)), indice)
+	loop.NewCondBr(loop.NewICmp(enum.IPredEQ, c, constant.NewInt(types.I8, 0)), fim, loop)
+
+	// O \0 final também foi contado
+	fim.NewRet(fim.NewSub(fim.NewLoad(types.I64, total), l.i64(1)))
+	return f
+}
+
+// rotinaCaixa gera solar_texto_maiusculas/minusculas(t) com a regra de
+// registry.ConverterCaixa: letras ASCII e, depois de um byte 0xC3, o segundo byte
+// das letras do Latin-1 (à-þ ou À-Þ, exceto ÷ e ×)
+func (l *LLVMBackend) rotinaCaixa(nome string, maiusculas bool) *ir.Func {
+	t := ir.NewParam("t", ptrI8)
+	f, nova := l.novaRotina(nome, ptrI8, t)
+	if !nova {
+		return f
+	}
+	strlen := l.funcaoExterna("strlen", types.I64, false, ptrI8)
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	i8 := func(v int64) *constant.Int { return constant.NewInt(types.I8, v) }
+
+	// Bytes tratados como inteiros com sinal: 0xC3 = -61, 0xA0 = -96, 0x80 = -128
+	inicioAscii, inicioLatin, delta := i8('a'), i8(-96), i8(-32)
+	if !maiusculas {
+		inicioAscii, inicioLatin, delta = i8('A'), i8(-128), i8(32)
+	}
+
+	entrada, loop, fim := f.NewBlock("entry"), f.NewBlock("loop"), f.NewBlock("fim")
+	resultado := entrada.NewCall(malloc, entrada.NewAdd(entrada.NewCall(strlen, t), l.i64(1)))
+	indice := entrada.NewAlloca(types.I64)
+	anterior := entrada.NewAlloca(types.I8)
+	entrada.NewStore(l.i64(0), indice)
+	entrada.NewStore(i8(0), anterior)
+	entrada.NewBr(loop)
+
+	i := loop.NewLoad(types.I64, indice)
+	c := loop.NewLoad(types.I8, loop.NewGetElementPtr(types.I8, t, i))
+	ascii := loop.NewICmp(enum.IPredULT, loop.NewSub(c, inicioAscii), i8(26))
+	posLatin := loop.NewSub(c, inicioLatin)
+	latin := loop.NewAnd(
+		loop.NewICmp(enum.IPredEQ, loop.NewLoad(types.I8, anterior), i8(-61)),
+		loop.NewAnd(loop.NewICmp(enum.IPredULT, posLatin, i8(31)), loop.NewICmp(enum.IPredNE, posLatin, i8(0x17))),
+	)
+	convertido := loop.NewSelect(loop.NewOr(ascii, latin), loop.NewAdd(c, delta), c)
+	loop.NewStore(convertido, loop.NewGetElementPtr(types.I8, resultado, i))
+	loop.NewStore(c, anterior)
+	loop.NewStore(loop.NewAdd(i, l.i64(1)), indice)
+	loop.NewCondBr(loop.NewICmp(enum.IPredEQ, c, i8(0)), fim, loop)
+
+	fim.NewRet(resultado)
+	return f
+}
+
+// rotinaSubstitui gera solar_texto_substitui(t, antigo, novo): conta as ocorrências
+// de antigo para alocar o resultado e depois copia os trechos entre elas. Com antigo
+// vazio o texto volta inalterado
+func (l *LLVMBackend) rotinaSubstitui() *ir.Func {
+	t, antigo, novo := ir.NewParam("t", ptrI8), ir.NewParam("antigo", ptrI8), ir.NewParam("novo", ptrI8)
+	f, nova := l.novaRotina("texto_substitui", ptrI8, t, antigo, novo)
+	if !nova {
+		return f
+	}
+	strlen := l.funcaoExterna("strlen", types.I64, false, ptrI8)
+	strstr := l.funcaoExterna("strstr", ptrI8, false, ptrI8, ptrI8)
+	strcpy := l.funcaoExterna("strcpy", ptrI8, false, ptrI8, ptrI8)
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	memcpy := l.funcaoExterna("memcpy", ptrI8, false, ptrI8, ptrI8, types.I64)
+
+	entrada, inalterado, conta := f.NewBlock("entry"), f.NewBlock("inalterado"), f.NewBlock("conta")
+	buscaConta, achouConta := f.NewBlock("busca_conta"), f.NewBlock("achou_conta")
+	aloca, buscaCopia, achouCopia, fim := f.NewBlock("aloca"), f.NewBlock("busca_copia"), f.NewBlock("achou_copia"), f.NewBlock("fim")
+
+	posicao := entrada.NewAlloca(ptrI8)
+	destino := entrada.NewAlloca(ptrI8)
+	ocorrencias := entrada.NewAlloca(types.I64)
+	tamAntigo := entrada.NewCall(strlen, antigo)
+	entrada.NewCondBr(entrada.NewICmp(enum.IPredEQ, tamAntigo, l.i64(0)), inalterado, conta)
+
+	inalterado.NewRet(t)
+
+	// Primeira passada: conta as ocorrências
+	tamNovo := conta.NewCall(strlen, novo)
+	conta.NewStore(t, posicao)
+	conta.NewStore(l.i64(0), ocorrencias)
+	conta.NewBr(buscaConta)
+
+	achado := buscaConta.NewCall(strstr, buscaConta.NewLoad(ptrI8, posicao), antigo)
+	buscaConta.NewCondBr(buscaConta.NewICmp(enum.IPredNE, achado, constant.NewNull(ptrI8)), achouConta, aloca)
+
+	achouConta.NewStore(achouConta.NewAdd(achouConta.NewLoad(types.I64, ocorrencias), l.i64(1)), ocorrencias)
+	achouConta.NewStore(achouConta.NewGetElementPtr(types.I8, achado, tamAntigo), posicao)
+	achouConta.NewBr(buscaConta)
+
+	// tamanho(t) + ocorrências * (tamanho(novo) - tamanho(antigo)) + \0
+	diferenca := aloca.NewMul(aloca.NewLoad(types.I64, ocorrencias), aloca.NewSub(tamNovo, tamAntigo))
+	resultado := aloca.NewCall(malloc, aloca.NewAdd(aloca.NewAdd(aloca.NewCall(strlen, t), diferenca), l.i64(1)))
+	aloca.NewStore(t, posicao)
+	aloca.NewStore(resultado, destino)
+	aloca.NewBr(buscaCopia)
+
+	// Segunda passada: copia o trecho antes de cada ocorrência seguido de novo
+	origem := buscaCopia.NewLoad(ptrI8, posicao)
+	saida := buscaCopia.NewLoad(ptrI8, destino)
+	proxima := buscaCopia.NewCall(strstr, origem, antigo)
+	buscaCopia.NewCondBr(buscaCopia.NewICmp(enum.IPredNE, proxima, constant.NewNull(ptrI8)), achouCopia, fim)
+
+	antes := achouCopia.NewSub(achouCopia.NewPtrToInt(proxima, types.I64), achouCopia.NewPtrToInt(origem, types.I64))
+	achouCopia.NewCall(memcpy, saida, origem, antes)
+	depoisAntes := achouCopia.NewGetElementPtr(types.I8, saida, antes)
+	achouCopia.NewCall(memcpy, depoisAntes, novo, tamNovo)
+	achouCopia.NewStore(achouCopia.NewGetElementPtr(types.I8, depoisAntes, tamNovo), destino)
+	achouCopia.NewStore(achouCopia.NewGetElementPtr(types.I8, proxima, tamAntigo), posicao)
+	achouCopia.NewBr(buscaCopia)
+
+	// Resto do texto, com o \0
+	fim.NewCall(strcpy, saida, origem)
+	fim.NewRet(resultado)
+	return f
+}
+
+// rotinaDivide gera solar_texto_divide(t, separador): a lista dos trechos de t entre as
+// ocorrências do separador, como strings.Split; com separador vazio, um trecho por
+// caractere UTF-8 (byte inicial seguido dos bytes de continuação)
+func (l *LLVMBackend) rotinaDivide() *ir.Func {
+	t, separador := ir.NewParam("t", ptrI8), ir.NewParam("separador", ptrI8)
+	f, nova := l.novaRotina("texto_divide", ptrLista, t, separador)
+	if !nova {
+		return f
+	}
+	strlen := l.funcaoExterna("strlen", types.I64, false, ptrI8)
+	strstr := l.funcaoExterna("strstr", ptrI8, false, ptrI8, ptrI8)
+	malloc := l.funcaoExterna("malloc", ptrI8, false, types.I64)
+	memcpy := l.funcaoExterna("memcpy", ptrI8, false, ptrI8, ptrI8, types.I64)
+	anexa := l.rotinaAnexa()
+
+	prevFunc, prevBlock := l.function, l.block
+	defer func() { l.function, l.block = prevFunc, prevBlock }()
+	l.function = f
+
+	entrada, busca, caractere, continuacao, fim := f.NewBlock("entry"), f.NewBlock("busca"), f.NewBlock("caractere"), f.NewBlock("continuacao"), f.NewBlock("fim")
+	buscaFim, proximo, trecho := f.NewBlock("busca_fim"), f.NewBlock("proximo"), f.NewBlock("trecho")
+
+	// Anexa à lista uma cópia dos bytes em [inicio, final)
+	var lista value.Value
+	anexarTrecho := func(b *ir.Block, inicio, final value.Value) {
+		n := b.NewSub(b.NewPtrToInt(final, types.I64), b.NewPtrToInt(inicio, types.I64))
+		copia := b.NewCall(malloc, b.NewAdd(n, l.i64(1)))
+		b.NewCall(memcpy, copia, inicio, n)
+		b.NewStore(constant.NewInt(types.I8, 0), b.NewGetElementPtr(types.I8, copia, n))
+		b.NewStore(copia, b.NewBitCast(b.NewCall(anexa, lista), types.NewPointer(ptrI8)))
+	}
+
+	l.block = entrada
+	lista = entrada.NewBitCast(entrada.NewCall(malloc, l.i64(24)), ptrLista)
+	entrada.NewStore(l.i64(0), l.campo(lista, campoTamanho))
+	entrada.NewStore(l.i64(0), l.campo(lista, campoCapacidade))
+	entrada.NewStore(constant.NewNull(ptrI8), l.campo(lista, campoDados))
+	posicao := entrada.NewAlloca(ptrI8)
+	final := entrada.NewAlloca(ptrI8)
+	entrada.NewStore(t, posicao)
+	tamSeparador := entrada.NewCall(strlen, separador)
+	entrada.NewCondBr(entrada.NewICmp(enum.IPredEQ, tamSeparador, l.i64(0)), caractere, busca)
+
+	// Trecho até a próxima ocorrência (ou até o fim do texto, encerrando)
+	inicio := busca.NewLoad(ptrI8, posicao)
+	achado := busca.NewCall(strstr, inicio, separador)
+	busca.NewCondBr(busca.NewICmp(enum.IPredEQ, achado, constant.NewNull(ptrI8)), buscaFim, proximo)
+
+	anexarTrecho(buscaFim, inicio, buscaFim.NewGetElementPtr(types.I8, inicio, buscaFim.NewCall(strlen, inicio)))
+	buscaFim.NewBr(fim)
+
+	anexarTrecho(proximo, inicio, achado)
+	proximo.NewStore(proximo.NewGetElementPtr(types.I8, achado, tamSeparador), posicao)
+	proximo.NewBr(busca)
+
+	// Separador vazio: um trecho por caractere
+	atual := caractere.NewLoad(ptrI8, posicao)
+	caractere.NewStore(caractere.NewGetElementPtr(types.I8, atual, l.i64(1)), final)
+	fimTexto := caractere.NewICmp(enum.IPredEQ, caractere.NewLoad(types.I8, atual), constant.NewInt(types.I8, 0))
+	caractere.NewCondBr(fimTexto, fim, continuacao)
+
+	depois := continuacao.NewLoad(ptrI8, final)
+	byteSeguinte := continuacao.NewLoad(types.I8, depois)
+	ehContinuacao := continuacao.NewICmp(enum.IPredEQ, continuacao.NewAnd(byteSeguinte, constant.NewInt(types.I8, -64)), constant.NewInt(types.I8, -128))
+	continuacao.NewStore(continuacao.NewGetElementPtr(types.I8, depois, l.i64(1)), final)
+	continuacao.NewCondBr(ehContinuacao, continuacao, trecho)
+
+	// O último byte lido não pertence ao caractere
+	fimCaractere := trecho.NewGetElementPtr(types.I8, trecho.NewLoad(ptrI8, final), l.i64(-1))
+	anexarTrecho(trecho, atual, fimCaractere)
+	trecho.NewStore(fimCaractere, posicao)
+	trecho.NewBr(caractere)
+
+	fim.NewRet(lista)
+	return f
+}
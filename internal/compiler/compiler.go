@@ -27,6 +27,8 @@ type Compiler struct {
 	parser         *parser.Parser
 	moduleResolver *ModuleResolver
 	prelude        *prelude.Prelude
+	tipos          map[parser.Expressao]parser.Tipo // tipos inferidos na checagem
+	declarados     *parser.TiposDeclarados          // registros e enumerações da checagem
 	debug          bool
 }
 
@@ -115,6 +117,11 @@ Backends disponíveis:
 		fmt.Printf("Backend selecionado: %s\n\n", backend.GetName())
 	}
 
+	// Backends que geram código dependente de tipo recebem os tipos e as declarações da checagem
+	if tipado, ok := backend.(backends.BackendTipado); ok {
+		tipado.DefinirTipos(c.tipos, c.declarados)
+	}
+
 	return backend.Compile(statements)
 }
 
@@ -129,7 +136,7 @@ func (c *Compiler) tokenizar(conteudo string) ([]lexer.Token, error) {
 }
 
 func (c *Compiler) analisarSintaxe(tokens []lexer.Token) ([]parser.Expressao, error) {
-	c.parser = parser.NovoParser(tokens)
+	c.parser = parser.NovoParserComImportacoes(tokens, c.moduleResolver.EhTipoExportado)
 	statements, err := c.parser.AnalisarPrograma()
 	if err != nil {
 		if c.debug {
@@ -140,7 +147,10 @@ func (c *Compiler) analisarSintaxe(tokens []lexer.Token) ([]parser.Expressao, er
 	return statements, nil
 }
 
-// processarImports resolve e incorpora módulos importados
+// processarImports resolve e incorpora módulos importados.
+// Os nós importados são posicionados antes dos statements do programa, na ordem
+// em que aparecem no módulo de origem, para que a inicialização de globais
+// importadas aconteça antes da inicialização das globais do próprio programa.
 func (c *Compiler) processarImports(statements []parser.Expressao) ([]parser.Expressao, error) {
 	var novosStatements []parser.Expressao
 	var importsEncontrados []*parser.Importacao
@@ -154,6 +164,18 @@ func (c *Compiler) processarImports(statements []parser.Expressao) ([]parser.Exp
 		}
 	}
 
+	var importados []parser.Expressao
+	jaImportados := make(map[parser.Expressao]bool)
+
+	// Globais, funções e tipos do programa, que as dependências dos módulos não podem redeclarar
+	declaradosPrograma := make(map[string]bool)
+	for _, stmt := range novosStatements {
+		if nome := nomeDeclarado(stmt); nome != "" {
+			declaradosPrograma[nome] = true
+		}
+	}
+	origemDependencias := make(map[string]string)
+
 	// Processa cada import
 	for _, imp := range importsEncontrados {
 		if c.debug {
@@ -161,21 +183,22 @@ func (c *Compiler) processarImports(statements []parser.Expressao) ([]parser.Exp
 		}
 
 		// Resolve o módulo
-		_, err := c.moduleResolver.ResolverModulo(imp.Modulo)
+		modulo, err := c.moduleResolver.ResolverModulo(imp.Modulo)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao resolver módulo '%s': %v", imp.Modulo, err)
 		}
 
-		// Valida e incorpora os símbolos solicitados
+		// Valida os símbolos solicitados
+		solicitados := make(map[parser.Expressao]bool)
 		for _, simbolo := range imp.Simbolos {
 			sim, err := c.moduleResolver.ResolverSimbolo(imp.Modulo, simbolo)
 			if err != nil {
 				return nil, fmt.Errorf("erro ao resolver símbolo '%s' do módulo '%s': %v", simbolo, imp.Modulo, err)
 			}
 
-			// Adiciona o nó AST do símbolo importado (apenas se não for built-in)
+			// Apenas símbolos com nó AST são incorporados (built-ins não têm nó)
 			if sim.Node != nil {
-				novosStatements = append(novosStatements, sim.Node)
+				solicitados[sim.Node] = true
 			}
 
 			if c.debug {
@@ -186,13 +209,40 @@ func (c *Compiler) processarImports(statements []parser.Expressao) ([]parser.Exp
 				fmt.Printf("  Símbolo '%s' importado com sucesso (%s)\n", simbolo, tipoStr)
 			}
 		}
+
+		// Os símbolos trazem junto as globais, funções, registros e enumerações do módulo
+		// que usam: a inicialização dessas globais roda antes da do programa
+		incluidos := dependenciasModulo(modulo, solicitados)
+
+		// Incorpora os nós na ordem do código fonte do módulo
+		for _, node := range modulo.AST {
+			if !incluidos[node] || jaImportados[node] {
+				continue
+			}
+			if nome := nomeDeclarado(node); nome != "" && !solicitados[node] {
+				if declaradosPrograma[nome] {
+					return nil, fmt.Errorf("'%s' do módulo '%s', usado pelos símbolos importados, também é declarado no programa", nome, imp.Modulo)
+				}
+				if origem, existe := origemDependencias[nome]; existe && origem != imp.Modulo {
+					return nil, fmt.Errorf("'%s', usado pelos símbolos importados, é declarado nos módulos '%s' e '%s'", nome, origem, imp.Modulo)
+				}
+				origemDependencias[nome] = imp.Modulo
+			}
+			importados = append(importados, node)
+			jaImportados[node] = true
+		}
 	}
 
-	return novosStatements, nil
+	return append(importados, novosStatements...), nil
 }
 
 // checagemTipos executa a validação de tipos sobre a AST
 func (c *Compiler) checagemTipos(statements []parser.Expressao) error {
 	tc := NovoTypeChecker()
-	return tc.Check(statements)
+	if err := tc.Check(statements); err != nil {
+		return err
+	}
+	c.tipos = tc.Tipos()
+	c.declarados = tc.Declarados()
+	return nil
 }
@@ -22,10 +22,10 @@ type ModuloCarregado struct {
 	Nome     string
 	Caminho  string
 	AST      []parser.Expressao
-	Simbolos map[string]*SimboloExportado // funções/variáveis exportadas
+	Simbolos map[string]*SimboloExportado // funções/variáveis/registros exportados
 }
 
-// SimboloExportado representa uma função ou variável exportada de um módulo
+// SimboloExportado representa uma função, variável ou tipo registro exportado de um módulo
 type SimboloExportado struct {
 	Nome string
 	Tipo TipoSimbolo
@@ -37,7 +37,9 @@ type TipoSimbolo int
 const (
 	SIMBOLO_FUNCAO TipoSimbolo = iota
 	SIMBOLO_VARIAVEL
-	SIMBOLO_BUILTIN // símbolos especiais implementados pelo compilador
+	SIMBOLO_REGISTRO   // tipos registro; importados pelo nome como funções e variáveis
+	SIMBOLO_ENUMERACAO // enumerações; importar o tipo traz todas as suas variantes
+	SIMBOLO_BUILTIN    // símbolos especiais implementados pelo compilador
 )
 
 // NewModuleResolver cria um novo resolvedor de módulos
@@ -142,7 +144,7 @@ func (mr *ModuleResolver) carregarModulo(nomeModulo, caminhoArquivo string) (*Mo
 	}, nil
 }
 
-// extrairSimbolosExportados identifica funções e variáveis que podem ser exportadas
+// extrairSimbolosExportados identifica funções, variáveis, registros e enumerações que podem ser exportados
 func (mr *ModuleResolver) extrairSimbolosExportados(ast []parser.Expressao) map[string]*SimboloExportado {
 	simbolos := make(map[string]*SimboloExportado)
 
@@ -161,6 +163,18 @@ func (mr *ModuleResolver) extrairSimbolosExportados(ast []parser.Expressao) map[
 				Tipo: SIMBOLO_VARIAVEL,
 				Node: expr,
 			}
+		case *parser.RegistroDeclaracao:
+			simbolos[node.Nome] = &SimboloExportado{
+				Nome: node.Nome,
+				Tipo: SIMBOLO_REGISTRO,
+				Node: expr,
+			}
+		case *parser.EnumeracaoDeclaracao:
+			simbolos[node.Nome] = &SimboloExportado{
+				Nome: node.Nome,
+				Tipo: SIMBOLO_ENUMERACAO,
+				Node: expr,
+			}
 		}
 	}
 
@@ -195,6 +209,17 @@ func (mr *ModuleResolver) ResolverSimbolo(nomeModulo, nomeSimbolo string) (*Simb
 	return simbolo, nil
 }
 
+// EhTipoExportado informa se o módulo exporta o símbolo como registro ou enumeração
+// (implementa parser.TipoImportado); módulos ou símbolos inexistentes são reportados
+// depois, ao processar as importações
+func (mr *ModuleResolver) EhTipoExportado(nomeModulo, nomeSimbolo string) bool {
+	simbolo, err := mr.ResolverSimbolo(nomeModulo, nomeSimbolo)
+	if err != nil {
+		return false
+	}
+	return simbolo.Tipo == SIMBOLO_REGISTRO || simbolo.Tipo == SIMBOLO_ENUMERACAO
+}
+
 // ListarSimbolosModulo retorna todos os símbolos disponíveis em um módulo
 func (mr *ModuleResolver) ListarSimbolosModulo(nomeModulo string) ([]string, error) {
 	modulo, err := mr.ResolverModulo(nomeModulo)
@@ -219,3 +244,153 @@ func (mr *ModuleResolver) AdicionarCaminhoBusca(caminho string) {
 	}
 	mr.caminhosBusca = append(mr.caminhosBusca, caminho)
 }
+
+// dependenciasModulo completa os nós solicitados com as globais, as funções, os registros
+// e as enumerações de nível superior do módulo que eles usam, direta ou indiretamente
+func dependenciasModulo(modulo *ModuloCarregado, solicitados map[parser.Expressao]bool) map[parser.Expressao]bool {
+	declaracoes := make(map[string][]parser.Expressao) // nome -> nós que declaram ou atribuem
+	for _, node := range modulo.AST {
+		if nome := nomeDeclarado(node); nome != "" {
+			declaracoes[nome] = append(declaracoes[nome], node)
+		}
+		// Variantes são usadas pelo nome e trazem a enumeração
+		if enumeracao, ok := node.(*parser.EnumeracaoDeclaracao); ok {
+			for _, variante := range enumeracao.Variantes {
+				declaracoes[variante] = append(declaracoes[variante], node)
+			}
+		}
+	}
+
+	incluidos := make(map[parser.Expressao]bool)
+	var pendentes []parser.Expressao
+	for node := range solicitados {
+		pendentes = append(pendentes, node)
+	}
+	for len(pendentes) > 0 {
+		node := pendentes[0]
+		pendentes = pendentes[1:]
+		if incluidos[node] {
+			continue
+		}
+		incluidos[node] = true
+
+		nomes := make(map[string]bool)
+		nomesReferenciados(node, nomes)
+		for nome := range nomes {
+			pendentes = append(pendentes, declaracoes[nome]...)
+		}
+	}
+	return incluidos
+}
+
+// nomeDeclarado retorna o nome da função, da global (declarada ou atribuída), do registro
+// ou da enumeração de um comando de nível superior; vazio para os demais comandos
+func nomeDeclarado(node parser.Expressao) string {
+	switch n := node.(type) {
+	case *parser.FuncaoDeclaracao:
+		return n.Nome
+	case *parser.Atribuicao:
+		return n.Nome
+	case *parser.RegistroDeclaracao:
+		return n.Nome
+	case *parser.EnumeracaoDeclaracao:
+		return n.Nome
+	}
+	return ""
+}
+
+// nomesDoTipo coleta os registros e enumerações usados em um tipo
+func nomesDoTipo(tp parser.Tipo, nomes map[string]bool) {
+	switch {
+	case tp.EhLista():
+		nomesDoTipo(tp.Elemento(), nomes)
+	case tp.EhMapa():
+		nomesDoTipo(tp.Chave(), nomes)
+		nomesDoTipo(tp.Valor(), nomes)
+	case tp.EhNomeado():
+		nomes[tp.String()] = true
+	}
+}
+
+// nomesReferenciados coleta os nomes de variáveis, funções e tipos usados em e. Não
+// distingue locais de globais: um local com o nome de uma global do módulo também a inclui
+func nomesReferenciados(e parser.Expressao, nomes map[string]bool) {
+	visitar := func(filhos ...parser.Expressao) {
+		for _, filho := range filhos {
+			if filho != nil {
+				nomesReferenciados(filho, nomes)
+			}
+		}
+	}
+
+	switch n := e.(type) {
+	case *parser.Variavel:
+		nomes[n.Nome] = true
+	case *parser.Atribuicao:
+		nomes[n.Nome] = true
+		if n.TipoAnotado != nil {
+			nomesDoTipo(*n.TipoAnotado, nomes)
+		}
+		visitar(n.Valor)
+	case *parser.ChamadaFuncao:
+		nomes[n.Nome] = true
+		visitar(n.Argumentos...)
+	case *parser.TextoInterpolado:
+		visitar(n.Partes...)
+	case *parser.OperacaoBinaria:
+		visitar(n.OperandoEsquerdo, n.OperandoDireito)
+	case *parser.OperacaoUnaria:
+		visitar(n.Operando)
+	case *parser.ListaLiteral:
+		visitar(n.Elementos...)
+	case *parser.MapaLiteral:
+		visitar(n.Chaves...)
+		visitar(n.Valores...)
+	case *parser.Indexacao:
+		visitar(n.Colecao, n.Indice)
+	case *parser.AtribuicaoIndice:
+		visitar(n.Colecao, n.Indice, n.Valor)
+	case *parser.RegistroLiteral:
+		nomes[n.Nome] = true
+		visitar(n.Valores...)
+	case *parser.RegistroDeclaracao:
+		for _, campo := range n.Campos {
+			nomesDoTipo(campo.Tipo, nomes)
+		}
+	case *parser.AcessoCampo:
+		visitar(n.Objeto)
+	case *parser.AtribuicaoCampo:
+		visitar(n.Objeto, n.Valor)
+	case *parser.Escolha:
+		visitar(n.Valor)
+		for _, caso := range n.Casos {
+			visitar(caso.Padrao, caso.Corpo)
+		}
+	case *parser.ComandoSe:
+		visitar(n.Condicao, n.BlocoSe)
+		for _, ramo := range n.SenaoSe {
+			visitar(ramo.Condicao, ramo.Bloco)
+		}
+		if n.BlocoSenao != nil {
+			visitar(n.BlocoSenao)
+		}
+	case *parser.Bloco:
+		visitar(n.Comandos...)
+	case *parser.ComandoEnquanto:
+		visitar(n.Condicao, n.Corpo)
+	case *parser.ComandoPara:
+		visitar(n.Inicializacao, n.Condicao, n.PosIteracao, n.Corpo)
+	case *parser.ComandoParaCada:
+		visitar(n.Colecao, n.Corpo)
+	case *parser.ComandoParaIntervalo:
+		visitar(n.Inicio, n.Fim, n.Passo, n.Corpo)
+	case *parser.FuncaoDeclaracao:
+		for _, parametro := range n.Parametros {
+			nomesDoTipo(parametro.Tipo, nomes)
+		}
+		nomesDoTipo(n.Retorno, nomes)
+		visitar(n.Corpo)
+	case *parser.Retorno:
+		visitar(n.Valor)
+	}
+}
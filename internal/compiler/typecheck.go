@@ -2,9 +2,12 @@ package compiler
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/khevencolino/Solar/internal/parser"
 	"github.com/khevencolino/Solar/internal/prelude"
+	"github.com/khevencolino/Solar/internal/registry"
 )
 
 // TypeChecker realiza inferência e checagem real de tipos
@@ -12,14 +15,34 @@ type TypeChecker struct {
 	scopes       []map[string]parser.Tipo
 	funcs        map[string]*funcSig
 	funcRetStack []parser.Tipo
+	lacos        []string // rótulos dos laços envolventes ("" para laço sem rótulo)
 	builtins     map[string]builtinSig
 	prelude      *prelude.Prelude
+	tipos        map[parser.Expressao]parser.Tipo // tipo inferido de cada nó, usado pelos backends
+	variantes    map[string]parser.Tipo           // variante de enumeração -> tipo da enumeração
+	declarados   *parser.TiposDeclarados          // campos dos registros e variantes das enumerações
+
+	// Ordem de inicialização das globais: uma função chamada no nível superior não pode
+	// usar uma global que só é atribuída naquele comando ou depois dele
+	funcAtual       *funcSig        // função cujo corpo está sendo checado (nil no nível superior)
+	instrucao       int             // índice do comando de nível superior sendo checado
+	inicioGlobal    map[string]int  // global -> índice do comando que a declara
+	chamadasGlobais []chamadaGlobal // chamadas de funções do usuário feitas no nível superior
 }
 
 type funcSig struct {
-	name   string
-	params []parser.ParametroFuncao
-	ret    parser.Tipo
+	name    string
+	params  []parser.ParametroFuncao
+	ret     parser.Tipo
+	globais map[string]bool // globais lidas ou atribuídas no corpo
+	chamam  map[string]bool // funções do usuário chamadas no corpo
+}
+
+// chamadaGlobal é uma chamada de função do usuário feita por um comando de nível superior
+type chamadaGlobal struct {
+	funcao    string
+	instrucao int
+	linha     int
 }
 
 type builtinSig struct {
@@ -37,30 +60,249 @@ func NovoTypeChecker() *TypeChecker {
 		funcs:        make(map[string]*funcSig),
 		funcRetStack: []parser.Tipo{},
 		prelude:      prelude.NewPrelude(),
+		tipos:        make(map[parser.Expressao]parser.Tipo),
+		variantes:    make(map[string]parser.Tipo),
+		declarados:   parser.NovosTiposDeclarados(),
+		inicioGlobal: make(map[string]int),
 		builtins: map[string]builtinSig{
 			// Mantém apenas builtins que não são do prelude
 			"soma": {params: []parser.Tipo{parser.TipoInteiro}, varargs: true, minArgs: 2, ret: parser.TipoInteiro},
 		},
 	}
+	// Builtins do registro com assinatura tipada (implementadas no runtime); as que
+	// só existem para listas ou mapas são checadas por inferirBuiltinColecao
+	for _, nome := range registry.RegistroGlobal.ListarFuncoes() {
+		assinatura, _ := registry.RegistroGlobal.ObterAssinatura(nome)
+		if assinatura.TipoFuncao != registry.FUNCAO_RUNTIME || assinatura.Rotina == "" {
+			continue
+		}
+		sig := builtinSig{minArgs: assinatura.MinArgumentos, ret: tipoRegistro(assinatura.TipoRetorno)}
+		for _, tp := range assinatura.TiposArgumento {
+			sig.params = append(sig.params, tipoRegistro(tp))
+		}
+		tc.builtins[nome] = sig
+	}
 	return tc
 }
 
+// tipoRegistro converte um tipo de argumento do registro de builtins para o tipo da linguagem
+func tipoRegistro(tp registry.TipoArgumento) parser.Tipo {
+	switch tp {
+	case registry.TIPO_DECIMAL:
+		return parser.TipoDecimal
+	case registry.TIPO_TEXTO:
+		return parser.TipoTexto
+	case registry.TIPO_BOOLEANO:
+		return parser.TipoBooleano
+	case registry.TIPO_VAZIO:
+		return parser.TipoVazio
+	case registry.TIPO_LISTA_TEXTO:
+		return parser.TipoLista(parser.TipoTexto)
+	default:
+		return parser.TipoInteiro
+	}
+}
+
 func (t *TypeChecker) Check(stmts []parser.Expressao) error {
+	// Registros e enumerações vêm antes de tudo: assinaturas e anotações podem usá-los
+	if err := t.declararEnumeracoes(stmts); err != nil {
+		return err
+	}
+	if err := t.declararRegistros(stmts); err != nil {
+		return err
+	}
+
 	// Primeira passada: coletar assinaturas de funções de nível superior
 	for _, s := range stmts {
 		if fn, ok := s.(*parser.FuncaoDeclaracao); ok {
-			sig := &funcSig{name: fn.Nome, ret: fn.Retorno}
+			for _, param := range fn.Parametros {
+				if err := t.checkTipoDeclarado(param.Tipo); err != nil {
+					return fmt.Errorf("parâmetro '%s' de '%s': %v", param.Nome, fn.Nome, err)
+				}
+			}
+			if err := t.checkTipoDeclarado(fn.Retorno); err != nil {
+				return fmt.Errorf("retorno de '%s': %v", fn.Nome, err)
+			}
+			sig := &funcSig{name: fn.Nome, ret: fn.Retorno, globais: make(map[string]bool), chamam: make(map[string]bool)}
 			sig.params = append(sig.params, fn.Parametros...)
 			t.funcs[fn.Nome] = sig
 		}
 	}
 
-	// Checar statements top-level
-	for _, s := range stmts {
+	// Checar statements top-level: as globais são inicializadas antes de qualquer
+	// função ser executada, então os corpos das funções são checados por último
+	for i, s := range stmts {
+		if _, ok := s.(*parser.FuncaoDeclaracao); ok {
+			continue
+		}
+		t.instrucao = i
 		if _, err := t.inferirExpr(s); err != nil {
 			return err
 		}
 	}
+	for _, s := range stmts {
+		if fn, ok := s.(*parser.FuncaoDeclaracao); ok {
+			if _, err := t.checkFuncDecl(fn); err != nil {
+				return err
+			}
+		}
+	}
+	return t.checkOrdemInicializacao()
+}
+
+// checkOrdemInicializacao rejeita chamadas de nível superior a funções que usam (direta
+// ou indiretamente) uma global ainda não inicializada no momento da chamada: o
+// interpretador não conhece a global e os backends compilados leriam o zero da .data
+func (t *TypeChecker) checkOrdemInicializacao() error {
+	for _, chamada := range t.chamadasGlobais {
+		visitadas := map[string]bool{chamada.funcao: true}
+		pendentes := []string{chamada.funcao}
+		for len(pendentes) > 0 {
+			sig := t.funcs[pendentes[0]]
+			pendentes = pendentes[1:]
+			globais := make([]string, 0, len(sig.globais))
+			for nome := range sig.globais {
+				globais = append(globais, nome)
+			}
+			sort.Strings(globais)
+			for _, nome := range globais {
+				if inicio, ok := t.inicioGlobal[nome]; ok && inicio >= chamada.instrucao {
+					return fmt.Errorf("chamada de '%s' na linha %d usa a variável global '%s' (em '%s') antes da sua inicialização", chamada.funcao, chamada.linha, nome, sig.name)
+				}
+			}
+			for nome := range sig.chamam {
+				if !visitadas[nome] {
+					visitadas[nome] = true
+					pendentes = append(pendentes, nome)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// usarVariavel registra o uso de uma global no corpo da função sendo checada
+func (t *TypeChecker) usarVariavel(nome string) {
+	if t.funcAtual == nil {
+		return
+	}
+	for i := len(t.scopes) - 1; i > 0; i-- {
+		if _, ok := t.scopes[i][nome]; ok {
+			return // local ou parâmetro
+		}
+	}
+	if _, ok := t.scopes[0][nome]; ok {
+		t.funcAtual.globais[nome] = true
+	}
+}
+
+// declararGlobal registra o comando de nível superior que declara a global
+func (t *TypeChecker) declararGlobal(nome string) {
+	if len(t.scopes) > 1 || t.funcAtual != nil {
+		return
+	}
+	if _, ok := t.inicioGlobal[nome]; !ok {
+		t.inicioGlobal[nome] = t.instrucao
+	}
+}
+
+// declararRegistros define os campos dos registros de nível superior e valida as
+// declarações: nomes e campos únicos, tipos dos campos conhecidos e nenhum registro
+// contendo a si mesmo (direta ou indiretamente), o que teria tamanho infinito
+func (t *TypeChecker) declararRegistros(stmts []parser.Expressao) error {
+	var declaracoes []*parser.RegistroDeclaracao
+	for _, s := range stmts {
+		decl, ok := s.(*parser.RegistroDeclaracao)
+		if !ok {
+			continue
+		}
+		tp := parser.TipoNomeado(decl.Nome)
+		if t.declarados.Declarado(tp) {
+			return fmt.Errorf("tipo '%s' declarado mais de uma vez", decl.Nome)
+		}
+		vistos := make(map[string]bool)
+		for _, campo := range decl.Campos {
+			if vistos[campo.Nome] {
+				return fmt.Errorf("campo '%s' repetido no registro '%s'", campo.Nome, decl.Nome)
+			}
+			vistos[campo.Nome] = true
+		}
+		t.declarados.DefinirCampos(tp, decl.Campos)
+		declaracoes = append(declaracoes, decl)
+	}
+	for _, decl := range declaracoes {
+		for _, campo := range decl.Campos {
+			if campo.Tipo == parser.TipoVazio {
+				return fmt.Errorf("campo '%s' do registro '%s' não pode ser vazio", campo.Nome, decl.Nome)
+			}
+			if err := t.checkTipoDeclarado(campo.Tipo); err != nil {
+				return fmt.Errorf("campo '%s' do registro '%s': %v", campo.Nome, decl.Nome, err)
+			}
+		}
+		if caminho := t.cicloRegistro(parser.TipoNomeado(decl.Nome), nil); caminho != nil {
+			return fmt.Errorf("registro '%s' contém a si mesmo (%s); use uma lista para referências recursivas", decl.Nome, strings.Join(caminho, " -> "))
+		}
+	}
+	return nil
+}
+
+// declararEnumeracoes define as variantes das enumerações de nível superior. Variantes
+// são usadas pelo nome (Vermelho), então não podem se repetir nem entre enumerações
+func (t *TypeChecker) declararEnumeracoes(stmts []parser.Expressao) error {
+	for _, s := range stmts {
+		decl, ok := s.(*parser.EnumeracaoDeclaracao)
+		if !ok {
+			continue
+		}
+		tp := parser.TipoNomeado(decl.Nome)
+		if t.declarados.Declarado(tp) {
+			return fmt.Errorf("tipo '%s' declarado mais de uma vez", decl.Nome)
+		}
+		for _, variante := range decl.Variantes {
+			if outra, existe := t.variantes[variante]; existe {
+				if outra == tp {
+					return fmt.Errorf("variante '%s' repetida na enumeração '%s'", variante, decl.Nome)
+				}
+				return fmt.Errorf("variante '%s' declarada nas enumerações '%s' e '%s'", variante, outra.String(), decl.Nome)
+			}
+			t.variantes[variante] = tp
+		}
+		t.declarados.DefinirVariantes(tp, decl.Variantes)
+	}
+	return nil
+}
+
+// cicloRegistro procura, a partir do registro, um caminho de campos registro que volte
+// a um registro do caminho; retorna os nomes do ciclo ou nil
+func (t *TypeChecker) cicloRegistro(tp parser.Tipo, caminho []string) []string {
+	for _, nome := range caminho {
+		if nome == tp.String() {
+			return append(caminho, tp.String())
+		}
+	}
+	caminho = append(caminho, tp.String())
+	for _, campo := range t.declarados.Campos(tp) {
+		if !t.declarados.EhRegistro(campo.Tipo) {
+			continue
+		}
+		if ciclo := t.cicloRegistro(campo.Tipo, caminho); ciclo != nil {
+			return ciclo
+		}
+	}
+	return nil
+}
+
+// checkTipoDeclarado verifica se os registros e enumerações usados no tipo (também como
+// elemento de lista ou valor de mapa) foram declarados
+func (t *TypeChecker) checkTipoDeclarado(tp parser.Tipo) error {
+	switch {
+	case tp.EhLista():
+		return t.checkTipoDeclarado(tp.Elemento())
+	case tp.EhMapa():
+		return t.checkTipoDeclarado(tp.Valor())
+	case tp.EhNomeado() && !t.declarados.Declarado(tp):
+		return fmt.Errorf("tipo '%s' não declarado", tp.String())
+	}
 	return nil
 }
 
@@ -99,7 +341,22 @@ func (t *TypeChecker) setVar(nome string, tp parser.Tipo) {
 }
 
 // Inferência e checagem
+// Tipos retorna o tipo inferido de cada expressão checada
+func (t *TypeChecker) Tipos() map[parser.Expressao]parser.Tipo { return t.tipos }
+
+// Declarados retorna os registros e as enumerações declarados no programa checado
+func (t *TypeChecker) Declarados() *parser.TiposDeclarados { return t.declarados }
+
+// inferirExpr infere o tipo da expressão e o registra para os backends
 func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
+	tp, err := t.inferirNo(e)
+	if err == nil {
+		t.tipos[e] = tp
+	}
+	return tp, err
+}
+
+func (t *TypeChecker) inferirNo(e parser.Expressao) (parser.Tipo, error) {
 	switch n := e.(type) {
 	case *parser.Constante:
 		return parser.TipoInteiro, nil
@@ -107,11 +364,28 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		return parser.TipoBooleano, nil
 	case *parser.LiteralTexto:
 		return parser.TipoTexto, nil
+	case *parser.TextoInterpolado:
+		// Qualquer valor pode ser interpolado; o texto usa a mesma formatação de imprime
+		for _, parte := range n.Partes {
+			tp, err := t.inferirExpr(parte)
+			if err != nil {
+				return 0, err
+			}
+			if tp == parser.TipoVazio {
+				return 0, fmt.Errorf("interpolação de expressão sem valor (vazio): %s", parte.String())
+			}
+		}
+		return parser.TipoTexto, nil
 	case *parser.LiteralDecimal:
 		return parser.TipoDecimal, nil
 
 	case *parser.Variavel:
 		if tp, ok := t.getVar(n.Nome); ok {
+			t.usarVariavel(n.Nome)
+			return tp, nil
+		}
+		// Sem variável com o nome: pode ser uma variante de enumeração
+		if tp, ok := t.variantes[n.Nome]; ok {
 			return tp, nil
 		}
 		return 0, fmt.Errorf("variável '%s' não declarada", n.Nome)
@@ -124,25 +398,129 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		}
 		if n.TipoAnotado != nil {
 			// Declaração com tipo explícito (permite shadowing)
-			if !t.mesmoTipo(*n.TipoAnotado, vtp) {
+			if err := t.checkTipoDeclarado(*n.TipoAnotado); err != nil {
+				return 0, fmt.Errorf("variável '%s': %v", n.Nome, err)
+			}
+			if !t.aceitaValor(*n.TipoAnotado, n.Valor, vtp) {
 				return 0, fmt.Errorf("atribuição incompatível: variável '%s' anotada como %s, valor é %s", n.Nome, n.TipoAnotado.String(), vtp.String())
 			}
 			t.setVarLocal(n.Nome, *n.TipoAnotado)
+			t.declararGlobal(n.Nome)
 			return *n.TipoAnotado, nil
 		}
 		// Sem anotação: pode ser reatribuição ou nova declaração
 		if existingType, exists := t.getVar(n.Nome); exists {
+			t.usarVariavel(n.Nome)
 			// Reatribuição - deve ser compatível com o tipo existente
-			if !t.mesmoTipo(existingType, vtp) {
+			if !t.aceitaValor(existingType, n.Valor, vtp) {
 				return 0, fmt.Errorf("reatribuição incompatível: variável '%s' é %s, valor é %s", n.Nome, existingType.String(), vtp.String())
 			}
-			t.setVar(n.Nome, vtp)
-			return vtp, nil
+			t.setVar(n.Nome, existingType)
+			return existingType, nil
 		}
 		// Nova declaração no escopo atual
+		if t.tipoIncompleto(vtp) {
+			if vtp.EhMapa() {
+				return 0, fmt.Errorf("mapa vazio precisa de anotação de tipo: %s: mapa<chave, valor> ~> {}", n.Nome)
+			}
+			return 0, fmt.Errorf("lista vazia precisa de anotação de tipo: %s: lista<tipo> ~> []", n.Nome)
+		}
 		t.setVarLocal(n.Nome, vtp)
+		t.declararGlobal(n.Nome)
 		return vtp, nil
 
+	case *parser.ListaLiteral:
+		// O primeiro elemento com tipo completo define o tipo dos demais; listas
+		// vazias ([]) ficam sem tipo de elemento até o contexto defini-lo
+		if len(n.Elementos) == 0 {
+			return parser.TipoLista(parser.TipoVazio), nil
+		}
+		tipos := make([]parser.Tipo, len(n.Elementos))
+		for i, e := range n.Elementos {
+			et, err := t.inferirExpr(e)
+			if err != nil {
+				return 0, err
+			}
+			if et == parser.TipoVazio {
+				return 0, fmt.Errorf("elemento de lista sem valor: %s", e.String())
+			}
+			tipos[i] = et
+		}
+		elemento := tipos[0]
+		for _, et := range tipos {
+			if !t.tipoIncompleto(et) {
+				elemento = et
+				break
+			}
+		}
+		for i, e := range n.Elementos {
+			if !t.aceitaValor(elemento, e, tipos[i]) {
+				return 0, fmt.Errorf("elemento %d da lista incompatível: esperado %s, recebeu %s", i+1, elemento.String(), tipos[i].String())
+			}
+		}
+		return parser.TipoLista(elemento), nil
+
+	case *parser.MapaLiteral:
+		return t.inferirMapaLiteral(n)
+
+	case *parser.Indexacao:
+		return t.inferirIndexacao(n.Colecao, n.Indice)
+
+	case *parser.AtribuicaoIndice:
+		elemento, err := t.inferirIndexacao(n.Colecao, n.Indice)
+		if err != nil {
+			return 0, err
+		}
+		vt, err := t.inferirExpr(n.Valor)
+		if err != nil {
+			return 0, err
+		}
+		if !t.aceitaValor(elemento, n.Valor, vt) {
+			return 0, fmt.Errorf("atribuição incompatível: elemento de %s, valor é %s", t.tipos[n.Colecao].String(), vt.String())
+		}
+		// Como na Atribuicao, o valor da expressão é o valor atribuído
+		return elemento, nil
+
+	case *parser.RegistroDeclaracao:
+		// Registros de nível superior já foram declarados no início da checagem
+		if len(t.scopes) > 1 || len(t.funcRetStack) > 0 {
+			return 0, fmt.Errorf("registro '%s' deve ser declarado no nível superior do programa", n.Nome)
+		}
+		return parser.TipoVazio, nil
+
+	case *parser.EnumeracaoDeclaracao:
+		// Enumerações de nível superior já foram declaradas no início da checagem
+		if len(t.scopes) > 1 || len(t.funcRetStack) > 0 {
+			return 0, fmt.Errorf("enumeração '%s' deve ser declarada no nível superior do programa", n.Nome)
+		}
+		return parser.TipoVazio, nil
+
+	case *parser.Escolha:
+		return t.inferirEscolha(n)
+
+	case *parser.RegistroLiteral:
+		return t.inferirRegistroLiteral(n)
+
+	case *parser.AcessoCampo:
+		return t.inferirAcessoCampo(n.Objeto, n.Campo)
+
+	case *parser.AtribuicaoCampo:
+		if !ehLocalAtribuivel(n.Objeto) {
+			return 0, fmt.Errorf("atribuição ao campo '%s' de %s, que não é uma variável, um elemento ou um campo", n.Campo, n.Objeto.String())
+		}
+		campo, err := t.inferirAcessoCampo(n.Objeto, n.Campo)
+		if err != nil {
+			return 0, err
+		}
+		vt, err := t.inferirExpr(n.Valor)
+		if err != nil {
+			return 0, err
+		}
+		if !t.aceitaValor(campo, n.Valor, vt) {
+			return 0, fmt.Errorf("atribuição incompatível: campo '%s' de %s é %s, valor é %s", n.Campo, t.tipos[n.Objeto].String(), campo.String(), vt.String())
+		}
+		return campo, nil
+
 	case *parser.OperacaoBinaria:
 		lt, err := t.inferirExpr(n.OperandoEsquerdo)
 		if err != nil {
@@ -153,7 +531,11 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 			return 0, err
 		}
 		switch n.Operador {
-		case parser.ADICAO, parser.SUBTRACAO, parser.MULTIPLICACAO, parser.DIVISAO, parser.POWER:
+		case parser.ADICAO, parser.SUBTRACAO, parser.MULTIPLICACAO, parser.DIVISAO, parser.RESTO, parser.POWER:
+			// '+' também concatena textos
+			if n.Operador == parser.ADICAO && t.mesmoTipo(lt, parser.TipoTexto) && t.mesmoTipo(rt, parser.TipoTexto) {
+				return parser.TipoTexto, nil
+			}
 			if !(t.ehNumerico(lt) && t.ehNumerico(rt)) {
 				return 0, fmt.Errorf("operador aritmético requer operandos numéricos, recebeu %s e %s", lt.String(), rt.String())
 			}
@@ -162,16 +544,61 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 			}
 			return lt, nil
 		case parser.IGUALDADE, parser.DIFERENCA, parser.MENOR_QUE, parser.MAIOR_QUE, parser.MENOR_IGUAL, parser.MAIOR_IGUAL:
+			if lt.EhLista() || rt.EhLista() {
+				return 0, fmt.Errorf("comparação entre listas não é suportada: %s %s %s", lt.String(), n.Operador.String(), rt.String())
+			}
+			if lt.EhMapa() || rt.EhMapa() {
+				return 0, fmt.Errorf("comparação entre mapas não é suportada: %s %s %s", lt.String(), n.Operador.String(), rt.String())
+			}
+			if t.declarados.EhRegistro(lt) || t.declarados.EhRegistro(rt) {
+				return 0, fmt.Errorf("comparação entre registros não é suportada: %s %s %s; compare os campos", lt.String(), n.Operador.String(), rt.String())
+			}
 			if n.Operador == parser.IGUALDADE || n.Operador == parser.DIFERENCA {
 				if !t.mesmoTipo(lt, rt) {
 					return 0, fmt.Errorf("comparação entre tipos incompatíveis: %s e %s", lt.String(), rt.String())
 				}
 				return parser.TipoBooleano, nil
 			}
-			if !t.mesmoTipo(lt, rt) || !t.ehNumerico(lt) {
-				return 0, fmt.Errorf("comparação relacional requer tipos numéricos iguais, recebeu %s e %s", lt.String(), rt.String())
+			// Textos são comparados em ordem lexicográfica
+			if !t.mesmoTipo(lt, rt) || !(t.ehNumerico(lt) || t.mesmoTipo(lt, parser.TipoTexto)) {
+				return 0, fmt.Errorf("comparação relacional requer tipos numéricos ou textos iguais, recebeu %s e %s", lt.String(), rt.String())
+			}
+			return parser.TipoBooleano, nil
+		case parser.E_LOGICO, parser.OU_LOGICO:
+			if !t.mesmoTipo(lt, parser.TipoBooleano) || !t.mesmoTipo(rt, parser.TipoBooleano) {
+				return 0, fmt.Errorf("operador lógico '%s' requer operandos booleanos, recebeu %s e %s", n.Operador.String(), lt.String(), rt.String())
 			}
 			return parser.TipoBooleano, nil
+		case parser.E_BIT, parser.OU_BIT, parser.XOU_BIT, parser.DESLOCAMENTO_ESQUERDA, parser.DESLOCAMENTO_DIREITA:
+			if !t.mesmoTipo(lt, parser.TipoInteiro) || !t.mesmoTipo(rt, parser.TipoInteiro) {
+				return 0, fmt.Errorf("operador bit a bit '%s' requer operandos inteiros, recebeu %s e %s", n.Operador.String(), lt.String(), rt.String())
+			}
+			return parser.TipoInteiro, nil
+		default:
+			return 0, fmt.Errorf("operador desconhecido")
+		}
+
+	case *parser.OperacaoUnaria:
+		ot, err := t.inferirExpr(n.Operando)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Operador {
+		case parser.NAO_LOGICO:
+			if !t.mesmoTipo(ot, parser.TipoBooleano) {
+				return 0, fmt.Errorf("operador lógico 'nao' requer operando booleano, recebeu %s", ot.String())
+			}
+			return parser.TipoBooleano, nil
+		case parser.NEGACAO:
+			if !t.ehNumerico(ot) {
+				return 0, fmt.Errorf("operador '-' requer operando numérico, recebeu %s", ot.String())
+			}
+			return ot, nil
+		case parser.NAO_BIT:
+			if !t.mesmoTipo(ot, parser.TipoInteiro) {
+				return 0, fmt.Errorf("operador bit a bit '~' requer operando inteiro, recebeu %s", ot.String())
+			}
+			return parser.TipoInteiro, nil
 		default:
 			return 0, fmt.Errorf("operador desconhecido")
 		}
@@ -186,12 +613,23 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 			if fn.MaxArgs != -1 && len(n.Argumentos) > fn.MaxArgs {
 				return 0, fmt.Errorf("função '%s' aceita no máximo %d argumento(s)", n.Nome, fn.MaxArgs)
 			}
+			// Argumentos de qualquer tipo, mas ainda precisam ser válidos
+			for _, arg := range n.Argumentos {
+				if _, err := t.inferirExpr(arg); err != nil {
+					return 0, err
+				}
+			}
 			// Para funções do prelude, assumimos que retornam inteiro
 			return parser.TipoInteiro, nil
 		}
 
 		// Função do usuário?
 		if sig, ok := t.funcs[n.Nome]; ok {
+			if t.funcAtual != nil {
+				t.funcAtual.chamam[n.Nome] = true
+			} else {
+				t.chamadasGlobais = append(t.chamadasGlobais, chamadaGlobal{funcao: n.Nome, instrucao: t.instrucao, linha: n.Token.Position.Line})
+			}
 			if len(n.Argumentos) != len(sig.params) {
 				return 0, fmt.Errorf("função '%s' espera %d argumentos, recebeu %d", sig.name, len(sig.params), len(n.Argumentos))
 			}
@@ -200,12 +638,16 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 				if err != nil {
 					return 0, err
 				}
-				if !t.mesmoTipo(at, sig.params[i].Tipo) {
+				if !t.aceitaValor(sig.params[i].Tipo, arg, at) {
 					return 0, fmt.Errorf("argumento %d de '%s' incompatível: esperado %s, recebeu %s", i+1, sig.name, sig.params[i].Tipo.String(), at.String())
 				}
 			}
 			return sig.ret, nil
 		}
+		// Builtin aplicada a uma lista ou a um mapa?
+		if tp, ok, err := t.inferirBuiltinColecao(n); ok {
+			return tp, err
+		}
 		// Builtin conhecido?
 		if b, ok := t.builtins[n.Nome]; ok {
 			if len(n.Argumentos) < b.minArgs {
@@ -254,6 +696,14 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		if _, err := t.inferirBloco(n.BlocoSe); err != nil {
 			return 0, err
 		}
+		for _, ramo := range n.SenaoSe {
+			if err := t.checkCondicao("senao se", ramo.Condicao); err != nil {
+				return 0, err
+			}
+			if _, err := t.inferirBloco(ramo.Bloco); err != nil {
+				return 0, err
+			}
+		}
 		if n.BlocoSenao != nil {
 			if _, err := t.inferirBloco(n.BlocoSenao); err != nil {
 				return 0, err
@@ -265,6 +715,10 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		if err := t.checkCondicao("enquanto", n.Condicao); err != nil {
 			return 0, err
 		}
+		if err := t.entrarLaco(n.Rotulo); err != nil {
+			return 0, err
+		}
+		defer t.sairLaco()
 		if _, err := t.inferirBloco(n.Corpo); err != nil {
 			return 0, err
 		}
@@ -273,6 +727,10 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 	case *parser.ComandoPara:
 		t.pushScope()
 		defer t.popScope()
+		if err := t.entrarLaco(n.Rotulo); err != nil {
+			return 0, err
+		}
+		defer t.sairLaco()
 		if n.Inicializacao != nil {
 			if _, err := t.inferirExpr(n.Inicializacao); err != nil {
 				return 0, err
@@ -293,6 +751,50 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		}
 		return parser.TipoVazio, nil
 
+	case *parser.ComandoParaCada:
+		ct, err := t.inferirExpr(n.Colecao)
+		if err != nil {
+			return 0, err
+		}
+		if !ct.EhLista() && !ct.EhMapa() {
+			return 0, fmt.Errorf("'para cada' requer uma lista ou um mapa, recebeu %s", ct.String())
+		}
+		if t.tipoIncompleto(ct) {
+			return 0, fmt.Errorf("'para cada' em %s sem tipo definido", colecaoVazia(ct))
+		}
+		variaveis := map[string]parser.Tipo{n.Variavel: ct.Elemento()}
+		if ct.EhMapa() {
+			variaveis[n.Variavel] = ct.Chave()
+			if n.Valor != "" {
+				if n.Valor == n.Variavel {
+					return 0, fmt.Errorf("'para cada': chave e valor não podem ter o mesmo nome '%s'", n.Valor)
+				}
+				variaveis[n.Valor] = ct.Valor()
+			}
+		} else if n.Valor != "" {
+			return 0, fmt.Errorf("'para cada %s, %s' requer um mapa, recebeu %s", n.Variavel, n.Valor, ct.String())
+		}
+		return parser.TipoVazio, t.checkCorpoIteracao(variaveis, n.Rotulo, n.Corpo)
+
+	case *parser.ComandoParaIntervalo:
+		limites := []struct {
+			nome string
+			expr parser.Expressao
+		}{{"início", n.Inicio}, {"fim", n.Fim}, {"passo", n.Passo}}
+		for _, limite := range limites {
+			if limite.expr == nil {
+				continue
+			}
+			lt, err := t.inferirExpr(limite.expr)
+			if err != nil {
+				return 0, err
+			}
+			if !t.mesmoTipo(lt, parser.TipoInteiro) {
+				return 0, fmt.Errorf("%s do intervalo do 'para' deve ser inteiro, recebeu %s", limite.nome, lt.String())
+			}
+		}
+		return parser.TipoVazio, t.checkCorpoIteracao(map[string]parser.Tipo{n.Variavel: parser.TipoInteiro}, n.Rotulo, n.Corpo)
+
 	case *parser.Bloco:
 		return t.inferirBloco(n)
 
@@ -318,16 +820,375 @@ func (t *TypeChecker) inferirExpr(e parser.Expressao) (parser.Tipo, error) {
 		if err != nil {
 			return 0, err
 		}
-		if !t.mesmoTipo(vt, declRet) {
+		if !t.aceitaValor(declRet, n.Valor, vt) {
 			return 0, fmt.Errorf("tipo de retorno incompatível: esperado %s, recebeu %s", declRet.String(), vt.String())
 		}
 		return parser.TipoVazio, nil
 
+	case *parser.Parar:
+		return parser.TipoVazio, t.checkControleLaco("parar", n.Rotulo)
+
+	case *parser.Continuar:
+		return parser.TipoVazio, t.checkControleLaco("continuar", n.Rotulo)
+
 	default:
 		return 0, fmt.Errorf("nó do tipo %T não suportado na checagem de tipos", e)
 	}
 }
 
+// inferirIndexacao checa um acesso a elemento de lista ou a valor de mapa e retorna o
+// tipo do elemento ou do valor
+func (t *TypeChecker) inferirIndexacao(colecao, indice parser.Expressao) (parser.Tipo, error) {
+	ct, err := t.inferirExpr(colecao)
+	if err != nil {
+		return 0, err
+	}
+	if !ct.EhLista() && !ct.EhMapa() {
+		return 0, fmt.Errorf("indexação requer uma lista ou um mapa, recebeu %s", ct.String())
+	}
+	if t.tipoIncompleto(ct) {
+		return 0, fmt.Errorf("indexação de %s sem tipo definido", colecaoVazia(ct))
+	}
+	it, err := t.inferirExpr(indice)
+	if err != nil {
+		return 0, err
+	}
+	if ct.EhMapa() {
+		if !t.mesmoTipo(it, ct.Chave()) {
+			return 0, fmt.Errorf("chave de %s deve ser %s, recebeu %s", ct.String(), ct.Chave().String(), it.String())
+		}
+		return ct.Valor(), nil
+	}
+	if !t.mesmoTipo(it, parser.TipoInteiro) {
+		return 0, fmt.Errorf("índice de lista deve ser inteiro, recebeu %s", it.String())
+	}
+	return ct.Elemento(), nil
+}
+
+// inferirRegistroLiteral checa a construção de um registro: cada campo declarado deve
+// receber um valor do seu tipo, exatamente uma vez
+func (t *TypeChecker) inferirRegistroLiteral(n *parser.RegistroLiteral) (parser.Tipo, error) {
+	tp := parser.TipoNomeado(n.Nome)
+	if !t.declarados.EhRegistro(tp) {
+		return 0, fmt.Errorf("registro '%s' não declarado", n.Nome)
+	}
+	atribuidos := make(map[string]bool)
+	for i, nome := range n.Campos {
+		_, campo, ok := t.declarados.Campo(tp, nome)
+		if !ok {
+			return 0, fmt.Errorf("registro '%s' não tem o campo '%s'", n.Nome, nome)
+		}
+		if atribuidos[nome] {
+			return 0, fmt.Errorf("campo '%s' repetido na construção de '%s'", nome, n.Nome)
+		}
+		atribuidos[nome] = true
+		vt, err := t.inferirExpr(n.Valores[i])
+		if err != nil {
+			return 0, err
+		}
+		if !t.aceitaValor(campo, n.Valores[i], vt) {
+			return 0, fmt.Errorf("campo '%s' de '%s' incompatível: esperado %s, recebeu %s", nome, n.Nome, campo.String(), vt.String())
+		}
+	}
+	var faltando []string
+	for _, campo := range t.declarados.Campos(tp) {
+		if !atribuidos[campo.Nome] {
+			faltando = append(faltando, campo.Nome)
+		}
+	}
+	if len(faltando) > 0 {
+		return 0, fmt.Errorf("construção de '%s' sem valor para: %s", n.Nome, strings.Join(faltando, ", "))
+	}
+	return tp, nil
+}
+
+// inferirEscolha checa uma escolha: os padrões devem ser do tipo do valor (variantes da
+// enumeração, inteiros ou textos literais) e nenhum caso pode ser inalcançável; sobre uma
+// enumeração, todas as variantes precisam ser cobertas (ou haver 'caso _'). O tipo da
+// escolha é o tipo comum dos corpos quando ela cobre todos os valores; senão, vazio
+func (t *TypeChecker) inferirEscolha(n *parser.Escolha) (parser.Tipo, error) {
+	vt, err := t.inferirExpr(n.Valor)
+	if err != nil {
+		return 0, err
+	}
+	if !t.declarados.EhEnumeracao(vt) && !t.mesmoTipo(vt, parser.TipoInteiro) && !t.mesmoTipo(vt, parser.TipoTexto) {
+		return 0, fmt.Errorf("escolha requer inteiro, texto ou enumeração, recebeu %s", vt.String())
+	}
+
+	cobertos := make(map[string]bool)
+	padrao := false // já houve 'caso _'
+	tipos := make([]parser.Tipo, len(n.Casos))
+	for i, caso := range n.Casos {
+		nome := "_"
+		if caso.Padrao != nil {
+			nome = caso.Padrao.String()
+		}
+		if padrao {
+			return 0, fmt.Errorf("caso %s inalcançável: o 'caso _' anterior já cobre todos os valores", nome)
+		}
+		if caso.Padrao == nil {
+			if t.declarados.EhEnumeracao(vt) && len(cobertos) == len(t.declarados.Variantes(vt)) {
+				return 0, fmt.Errorf("caso _ inalcançável: todas as variantes de %s já foram cobertas", vt.String())
+			}
+			padrao = true
+		} else {
+			if err := t.checkPadraoCaso(caso.Padrao, vt); err != nil {
+				return 0, err
+			}
+			if cobertos[nome] {
+				return 0, fmt.Errorf("caso %s inalcançável: já coberto por um caso anterior", nome)
+			}
+			cobertos[nome] = true
+		}
+		if tipos[i], err = t.inferirExpr(caso.Corpo); err != nil {
+			return 0, err
+		}
+	}
+
+	exaustiva := padrao || (t.declarados.EhEnumeracao(vt) && len(cobertos) == len(t.declarados.Variantes(vt)))
+	if t.declarados.EhEnumeracao(vt) && !exaustiva {
+		var faltando []string
+		for _, variante := range t.declarados.Variantes(vt) {
+			if !cobertos[variante] {
+				faltando = append(faltando, variante)
+			}
+		}
+		return 0, fmt.Errorf("escolha não exaustiva sobre %s: faltam %s (ou use 'caso _')", vt.String(), strings.Join(faltando, ", "))
+	}
+	if n.ComoValor && !exaustiva {
+		return 0, fmt.Errorf("escolha usada como valor precisa de 'caso _' para os valores de %s não cobertos", vt.String())
+	}
+	for _, tp := range tipos {
+		if tp == parser.TipoVazio || !t.mesmoTipo(tp, tipos[0]) {
+			if n.ComoValor {
+				return 0, fmt.Errorf("escolha usada como valor: todos os casos devem produzir o mesmo tipo, recebeu %s e %s", tipos[0].String(), tp.String())
+			}
+			return parser.TipoVazio, nil
+		}
+	}
+	if !exaustiva {
+		return parser.TipoVazio, nil
+	}
+	return tipos[0], nil
+}
+
+// checkPadraoCaso verifica se o padrão de um caso é do tipo do valor da escolha
+func (t *TypeChecker) checkPadraoCaso(padrao parser.Expressao, vt parser.Tipo) error {
+	switch p := padrao.(type) {
+	case *parser.Variavel:
+		if !t.declarados.EhEnumeracao(vt) {
+			return fmt.Errorf("caso %s incompatível com %s: variantes só casam com enumerações", p.Nome, vt.String())
+		}
+		if _, ok := t.declarados.Variante(vt, p.Nome); !ok {
+			return fmt.Errorf("caso %s: '%s' não é variante de %s", p.Nome, p.Nome, vt.String())
+		}
+	case *parser.Constante:
+		if !t.mesmoTipo(vt, parser.TipoInteiro) {
+			return fmt.Errorf("caso %s incompatível com %s", p.String(), vt.String())
+		}
+	case *parser.LiteralTexto:
+		if !t.mesmoTipo(vt, parser.TipoTexto) {
+			return fmt.Errorf("caso %s incompatível com %s", p.String(), vt.String())
+		}
+	}
+	t.tipos[padrao] = vt
+	return nil
+}
+
+// inferirAcessoCampo checa o acesso a um campo de registro e retorna o tipo do campo
+func (t *TypeChecker) inferirAcessoCampo(objeto parser.Expressao, nome string) (parser.Tipo, error) {
+	ot, err := t.inferirExpr(objeto)
+	if err != nil {
+		return 0, err
+	}
+	if !t.declarados.EhRegistro(ot) {
+		return 0, fmt.Errorf("acesso ao campo '%s' requer um registro, recebeu %s", nome, ot.String())
+	}
+	_, campo, ok := t.declarados.Campo(ot, nome)
+	if !ok {
+		return 0, fmt.Errorf("registro '%s' não tem o campo '%s'", ot.String(), nome)
+	}
+	return campo, nil
+}
+
+// ehLocalAtribuivel indica se a expressão designa um registro guardado (variável, elemento
+// de lista, valor de mapa ou campo de um desses); atribuir a um campo de um valor
+// temporário, como o retorno de uma função, não teria efeito
+func ehLocalAtribuivel(e parser.Expressao) bool {
+	switch n := e.(type) {
+	case *parser.Variavel, *parser.Indexacao:
+		return true
+	case *parser.AcessoCampo:
+		return ehLocalAtribuivel(n.Objeto)
+	}
+	return false
+}
+
+// inferirMapaLiteral checa um mapa literal: como nas listas, a primeira chave e o primeiro
+// valor com tipo completo definem os demais; o mapa vazio ({}) fica sem tipo até o
+// contexto defini-lo
+func (t *TypeChecker) inferirMapaLiteral(n *parser.MapaLiteral) (parser.Tipo, error) {
+	if len(n.Chaves) == 0 {
+		return parser.TipoMapa(parser.TipoVazio, parser.TipoVazio), nil
+	}
+	tiposChave := make([]parser.Tipo, len(n.Chaves))
+	tiposValor := make([]parser.Tipo, len(n.Valores))
+	for i := range n.Chaves {
+		kt, err := t.inferirExpr(n.Chaves[i])
+		if err != nil {
+			return 0, err
+		}
+		if !kt.EhChaveMapa() {
+			return 0, fmt.Errorf("chave de mapa deve ser inteiro, texto ou booleano, recebeu %s", kt.String())
+		}
+		vt, err := t.inferirExpr(n.Valores[i])
+		if err != nil {
+			return 0, err
+		}
+		if vt == parser.TipoVazio {
+			return 0, fmt.Errorf("valor de mapa sem valor: %s", n.Valores[i].String())
+		}
+		tiposChave[i], tiposValor[i] = kt, vt
+	}
+	chave, valor := tiposChave[0], tiposValor[0]
+	for _, vt := range tiposValor {
+		if !t.tipoIncompleto(vt) {
+			valor = vt
+			break
+		}
+	}
+	for i := range n.Chaves {
+		if !t.mesmoTipo(chave, tiposChave[i]) {
+			return 0, fmt.Errorf("chave %d do mapa incompatível: esperado %s, recebeu %s", i+1, chave.String(), tiposChave[i].String())
+		}
+		if !t.aceitaValor(valor, n.Valores[i], tiposValor[i]) {
+			return 0, fmt.Errorf("valor %d do mapa incompatível: esperado %s, recebeu %s", i+1, valor.String(), tiposValor[i].String())
+		}
+	}
+	return parser.TipoMapa(chave, valor), nil
+}
+
+// inferirBuiltinColecao checa as builtins do registro com versão para listas (RotinaLista)
+// ou mapas (RotinaMapa) quando o primeiro argumento é uma lista ou um mapa: argumentos
+// TIPO_ELEMENTO devem ter o tipo dos elementos da lista e TIPO_CHAVE o das chaves do
+// mapa. ok é false quando a chamada não é desse caso
+func (t *TypeChecker) inferirBuiltinColecao(n *parser.ChamadaFuncao) (tp parser.Tipo, ok bool, err error) {
+	assinatura, existe := registry.RegistroGlobal.ObterAssinatura(n.Nome)
+	if !existe || (assinatura.RotinaLista == "" && assinatura.RotinaMapa == "") || len(n.Argumentos) == 0 {
+		return 0, false, nil
+	}
+	ct, err := t.inferirExpr(n.Argumentos[0])
+	if err != nil {
+		return 0, true, err
+	}
+	lista := ct.EhLista() && assinatura.RotinaLista != ""
+	mapa := ct.EhMapa() && assinatura.RotinaMapa != ""
+	if !lista && !mapa {
+		if assinatura.Rotina != "" && !ct.EhLista() && !ct.EhMapa() {
+			return 0, false, nil // versão para textos
+		}
+		esperado := "lista"
+		if assinatura.RotinaLista == "" {
+			esperado = "mapa"
+		}
+		return 0, true, fmt.Errorf("argumento 1 de '%s' incompatível: esperado %s, recebeu %s", n.Nome, esperado, ct.String())
+	}
+	if len(n.Argumentos) != len(assinatura.TiposArgumento) {
+		return 0, true, fmt.Errorf("função '%s' espera %d argumentos, recebeu %d", n.Nome, len(assinatura.TiposArgumento), len(n.Argumentos))
+	}
+	for i, arg := range n.Argumentos[1:] {
+		at, err := t.inferirExpr(arg)
+		if err != nil {
+			return 0, true, err
+		}
+		esperado := tipoRegistro(assinatura.TiposArgumento[i+1])
+		switch assinatura.TiposArgumento[i+1] {
+		case registry.TIPO_ELEMENTO:
+			esperado = ct.Elemento()
+		case registry.TIPO_CHAVE:
+			esperado = ct.Chave()
+		}
+		if esperado == parser.TipoVazio {
+			return 0, true, fmt.Errorf("'%s' em %s sem tipo definido", n.Nome, colecaoVazia(ct))
+		}
+		if !t.aceitaValor(esperado, arg, at) {
+			return 0, true, fmt.Errorf("argumento %d de '%s' incompatível: esperado %s, recebeu %s", i+2, n.Nome, esperado.String(), at.String())
+		}
+	}
+	if assinatura.TipoRetorno == registry.TIPO_LISTA_CHAVE {
+		return parser.TipoLista(ct.Chave()), true, nil
+	}
+	return tipoRegistro(assinatura.TipoRetorno), true, nil
+}
+
+// aceitaValor verifica se um valor pode ser usado onde o tipo esperado é exigido; listas
+// e mapas literais com partes vazias ([], [[], [1]], {"a": []}) assumem o tipo esperado
+func (t *TypeChecker) aceitaValor(esperado parser.Tipo, valor parser.Expressao, tipoValor parser.Tipo) bool {
+	if t.mesmoTipo(esperado, tipoValor) {
+		return true
+	}
+	switch literal := valor.(type) {
+	case *parser.ListaLiteral:
+		if !esperado.EhLista() {
+			return false
+		}
+		for _, e := range literal.Elementos {
+			if !t.aceitaValor(esperado.Elemento(), e, t.tipos[e]) {
+				return false
+			}
+		}
+	case *parser.MapaLiteral:
+		if !esperado.EhMapa() {
+			return false
+		}
+		for i, chave := range literal.Chaves {
+			if !t.mesmoTipo(esperado.Chave(), t.tipos[chave]) || !t.aceitaValor(esperado.Valor(), literal.Valores[i], t.tipos[literal.Valores[i]]) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	t.tipos[valor] = esperado
+	return true
+}
+
+// tipoIncompleto indica uma lista ou um mapa cujo tipo ainda não foi definido (a lista
+// literal vazia e o mapa literal vazio, também dentro de outras listas e mapas)
+func (t *TypeChecker) tipoIncompleto(tp parser.Tipo) bool {
+	switch {
+	case tp.EhLista():
+		return t.tipoIncompleto(tp.Elemento())
+	case tp.EhMapa():
+		return tp.Chave() == parser.TipoVazio || t.tipoIncompleto(tp.Valor())
+	}
+	return tp == parser.TipoVazio
+}
+
+// checkCorpoIteracao checa o corpo de um laço de iteração com as variáveis do laço
+// declaradas em um escopo só dele
+func (t *TypeChecker) checkCorpoIteracao(variaveis map[string]parser.Tipo, rotulo string, corpo *parser.Bloco) error {
+	t.pushScope()
+	defer t.popScope()
+	for nome, tp := range variaveis {
+		t.setVarLocal(nome, tp)
+	}
+	if err := t.entrarLaco(rotulo); err != nil {
+		return err
+	}
+	defer t.sairLaco()
+	_, err := t.inferirBloco(corpo)
+	return err
+}
+
+// colecaoVazia descreve nas mensagens de erro a lista ou o mapa vazio do tipo
+func colecaoVazia(tp parser.Tipo) string {
+	if tp.EhMapa() {
+		return "mapa vazio"
+	}
+	return "lista vazia"
+}
+
 // checkCondicao valida que a expressão da condição de estruturas de controle seja booleano
 func (t *TypeChecker) checkCondicao(contexto string, expr parser.Expressao) error {
 	ct, err := t.inferirExpr(expr)
@@ -340,6 +1201,37 @@ func (t *TypeChecker) checkCondicao(contexto string, expr parser.Expressao) erro
 	return nil
 }
 
+// entrarLaco registra um laço envolvente; rótulos não podem repetir o de um laço externo
+func (t *TypeChecker) entrarLaco(rotulo string) error {
+	if rotulo != "" {
+		for _, externo := range t.lacos {
+			if externo == rotulo {
+				return fmt.Errorf("rótulo '%s' já usado por um laço externo", rotulo)
+			}
+		}
+	}
+	t.lacos = append(t.lacos, rotulo)
+	return nil
+}
+
+func (t *TypeChecker) sairLaco() { t.lacos = t.lacos[:len(t.lacos)-1] }
+
+// checkControleLaco valida 'parar'/'continuar': só dentro de laços e com rótulo existente
+func (t *TypeChecker) checkControleLaco(comando, rotulo string) error {
+	if len(t.lacos) == 0 {
+		return fmt.Errorf("'%s' só é permitido dentro de laços", comando)
+	}
+	if rotulo == "" {
+		return nil
+	}
+	for _, laco := range t.lacos {
+		if laco == rotulo {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s %s': nenhum laço envolvente tem o rótulo '%s'", comando, rotulo, rotulo)
+}
+
 func (t *TypeChecker) inferirBloco(b *parser.Bloco) (parser.Tipo, error) {
 	t.pushScope()
 	defer t.popScope()
@@ -358,6 +1250,17 @@ func (t *TypeChecker) checkFuncDecl(fn *parser.FuncaoDeclaracao) (parser.Tipo, e
 	t.funcRetStack = append(t.funcRetStack, fn.Retorno)
 	defer func() { t.funcRetStack = t.funcRetStack[:len(t.funcRetStack)-1] }()
 
+	// Funções aninhadas não têm assinatura global: os usos contam para a função de fora
+	if sig, ok := t.funcs[fn.Nome]; ok && t.funcAtual == nil {
+		t.funcAtual = sig
+		defer func() { t.funcAtual = nil }()
+	}
+
+	// Laços de fora não são alcançáveis de dentro da função
+	lacos := t.lacos
+	t.lacos = nil
+	defer func() { t.lacos = lacos }()
+
 	t.pushScope()
 	// Adiciona os parâmetros ao escopo local da função
 	for _, param := range fn.Parametros {
@@ -399,6 +1302,11 @@ func (t *TypeChecker) hasReturnInBlock(b *parser.Bloco) bool {
 			if t.hasReturnInBlock(n.BlocoSe) {
 				return true
 			}
+			for _, ramo := range n.SenaoSe {
+				if t.hasReturnInBlock(ramo.Bloco) {
+					return true
+				}
+			}
 			if n.BlocoSenao != nil && t.hasReturnInBlock(n.BlocoSenao) {
 				return true
 			}
@@ -410,6 +1318,20 @@ func (t *TypeChecker) hasReturnInBlock(b *parser.Bloco) bool {
 			if t.hasReturnInBlock(n.Corpo) {
 				return true
 			}
+		case *parser.ComandoParaCada:
+			if t.hasReturnInBlock(n.Corpo) {
+				return true
+			}
+		case *parser.ComandoParaIntervalo:
+			if t.hasReturnInBlock(n.Corpo) {
+				return true
+			}
+		case *parser.Escolha:
+			for _, caso := range n.Casos {
+				if bloco, ok := caso.Corpo.(*parser.Bloco); ok && t.hasReturnInBlock(bloco) {
+					return true
+				}
+			}
 		}
 	}
 	return false
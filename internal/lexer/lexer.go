@@ -3,7 +3,9 @@ package lexer
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/khevencolino/Solar/internal/registry"
 )
@@ -15,6 +17,8 @@ type Lexer struct {
 	linha   int                          // Linha atual
 	coluna  int                          // Coluna atual
 	padroes map[TokenType]*regexp.Regexp // Padrões regex para cada tipo de token
+	// Tokens já lidos que aguardam sua vez (partes de textos com interpolação)
+	pendentes []Token
 }
 
 // NovoLexer cria um novo analisador léxico
@@ -28,16 +32,28 @@ func NovoLexer(entrada string) *Lexer {
 	return lexer
 }
 
+// padraoInteiro reconhece literais inteiros decimais, hexadecimais (0x), binários (0b) e octais (0o),
+// com '_' permitido apenas entre dígitos
+const padraoInteiro = `^(0[xX](_?[0-9a-fA-F])+|0[bB](_?[01])+|0[oO](_?[0-7])+|\d+(_\d+)*)`
+
+// padraoDecimal reconhece literais decimais com parte fracionária e/ou expoente:
+// 123.45, 1., .5, 1e9, 6.02e23, 2.5E-3 (também com '_' entre dígitos)
+const padraoDecimal = `^(\d+(_\d+)*\.(\d+(_\d+)*)?([eE][+-]?\d+(_\d+)*)?|\.\d+(_\d+)*([eE][+-]?\d+(_\d+)*)?|\d+(_\d+)*[eE][+-]?\d+(_\d+)*)`
+
+// restoLiteral reconhece letras, dígitos e '_' colados ao fim de um literal numérico
+// (0x, 0xG, 0b102, 1_, 1__0), que tornam o literal inteiro inválido
+var restoLiteral = regexp.MustCompile(`^[a-zA-Z0-9_]+`)
+
 // Padrões regex pré-compilados (otimização - compilados apenas uma vez)
 var padroesCompiledos = map[TokenType]*regexp.Regexp{
-	NUMBER:        regexp.MustCompile(`^\d+`),                    // Números inteiros: 123, 456
-	FLOAT:         regexp.MustCompile(`^\d+\.\d+`),               // Números decimais: 123.45, 0.5
-	STRING:        regexp.MustCompile(`^"[^"]*"`),                // Strings: "texto", "olá mundo"
+	NUMBER:        regexp.MustCompile(padraoInteiro),             // Inteiros: 123, 1_000, 0xFF, 0b1010, 0o17
+	FLOAT:         regexp.MustCompile(padraoDecimal),             // Números decimais: 123.45, .5, 6.02e23
 	PLUS:          regexp.MustCompile(`^\+`),                     // Adição: +
 	MINUS:         regexp.MustCompile(`^-`),                      // Subtração: -
 	MULTIPLY:      regexp.MustCompile(`^\*`),                     // Multiplicação: *
 	POWER:         regexp.MustCompile(`^\*\*`),                   // Potência: **
 	DIVIDE:        regexp.MustCompile(`^/`),                      // Divisão: /
+	MODULO:        regexp.MustCompile(`^%`),                      // Resto: %
 	LPAREN:        regexp.MustCompile(`^\(`),                     // Parêntese esquerdo: (
 	RPAREN:        regexp.MustCompile(`^\)`),                     // Parêntese direito: )
 	ASSIGN:        regexp.MustCompile(`^~>`),                     // Símbolo para alocar variável: ~>
@@ -45,40 +61,69 @@ var padroesCompiledos = map[TokenType]*regexp.Regexp{
 	COMMA:         regexp.MustCompile(`^,`),                      // Vírgula: ,
 	SEMICOLON:     regexp.MustCompile(`^;`),                      // Ponto e vírgula: ;
 	COLON:         regexp.MustCompile(`^:`),                      // Dois pontos: :
+	RANGE:         regexp.MustCompile(`^\.\.`),                   // Intervalo: ..
+	PONTO:         regexp.MustCompile(`^\.`),                     // Acesso a campo: .
 	WHITESPACE:    regexp.MustCompile(`^\s+`),                    // Espaços em branco
 	COMMENT:       regexp.MustCompile(`^//.*`),                   // Comentários: //
 	LBRACE:        regexp.MustCompile(`^\{`),                     // Chave esquerda: {
 	RBRACE:        regexp.MustCompile(`^\}`),                     // Chave direita: }
+	LBRACKET:      regexp.MustCompile(`^\[`),                     // Colchete esquerdo: [
+	RBRACKET:      regexp.MustCompile(`^\]`),                     // Colchete direito: ]
 	EQUAL:         regexp.MustCompile(`^==`),                     // Operador de igualdade: ==
+	SETA:          regexp.MustCompile(`^=>`),                     // Seta de caso: =>
 	NOT_EQUAL:     regexp.MustCompile(`^!=`),                     // Operador de diferença: !=
 	LESS_EQUAL:    regexp.MustCompile(`^<=`),                     // Operador menor ou igual: <=
 	GREATER_EQUAL: regexp.MustCompile(`^>=`),                     // Operador maior ou igual: >=
 	LESS:          regexp.MustCompile(`^<`),                      // Operador menor que: <
 	GREATER:       regexp.MustCompile(`^>`),                      // Operador maior que: >
+	E:             regexp.MustCompile(`^&&`),                     // E lógico: && (ou a palavra-chave e)
+	OU:            regexp.MustCompile(`^\|\|`),                   // Ou lógico: || (ou a palavra-chave ou)
+	NAO:           regexp.MustCompile(`^!`),                      // Negação lógica: ! (ou a palavra-chave nao)
+	BIT_AND:       regexp.MustCompile(`^&`),                      // E bit a bit: &
+	BIT_OR:        regexp.MustCompile(`^\|`),                     // Ou bit a bit: |
+	BIT_XOR:       regexp.MustCompile(`^\^`),                     // Ou exclusivo bit a bit: ^
+	BIT_NOT:       regexp.MustCompile(`^~`),                      // Complemento bit a bit: ~
+	SHIFT_LEFT:    regexp.MustCompile(`^<<`),                     // Deslocamento à esquerda: <<
+	SHIFT_RIGHT:   regexp.MustCompile(`^>>`),                     // Deslocamento à direita (aritmético): >>
 }
 
 // ordemTiposToken define a ordem de tentativa de matching dos tokens.
-// A ordem é importante para evitar conflitos (ex: FLOAT antes de NUMBER, POWER antes de MULTIPLY, >= antes de >, etc.)
+// A ordem é importante para evitar conflitos (ex: FLOAT antes de NUMBER, POWER antes de MULTIPLY, >= antes de >,
+// && antes de &, ~> antes de ~, etc.)
 var ordemTiposToken = []TokenType{
 	COMMENT,
 	ASSIGN,
 	IDENTIFIER,
 	POWER,
+	SHIFT_LEFT,
+	SHIFT_RIGHT,
 	GREATER_EQUAL,
 	LESS_EQUAL,
 	NOT_EQUAL,
 	EQUAL,
-	STRING,
+	SETA,
+	E,
+	OU,
+	NAO,
+	BIT_AND,
+	BIT_OR,
+	BIT_XOR,
+	BIT_NOT,
+	RANGE,
 	FLOAT,
+	PONTO,
 	NUMBER,
 	PLUS,
 	MINUS,
 	DIVIDE,
+	MODULO,
 	MULTIPLY,
 	LPAREN,
 	RPAREN,
 	LBRACE,
 	RBRACE,
+	LBRACKET,
+	RBRACKET,
 	LESS,
 	GREATER,
 	COMMA,
@@ -117,16 +162,44 @@ func (l *Lexer) Tokenizar() ([]Token, error) {
 
 // proximoToken encontra o próximo token
 func (l *Lexer) proximoToken() (Token, error) {
+	if len(l.pendentes) > 0 {
+		token := l.pendentes[0]
+		l.pendentes = l.pendentes[1:]
+		return token, nil
+	}
+
 	if !l.temMais() {
 		return NovoToken(EOF, "", l.obterPosicaoAtual()), nil
 	}
 
+	// Textos têm escapes e não cabem em uma expressão regular
+	if l.espiar() == '"' {
+		return l.lerTexto()
+	}
+
 	posicaoAtual := l.obterPosicaoAtual()
 	restante := l.entrada[l.posicao:]
 
 	// Tenta fazer match com cada padrão respeitando a ordem definida globalmente
 	for _, tipoToken := range ordemTiposToken {
 		if match := l.padroes[tipoToken].FindString(restante); match != "" {
+			// Em "0..10" o '.' pertence ao intervalo, não a um decimal "0."
+			if tipoToken == FLOAT && strings.HasSuffix(match, ".") && strings.HasPrefix(restante[len(match):], ".") {
+				continue
+			}
+
+			if tipoToken == NUMBER || tipoToken == FLOAT {
+				if resto := restoLiteral.FindString(restante[len(match):]); resto != "" {
+					literal := match + resto
+					l.avancar(len(literal))
+					tipo := "inteiro"
+					if tipoToken == FLOAT {
+						tipo = "decimal"
+					}
+					return NovoToken(INVALID, literal, posicaoAtual), fmt.Errorf("literal %s inválido '%s' em %s", tipo, literal, posicaoAtual)
+				}
+			}
+
 			token := NovoToken(tipoToken, match, posicaoAtual)
 
 			// Se é um identificador, verifica se é uma função builtin ou palavra-chave
@@ -149,6 +222,140 @@ func (l *Lexer) proximoToken() (Token, error) {
 	return NovoToken(INVALID, caractereInvalido, posicaoAtual), fmt.Errorf("caractere inválido '%s' em %s", caractereInvalido, posicaoAtual)
 }
 
+// lerTexto lê um literal de texto entre aspas na mesma linha; o valor do token é o
+// conteúdo sem as aspas e com as sequências de escape já decodificadas.
+// Um texto com interpolações vira a sequência STRING_START, tokens da expressão,
+// (STRING_MIDDLE, tokens da expressão)*, STRING_END; os tokens além do primeiro
+// ficam em l.pendentes
+func (l *Lexer) lerTexto() (Token, error) {
+	inicio := l.obterPosicaoAtual()
+	l.avancar(1) // aspas de abertura
+
+	var tokens []Token
+	var valor strings.Builder
+	posicaoParte := inicio
+	for {
+		if !l.temMais() || l.espiar() == '\n' {
+			return NovoToken(INVALID, l.entrada[inicio.Offset:l.posicao], inicio), fmt.Errorf("texto não terminado iniciado em %s", inicio)
+		}
+
+		switch c := l.espiar(); {
+		case c == '"':
+			l.avancar(1)
+			tipo := STRING
+			if len(tokens) > 0 {
+				tipo = STRING_END
+			}
+			tokens = append(tokens, NovoToken(tipo, valor.String(), posicaoParte))
+			l.pendentes = append(l.pendentes, tokens[1:]...)
+			return tokens[0], nil
+		case c == '$' && strings.HasPrefix(l.entrada[l.posicao:], "${"):
+			tipo := STRING_START
+			if len(tokens) > 0 {
+				tipo = STRING_MIDDLE
+			}
+			tokens = append(tokens, NovoToken(tipo, valor.String(), posicaoParte))
+			valor.Reset()
+
+			posicaoInterpolacao := l.obterPosicaoAtual()
+			l.avancar(2) // ${
+			expressao, err := l.lerInterpolacao(posicaoInterpolacao)
+			if err != nil {
+				return NovoToken(INVALID, l.entrada[inicio.Offset:l.posicao], inicio), err
+			}
+			tokens = append(tokens, expressao...)
+			posicaoParte = l.obterPosicaoAtual()
+		case c == '\\':
+			posicaoEscape := l.obterPosicaoAtual()
+			decodificado, comprimento, err := decodificarEscape(l.entrada[l.posicao:])
+			if err != nil {
+				l.avancar(comprimento)
+				return NovoToken(INVALID, l.entrada[inicio.Offset:l.posicao], inicio), fmt.Errorf("%v em %s", err, posicaoEscape)
+			}
+			valor.WriteString(decodificado)
+			l.avancar(comprimento)
+		default:
+			valor.WriteByte(c)
+			l.avancar(1)
+		}
+	}
+}
+
+// lerInterpolacao lê os tokens da expressão de uma interpolação até o '}' que a fecha
+// (chaves internas e textos aninhados são respeitados)
+func (l *Lexer) lerInterpolacao(inicio Position) ([]Token, error) {
+	var tokens []Token
+	profundidade := 0
+	for {
+		token, err := l.proximoToken()
+		if err != nil {
+			return nil, fmt.Errorf("%v (na interpolação iniciada em %s)", err, inicio)
+		}
+
+		switch token.Type {
+		case EOF:
+			return nil, fmt.Errorf("interpolação '${' sem '}' em %s", inicio)
+		case WHITESPACE, COMMENT:
+			continue
+		case LBRACE:
+			profundidade++
+		case RBRACE:
+			if profundidade == 0 {
+				if len(tokens) == 0 {
+					return nil, fmt.Errorf("interpolação vazia em %s", inicio)
+				}
+				return tokens, nil
+			}
+			profundidade--
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+// decodificarEscape decodifica a sequência de escape no início de 'texto' (que começa com '\'),
+// retornando o texto resultante e quantos bytes da entrada ela ocupa.
+// Sequências aceitas: \n \t \r \\ \" \' \$ \uXXXX e \u{X...} (código Unicode em hexadecimal)
+func decodificarEscape(texto string) (string, int, error) {
+	if len(texto) < 2 {
+		return "", len(texto), fmt.Errorf("sequência de escape incompleta")
+	}
+
+	switch texto[1] {
+	case 'n':
+		return "\n", 2, nil
+	case 't':
+		return "\t", 2, nil
+	case 'r':
+		return "\r", 2, nil
+	case '\\', '"', '\'', '$':
+		return texto[1:2], 2, nil
+	case 'u':
+		var digitos string
+		comprimento := 6
+		if strings.HasPrefix(texto[2:], "{") {
+			fim := strings.IndexByte(texto, '}')
+			if fim < 0 {
+				return "", 2, fmt.Errorf("sequência de escape '\\u{' sem '}'")
+			}
+			digitos, comprimento = texto[3:fim], fim+1
+		} else if len(texto) >= 6 {
+			digitos = texto[2:6]
+		}
+		codigo, err := strconv.ParseUint(digitos, 16, 32)
+		if err != nil || len(digitos) == 0 || len(digitos) > 6 || !utf8.ValidRune(rune(codigo)) {
+			return "", 2, fmt.Errorf("sequência de escape '\\u' inválida: esperado \\uXXXX ou \\u{X...} com um código Unicode válido")
+		}
+		if codigo == 0 {
+			// Os textos dos backends compilados terminam em \0: o caractere nulo cortaria o texto
+			return "", comprimento, fmt.Errorf("sequência de escape '%s' inválida: textos não podem conter o caractere nulo", texto[:comprimento])
+		}
+		return string(rune(codigo)), comprimento, nil
+	default:
+		_, tamanho := utf8.DecodeRuneInString(texto[1:])
+		return "", 1 + tamanho, fmt.Errorf("sequência de escape inválida '%s'", texto[:1+tamanho])
+	}
+}
+
 // ehFuncaoBuiltin verifica se um identificador é uma função builtin
 func (l *Lexer) ehFuncaoBuiltin(nome string) bool {
 	return registry.RegistroGlobal.EhFuncaoBuiltin(nome)
@@ -164,8 +371,21 @@ var palavrasChave = map[string]TokenType{
 	"falso":      FALSO,
 	"para":       PARA,
 	"enquanto":   ENQUANTO,
+	"parar":      PARAR,
+	"continuar":  CONTINUAR,
+	"cada":       CADA,
+	"em":         EM,
+	"ate":        ATE,
+	"passo":      PASSO,
 	"importar":   IMPORTAR,
 	"de":         DE,
+	"e":          E,
+	"ou":         OU,
+	"nao":        NAO,
+	"registro":   REGISTRO,
+	"enumeracao": ENUMERACAO,
+	"escolha":    ESCOLHA,
+	"caso":       CASO,
 }
 
 // ehPalavraChave verifica se um identificador é uma palavra-chave
@@ -194,8 +414,8 @@ func (l *Lexer) avancar(comprimento int) {
 			if l.entrada[l.posicao] == '\n' {
 				l.linha++
 				l.coluna = 1
-			} else {
-				l.coluna++
+			} else if l.entrada[l.posicao]&0xC0 != 0x80 {
+				l.coluna++ // colunas contam caracteres, não bytes UTF-8 de continuação
 			}
 			l.posicao++
 		}
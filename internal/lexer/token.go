@@ -15,6 +15,7 @@ const (
 	MULTIPLY                    // Operador de multiplicação (*)
 	POWER                       // Operador de potência (**)
 	DIVIDE                      // Operador de divisão
+	MODULO                      // Operador de resto (%)
 	LPAREN                      // Parêntese esquerdo (()
 	RPAREN                      // Parêntese direito ())
 	ASSIGN                      // Assign para variavel ~>
@@ -45,11 +46,45 @@ const (
 	VERDADEIRO // verdadeiro
 	FALSO      // falso
 	// Loops
-	PARA     // for
-	ENQUANTO // while
+	PARA      // for
+	ENQUANTO  // while
+	PARAR     // break
+	CONTINUAR // continue
+	CADA      // cada (para cada x em xs)
+	EM        // em (para x em xs, para i em 0..10)
+	ATE       // ate (para i de 1 ate 10)
+	PASSO     // passo (para i de 1 ate 10 passo 2)
+	RANGE     // .. (intervalo)
 	// Imports
 	IMPORTAR // importar
 	DE       // de
+	// Operadores lógicos
+	E   // e, &&
+	OU  // ou, ||
+	NAO // nao, !
+	// Operadores bit a bit
+	BIT_AND     // &
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_NOT     // ~
+	SHIFT_LEFT  // <<
+	SHIFT_RIGHT // >>
+	// Partes de um texto com interpolação: "a ${x} b ${y} c" vira
+	// STRING_START("a ") x STRING_MIDDLE(" b ") y STRING_END(" c")
+	STRING_START
+	STRING_MIDDLE
+	STRING_END
+	// Listas
+	LBRACKET // [
+	RBRACKET // ]
+	// Registros
+	REGISTRO // registro
+	PONTO    // . (acesso a campo)
+	// Enumerações e escolha
+	ENUMERACAO // enumeracao
+	ESCOLHA    // escolha
+	CASO       // caso
+	SETA       // => (separa o padrão do corpo de um caso)
 )
 
 // String retorna uma representação em string do tipo de token
@@ -71,6 +106,8 @@ func (t TokenType) String() string {
 		return "POWER"
 	case DIVIDE:
 		return "DIVIDE"
+	case MODULO:
+		return "MODULO"
 	case LPAREN:
 		return "LPAREN"
 	case RPAREN:
@@ -127,10 +164,64 @@ func (t TokenType) String() string {
 		return "PARA"
 	case ENQUANTO:
 		return "ENQUANTO"
+	case PARAR:
+		return "PARAR"
+	case CONTINUAR:
+		return "CONTINUAR"
+	case CADA:
+		return "CADA"
+	case EM:
+		return "EM"
+	case ATE:
+		return "ATE"
+	case PASSO:
+		return "PASSO"
+	case RANGE:
+		return "RANGE"
 	case IMPORTAR:
 		return "IMPORTAR"
 	case DE:
 		return "DE"
+	case E:
+		return "E"
+	case OU:
+		return "OU"
+	case NAO:
+		return "NAO"
+	case BIT_AND:
+		return "BIT_AND"
+	case BIT_OR:
+		return "BIT_OR"
+	case BIT_XOR:
+		return "BIT_XOR"
+	case BIT_NOT:
+		return "BIT_NOT"
+	case SHIFT_LEFT:
+		return "SHIFT_LEFT"
+	case SHIFT_RIGHT:
+		return "SHIFT_RIGHT"
+	case STRING_START:
+		return "STRING_START"
+	case STRING_MIDDLE:
+		return "STRING_MIDDLE"
+	case STRING_END:
+		return "STRING_END"
+	case LBRACKET:
+		return "LBRACKET"
+	case RBRACKET:
+		return "RBRACKET"
+	case REGISTRO:
+		return "REGISTRO"
+	case PONTO:
+		return "PONTO"
+	case ENUMERACAO:
+		return "ENUMERACAO"
+	case ESCOLHA:
+		return "ESCOLHA"
+	case CASO:
+		return "CASO"
+	case SETA:
+		return "SETA"
 	default:
 		return "UNKNOWN"
 	}
@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/khevencolino/Solar/internal/lexer"
 )
@@ -11,18 +14,34 @@ type Node interface {
 	Constante(constante *Constante) interface{}
 	Booleano(boolLit *Booleano) interface{}
 	LiteralTexto(literal *LiteralTexto) interface{}
+	TextoInterpolado(texto *TextoInterpolado) interface{}
 	LiteralDecimal(literal *LiteralDecimal) interface{}
 	OperacaoBinaria(operacao *OperacaoBinaria) interface{}
+	OperacaoUnaria(operacao *OperacaoUnaria) interface{}
 	Variavel(variavel *Variavel) interface{}
 	Atribuicao(atribuicao *Atribuicao) interface{}
 	ChamadaFuncao(chamada *ChamadaFuncao) interface{}
 	ComandoSe(comando *ComandoSe) interface{}
 	ComandoEnquanto(cmd *ComandoEnquanto) interface{}
 	ComandoPara(cmd *ComandoPara) interface{}
+	ComandoParaCada(cmd *ComandoParaCada) interface{}
+	ComandoParaIntervalo(cmd *ComandoParaIntervalo) interface{}
 	Bloco(bloco *Bloco) interface{}
 	FuncaoDeclaracao(fn *FuncaoDeclaracao) interface{}
 	Retorno(ret *Retorno) interface{}
+	Parar(parar *Parar) interface{}
+	Continuar(continuar *Continuar) interface{}
 	Importacao(imp *Importacao) interface{}
+	ListaLiteral(lista *ListaLiteral) interface{}
+	Indexacao(indexacao *Indexacao) interface{}
+	AtribuicaoIndice(atribuicao *AtribuicaoIndice) interface{}
+	MapaLiteral(mapa *MapaLiteral) interface{}
+	RegistroDeclaracao(registro *RegistroDeclaracao) interface{}
+	RegistroLiteral(registro *RegistroLiteral) interface{}
+	AcessoCampo(acesso *AcessoCampo) interface{}
+	AtribuicaoCampo(atribuicao *AtribuicaoCampo) interface{}
+	EnumeracaoDeclaracao(enumeracao *EnumeracaoDeclaracao) interface{}
+	Escolha(escolha *Escolha) interface{}
 }
 
 // Expressao representa a interface base para todos os nós da AST
@@ -69,7 +88,30 @@ type LiteralTexto struct {
 
 func (lt *LiteralTexto) Aceitar(node Node) interface{} { return node.LiteralTexto(lt) }
 func (lt *LiteralTexto) String() string {
-	return fmt.Sprintf("\"%s\"", lt.Valor)
+	return strconv.Quote(lt.Valor)
+}
+
+// TextoInterpolado representa um texto com interpolações ("Olá, ${nome}!") na árvore.
+// Partes intercala os trechos literais (LiteralTexto) e as expressões, na ordem do texto
+type TextoInterpolado struct {
+	Partes []Expressao
+	Token  lexer.Token
+}
+
+func (t *TextoInterpolado) Aceitar(node Node) interface{} { return node.TextoInterpolado(t) }
+func (t *TextoInterpolado) String() string {
+	var resultado strings.Builder
+	resultado.WriteString("\"")
+	for _, parte := range t.Partes {
+		if literal, ok := parte.(*LiteralTexto); ok {
+			texto := strconv.Quote(literal.Valor)
+			resultado.WriteString(strings.ReplaceAll(texto[1:len(texto)-1], "${", "\\${"))
+		} else {
+			resultado.WriteString("${" + parte.String() + "}")
+		}
+	}
+	resultado.WriteString("\"")
+	return resultado.String()
 }
 
 // LiteralDecimal representa um literal decimal na árvore
@@ -104,6 +146,23 @@ func (o *OperacaoBinaria) String() string {
 		o.OperandoDireito.String())
 }
 
+// OperacaoUnaria representa uma operação prefixa (ex: -x, nao x) na árvore
+type OperacaoUnaria struct {
+	Operador TipoOperador
+	Operando Expressao
+	Token    lexer.Token
+}
+
+// Aceitar implementa o padrão para OperacaoUnaria
+func (o *OperacaoUnaria) Aceitar(node Node) interface{} {
+	return node.OperacaoUnaria(o)
+}
+
+// String retorna representação em string da operação
+func (o *OperacaoUnaria) String() string {
+	return fmt.Sprintf("(%s %s)", o.Operador.String(), o.Operando.String())
+}
+
 // TipoOperador representa os tipos de operadores
 type TipoOperador int
 
@@ -112,6 +171,7 @@ const (
 	SUBTRACAO
 	MULTIPLICACAO
 	DIVISAO
+	RESTO
 	POWER
 	// Operadores de comparação
 	IGUALDADE
@@ -120,6 +180,19 @@ const (
 	MAIOR_QUE
 	MENOR_IGUAL
 	MAIOR_IGUAL
+	// Operadores lógicos (com curto-circuito)
+	E_LOGICO
+	OU_LOGICO
+	NAO_LOGICO
+	// Menos unário
+	NEGACAO
+	// Operadores bit a bit (somente inteiros)
+	E_BIT
+	OU_BIT
+	XOU_BIT
+	DESLOCAMENTO_ESQUERDA
+	DESLOCAMENTO_DIREITA
+	NAO_BIT
 )
 
 // String retorna representação em string do operador
@@ -133,6 +206,8 @@ func (t TipoOperador) String() string {
 		return "*"
 	case DIVISAO:
 		return "/"
+	case RESTO:
+		return "%"
 	case POWER:
 		return "**"
 	case IGUALDADE:
@@ -147,6 +222,26 @@ func (t TipoOperador) String() string {
 		return "<="
 	case MAIOR_IGUAL:
 		return ">="
+	case E_LOGICO:
+		return "e"
+	case OU_LOGICO:
+		return "ou"
+	case NAO_LOGICO:
+		return "nao"
+	case NEGACAO:
+		return "-"
+	case E_BIT:
+		return "&"
+	case OU_BIT:
+		return "|"
+	case XOU_BIT:
+		return "^"
+	case DESLOCAMENTO_ESQUERDA:
+		return "<<"
+	case DESLOCAMENTO_DIREITA:
+		return ">>"
+	case NAO_BIT:
+		return "~"
 	default:
 		return "?"
 	}
@@ -182,6 +277,173 @@ func (a *Atribuicao) String() string {
 	return fmt.Sprintf("%s = %s", a.Nome, a.Valor.String())
 }
 
+// ListaLiteral representa uma lista literal ([1, 2, 3]) na árvore
+type ListaLiteral struct {
+	Elementos []Expressao
+	Token     lexer.Token
+}
+
+func (l *ListaLiteral) Aceitar(node Node) interface{} { return node.ListaLiteral(l) }
+func (l *ListaLiteral) String() string {
+	elementos := make([]string, len(l.Elementos))
+	for i, elemento := range l.Elementos {
+		elementos[i] = elemento.String()
+	}
+	return "[" + strings.Join(elementos, ", ") + "]"
+}
+
+// MapaLiteral representa um mapa literal ({"a": 1, "b": 2}) na árvore; as entradas
+// ficam na ordem do código, que é a ordem de inserção
+type MapaLiteral struct {
+	Chaves  []Expressao
+	Valores []Expressao
+	Token   lexer.Token // token '{'
+}
+
+func (m *MapaLiteral) Aceitar(node Node) interface{} { return node.MapaLiteral(m) }
+func (m *MapaLiteral) String() string {
+	entradas := make([]string, len(m.Chaves))
+	for i, chave := range m.Chaves {
+		entradas[i] = chave.String() + ": " + m.Valores[i].String()
+	}
+	return "{" + strings.Join(entradas, ", ") + "}"
+}
+
+// Indexacao representa o acesso a um elemento de lista (xs[i]) ou ao valor de uma
+// chave de mapa (m[k]) na árvore
+type Indexacao struct {
+	Colecao Expressao
+	Indice  Expressao
+	Token   lexer.Token // token '['
+}
+
+func (i *Indexacao) Aceitar(node Node) interface{} { return node.Indexacao(i) }
+func (i *Indexacao) String() string {
+	return fmt.Sprintf("%s[%s]", i.Colecao.String(), i.Indice.String())
+}
+
+// AtribuicaoIndice representa a atribuição a um elemento (xs[i] ~> valor) ou a uma
+// chave de mapa (m[k] ~> valor) na árvore
+type AtribuicaoIndice struct {
+	Colecao Expressao
+	Indice  Expressao
+	Valor   Expressao
+	Token   lexer.Token // token '['
+}
+
+func (a *AtribuicaoIndice) Aceitar(node Node) interface{} { return node.AtribuicaoIndice(a) }
+func (a *AtribuicaoIndice) String() string {
+	return fmt.Sprintf("%s[%s] = %s", a.Colecao.String(), a.Indice.String(), a.Valor.String())
+}
+
+// RegistroDeclaracao representa a declaração de um tipo registro
+// (registro Ponto { x: decimal, y: decimal }) na árvore
+type RegistroDeclaracao struct {
+	Nome   string
+	Campos []CampoRegistro // na ordem da declaração
+	Token  lexer.Token
+}
+
+func (r *RegistroDeclaracao) Aceitar(node Node) interface{} { return node.RegistroDeclaracao(r) }
+func (r *RegistroDeclaracao) String() string {
+	campos := make([]string, len(r.Campos))
+	for i, campo := range r.Campos {
+		campos[i] = campo.Nome + ": " + campo.Tipo.String()
+	}
+	return fmt.Sprintf("registro %s { %s }", r.Nome, strings.Join(campos, ", "))
+}
+
+// RegistroLiteral representa a construção de um registro (Ponto{x: 1.0, y: 2.0}) na
+// árvore; os campos ficam na ordem do código, que é a ordem de avaliação
+type RegistroLiteral struct {
+	Nome    string
+	Campos  []string
+	Valores []Expressao
+	Token   lexer.Token // nome do registro
+}
+
+func (r *RegistroLiteral) Aceitar(node Node) interface{} { return node.RegistroLiteral(r) }
+func (r *RegistroLiteral) String() string {
+	campos := make([]string, len(r.Campos))
+	for i, campo := range r.Campos {
+		campos[i] = campo + ": " + r.Valores[i].String()
+	}
+	return r.Nome + "{" + strings.Join(campos, ", ") + "}"
+}
+
+// AcessoCampo representa a leitura de um campo de registro (p.x) na árvore
+type AcessoCampo struct {
+	Objeto Expressao
+	Campo  string
+	Token  lexer.Token // nome do campo
+}
+
+func (a *AcessoCampo) Aceitar(node Node) interface{} { return node.AcessoCampo(a) }
+func (a *AcessoCampo) String() string {
+	return a.Objeto.String() + "." + a.Campo
+}
+
+// AtribuicaoCampo representa a atribuição a um campo de registro (p.x ~> valor) na árvore
+type AtribuicaoCampo struct {
+	Objeto Expressao
+	Campo  string
+	Valor  Expressao
+	Token  lexer.Token // nome do campo
+}
+
+func (a *AtribuicaoCampo) Aceitar(node Node) interface{} { return node.AtribuicaoCampo(a) }
+func (a *AtribuicaoCampo) String() string {
+	return fmt.Sprintf("%s.%s = %s", a.Objeto.String(), a.Campo, a.Valor.String())
+}
+
+// EnumeracaoDeclaracao representa a declaração de uma enumeração
+// (enumeracao Cor { Vermelho, Verde, Azul }) na árvore
+type EnumeracaoDeclaracao struct {
+	Nome      string
+	Variantes []string // na ordem da declaração
+	Token     lexer.Token
+}
+
+func (e *EnumeracaoDeclaracao) Aceitar(node Node) interface{} { return node.EnumeracaoDeclaracao(e) }
+func (e *EnumeracaoDeclaracao) String() string {
+	return fmt.Sprintf("enumeracao %s { %s }", e.Nome, strings.Join(e.Variantes, ", "))
+}
+
+// CasoEscolha é um caso de escolha: o padrão (variante, inteiro ou texto; nil em
+// 'caso _') e o corpo, um bloco ou uma expressão
+type CasoEscolha struct {
+	Padrao Expressao
+	Corpo  Expressao
+	Token  lexer.Token // palavra-chave caso
+}
+
+func (c CasoEscolha) String() string {
+	padrao := "_"
+	if c.Padrao != nil {
+		padrao = c.Padrao.String()
+	}
+	return fmt.Sprintf("caso %s => %s", padrao, c.Corpo.String())
+}
+
+// Escolha representa 'escolha valor { caso P => corpo, ... }' na árvore; os casos são
+// testados em ordem. ComoValor indica que a escolha aparece dentro de uma expressão
+// (ex: x ~> escolha ...) e por isso precisa produzir um valor em todos os casos
+type Escolha struct {
+	Valor     Expressao
+	Casos     []CasoEscolha
+	ComoValor bool
+	Token     lexer.Token
+}
+
+func (e *Escolha) Aceitar(node Node) interface{} { return node.Escolha(e) }
+func (e *Escolha) String() string {
+	casos := make([]string, len(e.Casos))
+	for i, caso := range e.Casos {
+		casos[i] = caso.String()
+	}
+	return fmt.Sprintf("escolha %s { %s }", e.Valor.String(), strings.Join(casos, ", "))
+}
+
 // ChamadaFuncao representa uma chamada de função na árvore
 type ChamadaFuncao struct {
 	Nome       string
@@ -208,16 +470,27 @@ func (c *ChamadaFuncao) String() string {
 type ComandoSe struct {
 	Condicao   Expressao
 	BlocoSe    *Bloco
-	BlocoSenao *Bloco // pode ser nil se não há else
+	SenaoSe    []*RamoSenaoSe // cadeia 'senao se', testada em ordem (pode ser vazia)
+	BlocoSenao *Bloco         // pode ser nil se não há else
 	Token      lexer.Token
 }
 
+// RamoSenaoSe representa um ramo 'senao se <condicao> { ... }' de um ComandoSe
+type RamoSenaoSe struct {
+	Condicao Expressao
+	Bloco    *Bloco
+	Token    lexer.Token
+}
+
 func (c *ComandoSe) Aceitar(node Node) interface{} {
 	return node.ComandoSe(c)
 }
 
 func (c *ComandoSe) String() string {
 	str := fmt.Sprintf("se (%s) %s", c.Condicao.String(), c.BlocoSe.String())
+	for _, ramo := range c.SenaoSe {
+		str += fmt.Sprintf(" senao se (%s) %s", ramo.Condicao.String(), ramo.Bloco.String())
+	}
 	if c.BlocoSenao != nil {
 		str += fmt.Sprintf(" senao %s", c.BlocoSenao.String())
 	}
@@ -249,6 +522,7 @@ func (b *Bloco) String() string {
 type ComandoEnquanto struct {
 	Condicao Expressao
 	Corpo    *Bloco
+	Rotulo   string // rótulo opcional usado por 'parar'/'continuar' (vazio se não houver)
 	Token    lexer.Token
 }
 
@@ -263,6 +537,7 @@ type ComandoPara struct {
 	Condicao      Expressao // pode ser nil (trata como verdadeiro)
 	PosIteracao   Expressao // pode ser nil
 	Corpo         *Bloco
+	Rotulo        string // rótulo opcional usado por 'parar'/'continuar' (vazio se não houver)
 	Token         lexer.Token
 }
 
@@ -271,6 +546,52 @@ func (p *ComandoPara) String() string {
 	return fmt.Sprintf("para (%s; %s; %s) %s", strOr(p.Inicializacao), strOr(p.Condicao), strOr(p.PosIteracao), p.Corpo.String())
 }
 
+// ComandoParaCada percorre os elementos de uma lista (para cada x em xs { ... }) ou
+// as chaves de um mapa, opcionalmente com os valores (para cada k, v em m { ... })
+type ComandoParaCada struct {
+	Variavel string // variável do laço, visível só no corpo
+	Valor    string // variável do valor (só em mapas); vazio se não houver
+	Colecao  Expressao
+	Corpo    *Bloco
+	Rotulo   string // rótulo opcional usado por 'parar'/'continuar' (vazio se não houver)
+	Token    lexer.Token
+}
+
+func (p *ComandoParaCada) Aceitar(node Node) interface{} { return node.ComandoParaCada(p) }
+func (p *ComandoParaCada) String() string {
+	variaveis := p.Variavel
+	if p.Valor != "" {
+		variaveis += ", " + p.Valor
+	}
+	return fmt.Sprintf("para cada %s em %s %s", variaveis, p.Colecao.String(), p.Corpo.String())
+}
+
+// ComandoParaIntervalo percorre um intervalo de inteiros: para i em 0..10 { ... } (fim
+// excluído) ou para i de 1 ate 10 passo 2 { ... } (fim incluído). Início, fim e passo
+// são avaliados uma única vez, antes da primeira iteração
+type ComandoParaIntervalo struct {
+	Variavel  string // variável do laço, visível só no corpo
+	Inicio    Expressao
+	Fim       Expressao
+	Passo     Expressao // pode ser nil (passo 1)
+	Inclusivo bool      // 'ate' inclui o fim; '..' não
+	Corpo     *Bloco
+	Rotulo    string // rótulo opcional usado por 'parar'/'continuar' (vazio se não houver)
+	Token     lexer.Token
+}
+
+func (p *ComandoParaIntervalo) Aceitar(node Node) interface{} { return node.ComandoParaIntervalo(p) }
+func (p *ComandoParaIntervalo) String() string {
+	passo := ""
+	if p.Passo != nil {
+		passo = " passo " + p.Passo.String()
+	}
+	if !p.Inclusivo {
+		return fmt.Sprintf("para %s em %s..%s%s %s", p.Variavel, p.Inicio.String(), p.Fim.String(), passo, p.Corpo.String())
+	}
+	return fmt.Sprintf("para %s de %s ate %s%s %s", p.Variavel, p.Inicio.String(), p.Fim.String(), passo, p.Corpo.String())
+}
+
 func strOr(e Expressao) string {
 	if e == nil {
 		return ""
@@ -289,6 +610,200 @@ const (
 	TipoBooleano             // booleano
 )
 
+// Tipos compostos (lista<T>, mapa<K, V>, registros, enumerações) são criados sob demanda por TipoLista,
+// TipoMapa e TipoNomeado e recebem números a partir de tipoPrimeiroComposto; cada combinação tem um único número, então Tipo
+// continua comparável com == e utilizável como chave de mapa
+const tipoPrimeiroComposto Tipo = 100
+
+// categoriaTipo distingue os tipos compostos
+type categoriaTipo int
+
+const (
+	categoriaLista categoriaTipo = iota
+	categoriaMapa
+	categoriaNomeado
+)
+
+// descricaoTipo descreve um tipo composto (nos mapas, elemento é o tipo dos valores;
+// nos tipos nomeados, só o nome importa)
+type descricaoTipo struct {
+	categoria categoriaTipo
+	chave     Tipo
+	elemento  Tipo
+	nome      string
+}
+
+var (
+	tiposCompostos  []descricaoTipo
+	indiceCompostos = make(map[descricaoTipo]Tipo)
+	mutexCompostos  sync.Mutex
+)
+
+// internarTipo retorna o número do tipo composto descrito, criando-o se necessário
+func internarTipo(descricao descricaoTipo) Tipo {
+	mutexCompostos.Lock()
+	defer mutexCompostos.Unlock()
+	if tp, ok := indiceCompostos[descricao]; ok {
+		return tp
+	}
+	tp := tipoPrimeiroComposto + Tipo(len(tiposCompostos))
+	tiposCompostos = append(tiposCompostos, descricao)
+	indiceCompostos[descricao] = tp
+	return tp
+}
+
+// descricao retorna a descrição de um tipo composto
+func (t Tipo) descricao() (descricaoTipo, bool) {
+	mutexCompostos.Lock()
+	defer mutexCompostos.Unlock()
+	indice := int(t - tipoPrimeiroComposto)
+	if indice < 0 || indice >= len(tiposCompostos) {
+		return descricaoTipo{}, false
+	}
+	return tiposCompostos[indice], true
+}
+
+// TipoLista retorna o tipo lista<elemento>
+func TipoLista(elemento Tipo) Tipo {
+	return internarTipo(descricaoTipo{categoria: categoriaLista, elemento: elemento})
+}
+
+// EhLista indica se o tipo é uma lista
+func (t Tipo) EhLista() bool {
+	d, ok := t.descricao()
+	return ok && d.categoria == categoriaLista
+}
+
+// Elemento retorna o tipo dos elementos de uma lista (TipoVazio para outros tipos)
+func (t Tipo) Elemento() Tipo {
+	if d, ok := t.descricao(); ok && d.categoria == categoriaLista {
+		return d.elemento
+	}
+	return TipoVazio
+}
+
+// TipoMapa retorna o tipo mapa<chave, valor>
+func TipoMapa(chave, valor Tipo) Tipo {
+	return internarTipo(descricaoTipo{categoria: categoriaMapa, chave: chave, elemento: valor})
+}
+
+// EhMapa indica se o tipo é um mapa
+func (t Tipo) EhMapa() bool {
+	d, ok := t.descricao()
+	return ok && d.categoria == categoriaMapa
+}
+
+// Chave retorna o tipo das chaves de um mapa (TipoVazio para outros tipos)
+func (t Tipo) Chave() Tipo {
+	if d, ok := t.descricao(); ok && d.categoria == categoriaMapa {
+		return d.chave
+	}
+	return TipoVazio
+}
+
+// Valor retorna o tipo dos valores de um mapa (TipoVazio para outros tipos)
+func (t Tipo) Valor() Tipo {
+	if d, ok := t.descricao(); ok && d.categoria == categoriaMapa {
+		return d.elemento
+	}
+	return TipoVazio
+}
+
+// CampoRegistro representa um campo de um tipo registro com nome e tipo
+type CampoRegistro struct {
+	Nome string
+	Tipo Tipo
+}
+
+// TipoNomeado retorna o tipo declarado pelo usuário com o nome dado (registro ou
+// enumeração); o que ele é fica registrado em TiposDeclarados, uma tabela por compilação
+func TipoNomeado(nome string) Tipo {
+	return internarTipo(descricaoTipo{categoria: categoriaNomeado, nome: nome})
+}
+
+// EhNomeado indica se o tipo é um tipo nomeado, declarado ou não
+func (t Tipo) EhNomeado() bool {
+	d, ok := t.descricao()
+	return ok && d.categoria == categoriaNomeado
+}
+
+// TiposDeclarados guarda os campos dos registros e as variantes das enumerações de uma
+// compilação. O TypeChecker a preenche e os backends a recebem junto com os tipos inferidos
+type TiposDeclarados struct {
+	campos    map[Tipo][]CampoRegistro
+	variantes map[Tipo][]string
+}
+
+// NovosTiposDeclarados cria uma tabela vazia
+func NovosTiposDeclarados() *TiposDeclarados {
+	return &TiposDeclarados{
+		campos:    make(map[Tipo][]CampoRegistro),
+		variantes: make(map[Tipo][]string),
+	}
+}
+
+// Declarado indica se a declaração do tipo nomeado já foi registrada
+func (d *TiposDeclarados) Declarado(t Tipo) bool {
+	return d.EhRegistro(t) || d.EhEnumeracao(t)
+}
+
+// EhRegistro indica se o tipo é um registro
+func (d *TiposDeclarados) EhRegistro(t Tipo) bool {
+	_, ok := d.campos[t]
+	return ok
+}
+
+// DefinirCampos associa os campos declarados ao tipo registro
+func (d *TiposDeclarados) DefinirCampos(t Tipo, campos []CampoRegistro) {
+	d.campos[t] = campos
+}
+
+// Campos retorna os campos de um registro na ordem da declaração (nil para outros tipos)
+func (d *TiposDeclarados) Campos(t Tipo) []CampoRegistro {
+	return d.campos[t]
+}
+
+// Campo retorna a posição e o tipo do campo de um registro; ok é falso se o campo não existe
+func (d *TiposDeclarados) Campo(t Tipo, nome string) (indice int, tipo Tipo, ok bool) {
+	for i, campo := range d.campos[t] {
+		if campo.Nome == nome {
+			return i, campo.Tipo, true
+		}
+	}
+	return 0, TipoVazio, false
+}
+
+// EhEnumeracao indica se o tipo é uma enumeração
+func (d *TiposDeclarados) EhEnumeracao(t Tipo) bool {
+	_, ok := d.variantes[t]
+	return ok
+}
+
+// DefinirVariantes associa as variantes declaradas ao tipo enumeração
+func (d *TiposDeclarados) DefinirVariantes(t Tipo, variantes []string) {
+	d.variantes[t] = variantes
+}
+
+// Variantes retorna as variantes de uma enumeração na ordem da declaração (nil para outros tipos)
+func (d *TiposDeclarados) Variantes(t Tipo) []string {
+	return d.variantes[t]
+}
+
+// Variante retorna a posição da variante na enumeração; ok é falso se ela não existe
+func (d *TiposDeclarados) Variante(t Tipo, nome string) (indice int, ok bool) {
+	for i, variante := range d.variantes[t] {
+		if variante == nome {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// EhChaveMapa indica se o tipo pode ser chave de mapa (inteiro, texto ou booleano)
+func (t Tipo) EhChaveMapa() bool {
+	return t == TipoInteiro || t == TipoTexto || t == TipoBooleano
+}
+
 func (t Tipo) String() string {
 	switch t {
 	case TipoVazio:
@@ -301,9 +816,17 @@ func (t Tipo) String() string {
 		return "texto"
 	case TipoBooleano:
 		return "booleano"
-	default:
-		return "?"
 	}
+	if t.EhLista() {
+		return "lista<" + t.Elemento().String() + ">"
+	}
+	if t.EhMapa() {
+		return "mapa<" + t.Chave().String() + ", " + t.Valor().String() + ">"
+	}
+	if d, ok := t.descricao(); ok && d.categoria == categoriaNomeado {
+		return d.nome
+	}
+	return "?"
 }
 
 // ParametroFuncao representa um parâmetro de função com nome e tipo
@@ -349,6 +872,36 @@ func (r *Retorno) String() string {
 	return fmt.Sprintf("retornar %s", r.Valor.String())
 }
 
+// Parar representa um comando 'parar' (break) na árvore
+type Parar struct {
+	Rotulo string // laço alvo; vazio para o laço mais interno
+	Token  lexer.Token
+}
+
+func (p *Parar) Aceitar(node Node) any { return node.Parar(p) }
+
+func (p *Parar) String() string {
+	if p.Rotulo == "" {
+		return "parar"
+	}
+	return fmt.Sprintf("parar %s", p.Rotulo)
+}
+
+// Continuar representa um comando 'continuar' (continue) na árvore
+type Continuar struct {
+	Rotulo string // laço alvo; vazio para o laço mais interno
+	Token  lexer.Token
+}
+
+func (c *Continuar) Aceitar(node Node) any { return node.Continuar(c) }
+
+func (c *Continuar) String() string {
+	if c.Rotulo == "" {
+		return "continuar"
+	}
+	return fmt.Sprintf("continuar %s", c.Rotulo)
+}
+
 // Importacao representa uma declaração de importação na árvore
 type Importacao struct {
 	Simbolos []string // Lista de símbolos a importar (função/variável)
@@ -1,8 +1,10 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/khevencolino/Solar/internal/lexer"
 	"github.com/khevencolino/Solar/internal/utils"
@@ -18,9 +20,15 @@ type Precedencia int
 
 const (
 	PRECEDENCIA_NENHUMA       Precedencia = iota
+	PRECEDENCIA_OU                        // ou ||
+	PRECEDENCIA_E                         // e &&
 	PRECEDENCIA_COMPARACAO                // == != < > <= >=
+	PRECEDENCIA_BIT_OU                    // |
+	PRECEDENCIA_BIT_XOU                   // ^
+	PRECEDENCIA_BIT_E                     // &
+	PRECEDENCIA_DESLOCAMENTO              // << >>
 	PRECEDENCIA_SOMA                      // + -
-	PRECEDENCIA_MULTIPLICACAO             // * /
+	PRECEDENCIA_MULTIPLICACAO             // * / %
 	PRECEDENCIA_POTENCIA                  // **
 )
 
@@ -28,16 +36,34 @@ const (
 type Parser struct {
 	tokens       []lexer.Token
 	posicaoAtual int
+	// Nomes que podem ser tipos declarados (registros e enumerações): os declarados no
+	// arquivo e os importados. Só eles são aceitos como tipo e iniciam uma construção (Ponto{...})
+	tiposNomeados map[string]bool
 }
 
+// TipoImportado informa se o símbolo exportado pelo módulo é um registro ou uma enumeração
+type TipoImportado func(modulo, simbolo string) bool
+
 // obterPrecedencia retorna a precedência de um operador
 func (p *Parser) obterPrecedencia(tokenType lexer.TokenType) Precedencia {
 	switch tokenType {
+	case lexer.OU:
+		return PRECEDENCIA_OU
+	case lexer.E:
+		return PRECEDENCIA_E
 	case lexer.EQUAL, lexer.NOT_EQUAL, lexer.LESS, lexer.GREATER, lexer.LESS_EQUAL, lexer.GREATER_EQUAL:
 		return PRECEDENCIA_COMPARACAO
+	case lexer.BIT_OR:
+		return PRECEDENCIA_BIT_OU
+	case lexer.BIT_XOR:
+		return PRECEDENCIA_BIT_XOU
+	case lexer.BIT_AND:
+		return PRECEDENCIA_BIT_E
+	case lexer.SHIFT_LEFT, lexer.SHIFT_RIGHT:
+		return PRECEDENCIA_DESLOCAMENTO
 	case lexer.PLUS, lexer.MINUS:
 		return PRECEDENCIA_SOMA
-	case lexer.MULTIPLY, lexer.DIVIDE:
+	case lexer.MULTIPLY, lexer.DIVIDE, lexer.MODULO:
 		return PRECEDENCIA_MULTIPLICACAO
 	case lexer.POWER:
 		return PRECEDENCIA_POTENCIA
@@ -53,12 +79,52 @@ func (p *Parser) ehAssociativoADireita(tokenType lexer.TokenType) bool {
 
 // NovoParser cria um novo analisador sintático
 func NovoParser(tokens []lexer.Token) *Parser {
+	return NovoParserComImportacoes(tokens, nil)
+}
+
+// NovoParserComImportacoes cria um analisador sintático que consulta tipoImportado para
+// saber quais símbolos importados são tipos (sem ele, nenhum símbolo importado é tipo)
+func NovoParserComImportacoes(tokens []lexer.Token, tipoImportado TipoImportado) *Parser {
 	return &Parser{
-		tokens:       tokens,
-		posicaoAtual: 0,
+		tokens:        tokens,
+		posicaoAtual:  0,
+		tiposNomeados: coletarNomesTipos(tokens, tipoImportado),
 	}
 }
 
+// coletarNomesTipos percorre os tokens antes da análise para que um registro ou uma
+// enumeração possa ser usado antes da sua declaração. Dos símbolos importados, só entram
+// os que o módulo exporta como tipo: uma variável importada seguida de '{' (para cada n
+// em numeros { ... }) não pode ser lida como construção de registro
+func coletarNomesTipos(tokens []lexer.Token, tipoImportado TipoImportado) map[string]bool {
+	nomes := make(map[string]bool)
+	for i := 0; i+1 < len(tokens); i++ {
+		switch tokens[i].Type {
+		case lexer.REGISTRO, lexer.ENUMERACAO:
+			if tokens[i+1].Type == lexer.IDENTIFIER {
+				nomes[tokens[i+1].Value] = true
+			}
+		case lexer.IMPORTAR:
+			var simbolos []string
+			j := i + 1
+			for ; j < len(tokens) && (tokens[j].Type == lexer.IDENTIFIER || tokens[j].Type == lexer.COMMA); j++ {
+				if tokens[j].Type == lexer.IDENTIFIER {
+					simbolos = append(simbolos, tokens[j].Value)
+				}
+			}
+			if tipoImportado == nil || j+1 >= len(tokens) || tokens[j].Type != lexer.DE || tokens[j+1].Type != lexer.IDENTIFIER {
+				continue
+			}
+			for _, simbolo := range simbolos {
+				if tipoImportado(tokens[j+1].Value, simbolo) {
+					nomes[simbolo] = true
+				}
+			}
+		}
+	}
+	return nomes
+}
+
 // AnalisarPrograma analisa um programa
 func (p *Parser) AnalisarPrograma() ([]Expressao, error) {
 	var statements []Expressao
@@ -115,6 +181,34 @@ func (p *Parser) analisarStatement() (Expressao, error) {
 		return p.analisarImportacao()
 	}
 
+	// registro Nome { campo: tipo, ... }
+	if token.Type == lexer.REGISTRO {
+		return p.analisarDeclaracaoRegistro()
+	}
+
+	// enumeracao Nome { Variante, ... }
+	if token.Type == lexer.ENUMERACAO {
+		return p.analisarDeclaracaoEnumeracao()
+	}
+
+	// escolha valor { caso ... } como comando; dentro de expressões é analisada em analisarPrefixo
+	if token.Type == lexer.ESCOLHA {
+		p.proximoToken() // consome 'escolha'
+		return p.analisarEscolha(token, false)
+	}
+
+	// parar / continuar [rótulo]
+	if token.Type == lexer.PARAR || token.Type == lexer.CONTINUAR {
+		return p.analisarControleLaco()
+	}
+
+	// Laço rotulado: rotulo: enquanto ... / rotulo: para ...
+	if token.Type == lexer.IDENTIFIER && p.espiarToken(1).Type == lexer.COLON {
+		if laco := p.espiarToken(2).Type; laco == lexer.ENQUANTO || laco == lexer.PARA {
+			return p.analisarLacoRotulado()
+		}
+	}
+
 	// Verifica se é início de IDENTIFIER que pode ser atribuição, chamada de função ou simples variável
 	if token.Type == lexer.IDENTIFIER {
 		p.proximoToken() // consome o identificador
@@ -139,10 +233,17 @@ func (p *Parser) analisarStatement() (Expressao, error) {
 		default:
 			// Não era atribuição nem chamada: retrocede e trata como expressão comum
 			p.posicaoAtual--
-			return p.analisarExpressao(PRECEDENCIA_NENHUMA)
+			return p.analisarExpressaoOuAtribuicaoIndice()
 		}
 	}
 
+	// '{' no início de um comando abre um bloco (com escopo próprio); um mapa literal
+	// só é reconhecido em posição de expressão (valor de atribuição, argumento, ...)
+	if token.Type == lexer.LBRACE {
+		p.proximoToken() // consome '{'
+		return p.analisarBloco()
+	}
+
 	// Caso contrário, analisa como expressão
 	return p.analisarExpressao(PRECEDENCIA_NENHUMA)
 }
@@ -162,6 +263,49 @@ func (p *Parser) analisarRetorno() (Expressao, error) {
 	return &Retorno{Valor: expr, Token: tok}, nil
 }
 
+// analisarControleLaco: ('parar' | 'continuar') rotulo? ';'
+// O rótulo precisa estar na mesma linha do comando (o ';' é opcional)
+func (p *Parser) analisarControleLaco() (Expressao, error) {
+	tok := p.proximoToken() // consome 'parar' ou 'continuar'
+	rotulo := ""
+	if t := p.tokenAtual(); t.Type == lexer.IDENTIFIER && t.Position.Line == tok.Position.Line {
+		rotulo = t.Value
+		p.proximoToken() // consome o rótulo
+	}
+	if tok.Type == lexer.PARAR {
+		return &Parar{Rotulo: rotulo, Token: tok}, nil
+	}
+	return &Continuar{Rotulo: rotulo, Token: tok}, nil
+}
+
+// analisarLacoRotulado: IDENT ':' ('enquanto' | 'para') ...
+func (p *Parser) analisarLacoRotulado() (Expressao, error) {
+	rotulo := p.proximoToken().Value // consome o rótulo
+	p.proximoToken()                 // consome ':'
+
+	if p.tokenAtual().Type == lexer.ENQUANTO {
+		laco, err := p.analisarComandoEnquanto()
+		if err != nil {
+			return nil, err
+		}
+		laco.(*ComandoEnquanto).Rotulo = rotulo
+		return laco, nil
+	}
+	laco, err := p.analisarComandoPara()
+	if err != nil {
+		return nil, err
+	}
+	switch l := laco.(type) {
+	case *ComandoPara:
+		l.Rotulo = rotulo
+	case *ComandoParaCada:
+		l.Rotulo = rotulo
+	case *ComandoParaIntervalo:
+		l.Rotulo = rotulo
+	}
+	return laco, nil
+}
+
 // analisarImportacao: 'importar' simbolos 'de' modulo ';'
 // Suporta: importar imprime de io;
 //
@@ -211,11 +355,15 @@ func (p *Parser) analisarImportacao() (Expressao, error) {
 
 // analisarExpressao implementa precedência de operadores usando o algoritmo Pratt
 func (p *Parser) analisarExpressao(precedenciaMinima Precedencia) (Expressao, error) {
-	// Analisa o lado esquerdo (prefixo)
+	// Analisa o lado esquerdo (prefixo) e os acessos a elemento que o seguem
 	esquerda, err := p.analisarPrefixo()
 	if err != nil {
 		return nil, err
 	}
+	esquerda, err = p.analisarAcessos(esquerda)
+	if err != nil {
+		return nil, err
+	}
 
 	// Processa operadores binários com precedência adequada
 	for {
@@ -270,33 +418,31 @@ func (p *Parser) analisarPrefixo() (Expressao, error) {
 
 	switch token.Type {
 	case lexer.NUMBER:
-		valor, err := strconv.Atoi(token.Value)
+		valor, err := p.converterInteiro(token, token.Value)
 		if err != nil {
-			return nil, utils.NovoErro(
-				"erro ao converter número",
-				token.Position.Line,
-				token.Position.Column,
-				err.Error(),
-			)
+			return nil, err
 		}
 		return &Constante{Valor: valor, Token: token}, nil
 
 	case lexer.FLOAT:
-		valor, err := strconv.ParseFloat(token.Value, 64)
+		valor, err := p.converterDecimal(token)
 		if err != nil {
-			return nil, utils.NovoErro(
-				"erro ao converter número decimal",
-				token.Position.Line,
-				token.Position.Column,
-				err.Error(),
-			)
+			return nil, err
 		}
 		return &LiteralDecimal{Valor: valor, Token: token}, nil
 
 	case lexer.STRING:
-		// Remove as aspas do início e fim
-		valor := token.Value[1 : len(token.Value)-1]
-		return &LiteralTexto{Valor: valor, Token: token}, nil
+		// O lexer já removeu as aspas e decodificou os escapes
+		return &LiteralTexto{Valor: token.Value, Token: token}, nil
+
+	case lexer.STRING_START:
+		return p.analisarTextoInterpolado(token)
+
+	case lexer.LBRACKET:
+		return p.analisarListaLiteral(token)
+
+	case lexer.LBRACE:
+		return p.analisarMapaLiteral(token)
 
 	case lexer.VERDADEIRO:
 		return &Booleano{Valor: true, Token: token}, nil
@@ -304,52 +450,65 @@ func (p *Parser) analisarPrefixo() (Expressao, error) {
 		return &Booleano{Valor: false, Token: token}, nil
 
 	case lexer.MINUS:
-		// Operador unário negativo
-		proximo := p.proximoToken()
-		switch proximo.Type {
-		case lexer.NUMBER:
-			valor, err := strconv.Atoi(proximo.Value)
-			if err != nil {
-				return nil, utils.NovoErro(
-					"erro ao converter número negativo",
-					proximo.Position.Line,
-					proximo.Position.Column,
-					err.Error(),
-				)
-			}
-			return &Constante{Valor: -valor, Token: token}, nil
-		case lexer.FLOAT:
-			valor, err := strconv.ParseFloat(proximo.Value, 64)
+		// Literal inteiro negativo: convertido com o sinal para aceitar o menor inteiro (-9223372036854775808)
+		if numero := p.tokenAtual(); numero.Type == lexer.NUMBER && p.espiarToken(1).Type != lexer.POWER {
+			p.proximoToken()
+			valor, err := p.converterInteiro(numero, "-"+numero.Value)
 			if err != nil {
-				return nil, utils.NovoErro(
-					"erro ao converter número decimal negativo",
-					proximo.Position.Line,
-					proximo.Position.Column,
-					err.Error(),
-				)
+				return nil, err
 			}
-			return &LiteralDecimal{Valor: -valor, Token: token}, nil
-		default:
-			// Se não for número, retorna erro
-			return nil, utils.NovoErro(
-				"operador negativo deve ser seguido de número",
-				proximo.Position.Line,
-				proximo.Position.Column,
-				fmt.Sprintf("encontrado %s", proximo.Type.String()),
-			)
+			return &Constante{Valor: valor, Token: token}, nil
 		}
 
+		// Menos unário: liga mais fraco que ** (-2 ** 2 é -(2 ** 2)) e mais forte que * e /
+		operando, err := p.analisarExpressao(PRECEDENCIA_POTENCIA)
+		if err != nil {
+			return nil, err
+		}
+		// Literais negativos continuam sendo literais
+		switch literal := operando.(type) {
+		case *Constante:
+			return &Constante{Valor: -literal.Valor, Token: token}, nil
+		case *LiteralDecimal:
+			return &LiteralDecimal{Valor: -literal.Valor, Token: token}, nil
+		}
+		return &OperacaoUnaria{Operador: NEGACAO, Operando: operando, Token: token}, nil
+
+	case lexer.NAO:
+		// Negação lógica: o operando vai até o fim da comparação (nao a == b é nao (a == b))
+		operando, err := p.analisarExpressao(PRECEDENCIA_COMPARACAO)
+		if err != nil {
+			return nil, err
+		}
+		return &OperacaoUnaria{Operador: NAO_LOGICO, Operando: operando, Token: token}, nil
+
+	case lexer.BIT_NOT:
+		// Complemento bit a bit: mesma força de ligação do menos unário
+		operando, err := p.analisarExpressao(PRECEDENCIA_POTENCIA)
+		if err != nil {
+			return nil, err
+		}
+		return &OperacaoUnaria{Operador: NAO_BIT, Operando: operando, Token: token}, nil
+
 	case lexer.IDENTIFIER:
-		// Pode ser variável ou início de chamada de função do usuário
+		// Pode ser variável, início de chamada de função do usuário ou construção de registro
 		if p.tokenAtual().Type == lexer.LPAREN {
 			return p.analisarChamadaFuncao(lexer.NovoToken(lexer.FUNCTION, token.Value, token.Position))
 		}
+		if p.tokenAtual().Type == lexer.LBRACE && p.tiposNomeados[token.Value] {
+			p.proximoToken() // consome '{'
+			return p.analisarRegistroLiteral(token)
+		}
 		return &Variavel{Nome: token.Value, Token: token}, nil
 
 	case lexer.FUNCTION:
 		// Chamada de função
 		return p.analisarChamadaFuncao(token)
 
+	case lexer.ESCOLHA:
+		// Escolha usada como valor (x ~> escolha c { ... })
+		return p.analisarEscolha(token, true)
+
 	case lexer.LPAREN:
 		// Expressão parentizada
 		expressao, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
@@ -369,9 +528,368 @@ func (p *Parser) analisarPrefixo() (Expressao, error) {
 			"expressão inválida",
 			token.Position.Line,
 			token.Position.Column,
-			fmt.Sprintf("esperado número, variável ou '(', encontrado '%s'", token.Value),
+			fmt.Sprintf("esperado número, variável, '(', '[' ou '{', encontrado '%s'", token.Value),
+		)
+	}
+}
+
+// analisarListaLiteral analisa os elementos de uma lista literal a partir do '[' já consumido
+func (p *Parser) analisarListaLiteral(inicio lexer.Token) (Expressao, error) {
+	lista := &ListaLiteral{Token: inicio}
+	for p.tokenAtual().Type != lexer.RBRACKET {
+		elemento, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		lista.Elementos = append(lista.Elementos, elemento)
+
+		if p.tokenAtual().Type != lexer.COMMA {
+			break
+		}
+		p.proximoToken() // consome ',' (vírgula final permitida)
+	}
+	if err := p.verificarProximoToken(lexer.RBRACKET); err != nil {
+		return nil, err
+	}
+	return lista, nil
+}
+
+// analisarMapaLiteral analisa as entradas 'chave: valor' de um mapa literal a partir do
+// '{' já consumido
+func (p *Parser) analisarMapaLiteral(inicio lexer.Token) (Expressao, error) {
+	mapa := &MapaLiteral{Token: inicio}
+	for p.tokenAtual().Type != lexer.RBRACE {
+		chave, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.verificarProximoToken(lexer.COLON); err != nil {
+			return nil, err
+		}
+		valor, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		mapa.Chaves = append(mapa.Chaves, chave)
+		mapa.Valores = append(mapa.Valores, valor)
+
+		if p.tokenAtual().Type != lexer.COMMA {
+			break
+		}
+		p.proximoToken() // consome ',' (vírgula final permitida)
+	}
+	if err := p.verificarProximoToken(lexer.RBRACE); err != nil {
+		return nil, err
+	}
+	return mapa, nil
+}
+
+// analisarRegistroLiteral analisa os campos 'nome: valor' da construção de um registro a
+// partir do '{' já consumido
+func (p *Parser) analisarRegistroLiteral(nome lexer.Token) (Expressao, error) {
+	registro := &RegistroLiteral{Nome: nome.Value, Token: nome}
+	for p.tokenAtual().Type != lexer.RBRACE {
+		campo := p.tokenAtual()
+		if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+			return nil, err
+		}
+		if err := p.verificarProximoToken(lexer.COLON); err != nil {
+			return nil, err
+		}
+		valor, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		registro.Campos = append(registro.Campos, campo.Value)
+		registro.Valores = append(registro.Valores, valor)
+
+		if p.tokenAtual().Type != lexer.COMMA {
+			break
+		}
+		p.proximoToken() // consome ',' (vírgula final permitida)
+	}
+	if err := p.verificarProximoToken(lexer.RBRACE); err != nil {
+		return nil, err
+	}
+	return registro, nil
+}
+
+// analisarDeclaracaoRegistro: 'registro' IDENT '{' (IDENT ':' tipo ','?)+ '}'
+// A vírgula entre os campos é opcional, como o ';' entre comandos
+func (p *Parser) analisarDeclaracaoRegistro() (Expressao, error) {
+	tok := p.proximoToken() // consome 'registro'
+	nomeTok := p.proximoToken()
+	if nomeTok.Type != lexer.IDENTIFIER {
+		return nil, utils.NovoErro("nome de registro inválido", nomeTok.Position.Line, nomeTok.Position.Column, "esperado identificador após 'registro'")
+	}
+	if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
+		return nil, err
+	}
+
+	registro := &RegistroDeclaracao{Nome: nomeTok.Value, Token: tok}
+	for p.tokenAtual().Type != lexer.RBRACE {
+		campo := p.tokenAtual()
+		if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+			return nil, err
+		}
+		if err := p.verificarProximoToken(lexer.COLON); err != nil {
+			return nil, err
+		}
+		tp, err := p.analisarTipo()
+		if err != nil {
+			return nil, err
+		}
+		registro.Campos = append(registro.Campos, CampoRegistro{Nome: campo.Value, Tipo: tp})
+
+		if p.tokenAtual().Type == lexer.COMMA {
+			p.proximoToken()
+		}
+	}
+	if err := p.verificarProximoToken(lexer.RBRACE); err != nil {
+		return nil, err
+	}
+	if len(registro.Campos) == 0 {
+		return nil, utils.NovoErro("registro sem campos", nomeTok.Position.Line, nomeTok.Position.Column,
+			fmt.Sprintf("o registro '%s' precisa declarar ao menos um campo", nomeTok.Value))
+	}
+	return registro, nil
+}
+
+// analisarDeclaracaoEnumeracao: 'enumeracao' IDENT '{' IDENT (',' IDENT)* ','? '}'
+func (p *Parser) analisarDeclaracaoEnumeracao() (Expressao, error) {
+	tok := p.proximoToken() // consome 'enumeracao'
+	nomeTok := p.proximoToken()
+	if nomeTok.Type != lexer.IDENTIFIER {
+		return nil, utils.NovoErro("nome de enumeração inválido", nomeTok.Position.Line, nomeTok.Position.Column, "esperado identificador após 'enumeracao'")
+	}
+	if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
+		return nil, err
+	}
+
+	enumeracao := &EnumeracaoDeclaracao{Nome: nomeTok.Value, Token: tok}
+	for p.tokenAtual().Type != lexer.RBRACE {
+		variante := p.tokenAtual()
+		if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+			return nil, err
+		}
+		if variante.Value == "_" {
+			return nil, utils.NovoErro("variante inválida", variante.Position.Line, variante.Position.Column, "'_' é reservado para o caso padrão de escolha")
+		}
+		enumeracao.Variantes = append(enumeracao.Variantes, variante.Value)
+
+		if p.tokenAtual().Type != lexer.COMMA {
+			break
+		}
+		p.proximoToken() // consome ',' (vírgula final permitida)
+	}
+	if err := p.verificarProximoToken(lexer.RBRACE); err != nil {
+		return nil, err
+	}
+	if len(enumeracao.Variantes) == 0 {
+		return nil, utils.NovoErro("enumeração sem variantes", nomeTok.Position.Line, nomeTok.Position.Column,
+			fmt.Sprintf("a enumeração '%s' precisa declarar ao menos uma variante", nomeTok.Value))
+	}
+	return enumeracao, nil
+}
+
+// analisarEscolha: 'escolha' expressao '{' ('caso' padrao '=>' (bloco | expressao | atribuicao) ','?)+ '}'
+// a partir do 'escolha' já consumido. A vírgula entre os casos é opcional
+func (p *Parser) analisarEscolha(tok lexer.Token, comoValor bool) (Expressao, error) {
+	valor, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
+		return nil, err
+	}
+
+	escolha := &Escolha{Valor: valor, ComoValor: comoValor, Token: tok}
+	for p.tokenAtual().Type != lexer.RBRACE {
+		casoTok := p.tokenAtual()
+		if err := p.verificarProximoToken(lexer.CASO); err != nil {
+			return nil, err
+		}
+		padrao, err := p.analisarPadraoCaso()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.verificarProximoToken(lexer.SETA); err != nil {
+			return nil, err
+		}
+		var corpo Expressao
+		if p.tokenAtual().Type == lexer.LBRACE {
+			p.proximoToken() // consome '{'
+			corpo, err = p.analisarBloco()
+		} else {
+			// Sem chaves, o corpo é uma expressão ou uma atribuição (caso 0 => n ~> n + 1)
+			corpo, err = p.analisarAtribOuExpressao()
+		}
+		if err != nil {
+			return nil, err
+		}
+		escolha.Casos = append(escolha.Casos, CasoEscolha{Padrao: padrao, Corpo: corpo, Token: casoTok})
+
+		if p.tokenAtual().Type == lexer.COMMA || p.tokenAtual().Type == lexer.SEMICOLON {
+			p.proximoToken()
+		}
+	}
+	if err := p.verificarProximoToken(lexer.RBRACE); err != nil {
+		return nil, err
+	}
+	if len(escolha.Casos) == 0 {
+		return nil, utils.NovoErro("escolha sem casos", tok.Position.Line, tok.Position.Column, "a escolha precisa de ao menos um 'caso'")
+	}
+	return escolha, nil
+}
+
+// analisarPadraoCaso analisa o padrão de um caso: '_' (qualquer valor, devolvido como
+// nil), uma variante de enumeração, um inteiro literal (com sinal) ou um texto literal
+func (p *Parser) analisarPadraoCaso() (Expressao, error) {
+	token := p.tokenAtual()
+	switch token.Type {
+	case lexer.IDENTIFIER:
+		p.proximoToken()
+		if token.Value == "_" {
+			return nil, nil
+		}
+		return &Variavel{Nome: token.Value, Token: token}, nil
+	case lexer.NUMBER, lexer.MINUS, lexer.STRING:
+		padrao, err := p.analisarPrefixo()
+		if err != nil {
+			return nil, err
+		}
+		switch padrao.(type) {
+		case *Constante, *LiteralTexto:
+			return padrao, nil
+		}
+	}
+	return nil, utils.NovoErro("padrão de caso inválido", token.Position.Line, token.Position.Column,
+		fmt.Sprintf("esperado variante de enumeração, inteiro, texto ou '_', encontrado '%s'", token.Value))
+}
+
+// analisarAcessos analisa os acessos a elemento e a campo após uma expressão: xs[i],
+// m[i][j], p.x, xs[0].centro.x
+func (p *Parser) analisarAcessos(expressao Expressao) (Expressao, error) {
+	for p.tokenAtual().Type == lexer.LBRACKET || p.tokenAtual().Type == lexer.PONTO {
+		tok := p.proximoToken() // consome '[' ou '.'
+		if tok.Type == lexer.PONTO {
+			campo := p.tokenAtual()
+			if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+				return nil, err
+			}
+			expressao = &AcessoCampo{Objeto: expressao, Campo: campo.Value, Token: campo}
+			continue
+		}
+		indice, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.verificarProximoToken(lexer.RBRACKET); err != nil {
+			return nil, err
+		}
+		expressao = &Indexacao{Colecao: expressao, Indice: indice, Token: tok}
+	}
+	return expressao, nil
+}
+
+// analisarTextoInterpolado analisa um texto com interpolações a partir do STRING_START já
+// consumido: cada expressão é seguida de um STRING_MIDDLE ou do STRING_END que fecha o texto
+func (p *Parser) analisarTextoInterpolado(inicio lexer.Token) (Expressao, error) {
+	texto := &TextoInterpolado{Token: inicio}
+	parte := inicio
+	for {
+		if parte.Value != "" {
+			texto.Partes = append(texto.Partes, &LiteralTexto{Valor: parte.Value, Token: parte})
+		}
+		if parte.Type == lexer.STRING_END {
+			return texto, nil
+		}
+
+		expressao, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		texto.Partes = append(texto.Partes, expressao)
+
+		parte = p.proximoToken()
+		if parte.Type != lexer.STRING_MIDDLE && parte.Type != lexer.STRING_END {
+			return nil, utils.NovoErro(
+				"interpolação inválida",
+				parte.Position.Line,
+				parte.Position.Column,
+				fmt.Sprintf("esperado '}' após a expressão, encontrado '%s'", parte.Value),
+			)
+		}
+	}
+}
+
+// converterInteiro converte o texto de um literal inteiro (decimal, 0x, 0b ou 0o, com separadores '_')
+// verificando se o valor cabe em 64 bits
+func (p *Parser) converterInteiro(token lexer.Token, texto string) (int, error) {
+	base := 10
+	digitos := strings.TrimPrefix(texto, "-")
+	if len(digitos) > 1 && digitos[0] == '0' && strings.ContainsAny(digitos[1:2], "xXbBoO") {
+		base = 0 // strconv detecta a base pelo prefixo
+	} else {
+		texto = strings.ReplaceAll(texto, "_", "")
+	}
+
+	valor, err := strconv.ParseInt(texto, base, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, utils.NovoErro(
+				"número inteiro fora do intervalo",
+				token.Position.Line,
+				token.Position.Column,
+				fmt.Sprintf("'%s' não cabe em 64 bits", texto),
+			)
+		}
+		return 0, utils.NovoErro(
+			"erro ao converter número",
+			token.Position.Line,
+			token.Position.Column,
+			err.Error(),
+		)
+	}
+	return int(valor), nil
+}
+
+// converterDecimal converte o texto de um literal decimal; literais que transbordam
+// são rejeitados (infinito e nan vêm do módulo math)
+func (p *Parser) converterDecimal(token lexer.Token) (float64, error) {
+	texto := strings.ReplaceAll(token.Value, "_", "")
+	valor, err := strconv.ParseFloat(texto, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, utils.NovoErro(
+				"número decimal fora do intervalo",
+				token.Position.Line,
+				token.Position.Column,
+				fmt.Sprintf("'%s' excede o maior decimal representável", token.Value),
+			)
+		}
+		return 0, utils.NovoErro(
+			"erro ao converter número decimal",
+			token.Position.Line,
+			token.Position.Column,
+			err.Error(),
 		)
 	}
+	// ParseFloat arredonda para 0 um valor pequeno demais sem erro: um literal com dígitos
+	// não nulos que vira 0 também está fora do intervalo
+	mantissa := texto
+	if e := strings.IndexAny(texto, "eE"); e >= 0 {
+		mantissa = texto[:e]
+	}
+	if valor == 0 && strings.ContainsAny(mantissa, "123456789") {
+		return 0, utils.NovoErro(
+			"número decimal fora do intervalo",
+			token.Position.Line,
+			token.Position.Column,
+			fmt.Sprintf("'%s' é menor que o menor decimal representável", token.Value),
+		)
+	}
+	return valor, nil
 }
 
 // tokenParaOperador converte um token em um TipoOperador
@@ -387,6 +905,8 @@ func (p *Parser) tokenParaOperador(token lexer.Token) (TipoOperador, error) {
 		return POWER, nil
 	case lexer.DIVIDE:
 		return DIVISAO, nil
+	case lexer.MODULO:
+		return RESTO, nil
 	case lexer.EQUAL:
 		return IGUALDADE, nil
 	case lexer.NOT_EQUAL:
@@ -399,12 +919,26 @@ func (p *Parser) tokenParaOperador(token lexer.Token) (TipoOperador, error) {
 		return MENOR_IGUAL, nil
 	case lexer.GREATER_EQUAL:
 		return MAIOR_IGUAL, nil
+	case lexer.E:
+		return E_LOGICO, nil
+	case lexer.OU:
+		return OU_LOGICO, nil
+	case lexer.BIT_AND:
+		return E_BIT, nil
+	case lexer.BIT_OR:
+		return OU_BIT, nil
+	case lexer.BIT_XOR:
+		return XOU_BIT, nil
+	case lexer.SHIFT_LEFT:
+		return DESLOCAMENTO_ESQUERDA, nil
+	case lexer.SHIFT_RIGHT:
+		return DESLOCAMENTO_DIREITA, nil
 	default:
 		return 0, utils.NovoErro(
 			"operador inválido",
 			token.Position.Line,
 			token.Position.Column,
-			fmt.Sprintf("esperado operador (+, -, *, /, **, ==, !=, <, >, <=, >=), encontrado '%s'", token.Value),
+			fmt.Sprintf("esperado operador (+, -, *, /, %%, **, ==, !=, <, >, <=, >=, e, ou, &, |, ^, <<, >>), encontrado '%s'", token.Value),
 		)
 	}
 }
@@ -477,13 +1011,9 @@ func (p *Parser) analisarDeclaracaoFuncao() (Expressao, error) {
 			if p.tokenAtual().Type == lexer.COLON {
 				p.proximoToken() // consumir ':'
 
-				tTok := p.proximoToken()
-				if tTok.Type != lexer.IDENTIFIER {
-					return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, "esperado identificador de tipo")
-				}
-				tp, err := p.parseTipoPorNome(tTok.Value)
+				tp, err := p.analisarTipo()
 				if err != nil {
-					return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, err.Error())
+					return nil, err
 				}
 				paramTipo = tp
 			}
@@ -506,13 +1036,9 @@ func (p *Parser) analisarDeclaracaoFuncao() (Expressao, error) {
 	var retorno Tipo = TipoInteiro
 	if p.tokenAtual().Type == lexer.COLON {
 		p.proximoToken()
-		tTok := p.proximoToken()
-		if tTok.Type != lexer.IDENTIFIER {
-			return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, "esperado identificador de tipo")
-		}
-		tp, err := p.parseTipoPorNome(tTok.Value)
+		tp, err := p.analisarTipo()
 		if err != nil {
-			return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, err.Error())
+			return nil, err
 		}
 		retorno = tp
 	}
@@ -543,7 +1069,7 @@ func (p *Parser) parseTipoPorNome(nome string) (Tipo, error) {
 	case "booleano", "Booleano":
 		return TipoBooleano, nil
 	default:
-		return 0, fmt.Errorf("tipo desconhecido '%s' (suportado: inteiro, decimal, texto, vazio, booleano)", nome)
+		return 0, fmt.Errorf("tipo desconhecido '%s' (suportado: inteiro, decimal, texto, vazio, booleano, lista<tipo>, mapa<chave, valor> ou um registro ou enumeração declarados)", nome)
 	}
 }
 
@@ -572,6 +1098,14 @@ func (p *Parser) verificarProximoToken(tipoEsperado lexer.TokenType) error {
 	return nil
 }
 
+// espiarToken retorna o token 'deslocamento' posições à frente sem avançar
+func (p *Parser) espiarToken(deslocamento int) lexer.Token {
+	if p.posicaoAtual+deslocamento >= len(p.tokens) {
+		return lexer.NovoToken(lexer.EOF, "", lexer.NovaPosicao(0, 0, 0))
+	}
+	return p.tokens[p.posicaoAtual+deslocamento]
+}
+
 // tokenAtual retorna o token atual sem avançar
 func (p *Parser) tokenAtual() lexer.Token {
 	if p.chegouAoFim() {
@@ -614,11 +1148,29 @@ func (p *Parser) analisarComandoSe() (Expressao, error) {
 		return nil, fmt.Errorf("erro ao analisar bloco 'se': %v", err)
 	}
 
-	// Verifica se há "senao"
+	// Verifica se há "senao se" (quantos houver) e "senao"
+	var senaoSe []*RamoSenaoSe
 	var blocoSenao *Bloco
-	if p.tokenAtual().Type == lexer.SENAO {
+	for p.tokenAtual().Type == lexer.SENAO {
 		p.proximoToken() // consome "senao"
 
+		if p.tokenAtual().Type == lexer.SE {
+			tokenRamo := p.proximoToken() // consome "se"
+			condicaoRamo, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao analisar condição do 'senao se': %v", err)
+			}
+			if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
+				return nil, fmt.Errorf("esperado '{' após condição do 'senao se': %v", err)
+			}
+			blocoRamo, err := p.analisarBloco()
+			if err != nil {
+				return nil, fmt.Errorf("erro ao analisar bloco 'senao se': %v", err)
+			}
+			senaoSe = append(senaoSe, &RamoSenaoSe{Condicao: condicaoRamo, Bloco: blocoRamo, Token: tokenRamo})
+			continue
+		}
+
 		// Espera '{'
 		if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
 			return nil, err
@@ -628,11 +1180,13 @@ func (p *Parser) analisarComandoSe() (Expressao, error) {
 		if err != nil {
 			return nil, err
 		}
+		break
 	}
 
 	return &ComandoSe{
 		Condicao:   condicao,
 		BlocoSe:    blocoSe,
+		SenaoSe:    senaoSe,
 		BlocoSenao: blocoSenao,
 		Token:      tokenSe,
 	}, nil
@@ -685,11 +1239,13 @@ func (p *Parser) analisarComandoEnquanto() (Expressao, error) {
 }
 
 // analisarComandoPara: 'para' '(' init? ';' cond? ';' pos? ')' '{' bloco '}'
+// ou as formas de iteração (ver analisarParaIteracao)
 func (p *Parser) analisarComandoPara() (Expressao, error) {
 	tok := p.proximoToken() // consumir 'para'
-	if err := p.verificarProximoToken(lexer.LPAREN); err != nil {
-		return nil, err
+	if p.tokenAtual().Type != lexer.LPAREN {
+		return p.analisarParaIteracao(tok)
 	}
+	p.proximoToken() // consome '('
 	// init (pode ser vazio)
 	var init Expressao
 	if p.tokenAtual().Type != lexer.SEMICOLON {
@@ -736,6 +1292,91 @@ func (p *Parser) analisarComandoPara() (Expressao, error) {
 	return &ComandoPara{Inicializacao: init, Condicao: cond, PosIteracao: pos, Corpo: corpo, Token: tok}, nil
 }
 
+// analisarParaIteracao: 'para' 'cada'? IDENT (',' IDENT)? iteracao '{' bloco '}', onde iteracao é
+//
+//	'em' expr                            (elementos de uma lista ou chaves e valores de um mapa)
+//	'em' expr '..' expr ('passo' expr)?  (intervalo sem o fim)
+//	'de' expr 'ate' expr ('passo' expr)? (intervalo com o fim)
+func (p *Parser) analisarParaIteracao(tok lexer.Token) (Expressao, error) {
+	cada := p.tokenAtual().Type == lexer.CADA
+	if cada {
+		p.proximoToken() // consome 'cada'
+	}
+	variavel := p.tokenAtual()
+	if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+		return nil, fmt.Errorf("esperado '(' ou a variável do laço após 'para': %v", err)
+	}
+	valor := ""
+	if p.tokenAtual().Type == lexer.COMMA {
+		p.proximoToken() // consome ','
+		tokValor := p.tokenAtual()
+		if err := p.verificarProximoToken(lexer.IDENTIFIER); err != nil {
+			return nil, fmt.Errorf("esperado a variável do valor após ',': %v", err)
+		}
+		valor = tokValor.Value
+	}
+
+	separador := p.proximoToken() // consome 'em' ou 'de'
+	if separador.Type != lexer.EM && (separador.Type != lexer.DE || cada) {
+		esperado := "'em' ou 'de'"
+		if cada {
+			esperado = "'em'"
+		}
+		return nil, utils.NovoErro("token inesperado", separador.Position.Line, separador.Position.Column,
+			fmt.Sprintf("esperado %s após a variável do laço, encontrado %s", esperado, separador.Type))
+	}
+	inicio, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+	if err != nil {
+		return nil, err
+	}
+
+	var fim, passo Expressao
+	intervalo := separador.Type == lexer.DE || p.tokenAtual().Type == lexer.RANGE
+	if intervalo && valor != "" {
+		return nil, utils.NovoErro("token inesperado", tok.Position.Line, tok.Position.Column,
+			"um intervalo tem só a variável do laço; 'para k, v em' percorre mapas")
+	}
+	if intervalo {
+		if separador.Type == lexer.DE {
+			err = p.verificarProximoToken(lexer.ATE)
+		} else {
+			p.proximoToken() // consome '..'
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fim, err = p.analisarExpressao(PRECEDENCIA_NENHUMA); err != nil {
+			return nil, err
+		}
+		if p.tokenAtual().Type == lexer.PASSO {
+			p.proximoToken() // consome 'passo'
+			if passo, err = p.analisarExpressao(PRECEDENCIA_NENHUMA); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := p.verificarProximoToken(lexer.LBRACE); err != nil {
+		return nil, err
+	}
+	corpo, err := p.analisarBloco()
+	if err != nil {
+		return nil, err
+	}
+	if !intervalo {
+		return &ComandoParaCada{Variavel: variavel.Value, Valor: valor, Colecao: inicio, Corpo: corpo, Token: tok}, nil
+	}
+	return &ComandoParaIntervalo{
+		Variavel:  variavel.Value,
+		Inicio:    inicio,
+		Fim:       fim,
+		Passo:     passo,
+		Inclusivo: separador.Type == lexer.DE,
+		Corpo:     corpo,
+		Token:     tok,
+	}, nil
+}
+
 // analisarAtribOuExpressao tenta analisar uma atribuição (com ou sem anotação de tipo) ou uma expressão
 func (p *Parser) analisarAtribOuExpressao() (Expressao, error) {
 	if p.tokenAtual().Type == lexer.IDENTIFIER {
@@ -756,7 +1397,37 @@ func (p *Parser) analisarAtribOuExpressao() (Expressao, error) {
 		// não era atribuição: restaura posição para tratar como expressão normal
 		p.posicaoAtual = save
 	}
-	return p.analisarExpressao(PRECEDENCIA_NENHUMA)
+	return p.analisarExpressaoOuAtribuicaoIndice()
+}
+
+// analisarExpressaoOuAtribuicaoIndice analisa uma expressão; se ela for um acesso a
+// elemento ou a campo seguido de '~>' (xs[i] ~> valor, p.x ~> valor), o resultado é a
+// atribuição ao elemento ou ao campo
+func (p *Parser) analisarExpressaoOuAtribuicaoIndice() (Expressao, error) {
+	expressao, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+	if err != nil {
+		return nil, err
+	}
+	if p.tokenAtual().Type != lexer.ASSIGN {
+		return expressao, nil
+	}
+	switch alvo := expressao.(type) {
+	case *Indexacao:
+		p.proximoToken() // consome '~>'
+		valor, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		return &AtribuicaoIndice{Colecao: alvo.Colecao, Indice: alvo.Indice, Valor: valor, Token: alvo.Token}, nil
+	case *AcessoCampo:
+		p.proximoToken() // consome '~>'
+		valor, err := p.analisarExpressao(PRECEDENCIA_NENHUMA)
+		if err != nil {
+			return nil, err
+		}
+		return &AtribuicaoCampo{Objeto: alvo.Objeto, Campo: alvo.Campo, Valor: valor, Token: alvo.Token}, nil
+	}
+	return expressao, nil
 }
 
 // verifica se há uma anotação de tipo logo após o token atual no formato ': Tipo'
@@ -765,13 +1436,75 @@ func (p *Parser) parseTipoAnnotationIfPresent() (*Tipo, error) {
 		return nil, nil
 	}
 	p.proximoToken() // consome ':'
+	tp, err := p.analisarTipo()
+	if err != nil {
+		return nil, err
+	}
+	return &tp, nil
+}
+
+// analisarTipo lê um tipo: um nome (inteiro, decimal, texto, booleano, vazio), lista<tipo>,
+// mapa<chave, valor> ou o nome de um registro
+func (p *Parser) analisarTipo() (Tipo, error) {
 	tTok := p.proximoToken()
 	if tTok.Type != lexer.IDENTIFIER {
-		return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, "esperado identificador de tipo")
+		return 0, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, "esperado identificador de tipo")
+	}
+	if tTok.Value == "lista" {
+		if err := p.verificarProximoToken(lexer.LESS); err != nil {
+			return 0, err
+		}
+		elemento, err := p.analisarTipo()
+		if err != nil {
+			return 0, err
+		}
+		if err := p.fecharParametroTipo(); err != nil {
+			return 0, err
+		}
+		return TipoLista(elemento), nil
+	}
+	if tTok.Value == "mapa" {
+		if err := p.verificarProximoToken(lexer.LESS); err != nil {
+			return 0, err
+		}
+		tokChave := p.tokenAtual()
+		chave, err := p.analisarTipo()
+		if err != nil {
+			return 0, err
+		}
+		if !chave.EhChaveMapa() {
+			return 0, utils.NovoErro("tipo inválido", tokChave.Position.Line, tokChave.Position.Column,
+				fmt.Sprintf("chave de mapa deve ser inteiro, texto ou booleano, encontrado %s", chave.String()))
+		}
+		if err := p.verificarProximoToken(lexer.COMMA); err != nil {
+			return 0, err
+		}
+		valor, err := p.analisarTipo()
+		if err != nil {
+			return 0, err
+		}
+		if err := p.fecharParametroTipo(); err != nil {
+			return 0, err
+		}
+		return TipoMapa(chave, valor), nil
+	}
+	if p.tiposNomeados[tTok.Value] {
+		return TipoNomeado(tTok.Value), nil
 	}
 	tp, err := p.parseTipoPorNome(tTok.Value)
 	if err != nil {
-		return nil, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, err.Error())
+		return 0, utils.NovoErro("tipo inválido", tTok.Position.Line, tTok.Position.Column, err.Error())
 	}
-	return &tp, nil
+	return tp, nil
+}
+
+// fecharParametroTipo consome o '>' que fecha um parâmetro de tipo. Em lista<lista<inteiro>>
+// o lexer produz '>>', que é dividido: o primeiro '>' é consumido e o segundo fica como token atual
+func (p *Parser) fecharParametroTipo() error {
+	if tok := p.tokenAtual(); tok.Type == lexer.SHIFT_RIGHT {
+		pos := lexer.NovaPosicao(tok.Position.Line, tok.Position.Column+1, tok.Position.Offset+1)
+		p.tokens[p.posicaoAtual] = lexer.NovoToken(lexer.GREATER, ">", pos)
+		return nil
+	}
+	return p.verificarProximoToken(lexer.GREATER)
 }
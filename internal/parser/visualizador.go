@@ -42,6 +42,11 @@ func (v *VisualizadorArvore) CriarArvore(expressao Expressao) *tree.Tree {
 
 		return arvore
 
+	case *OperacaoUnaria:
+		arvore := tree.NewTree(tree.NodeString(expr.Operador.String()))
+		arvore.AddChild(v.CriarArvore(expr.Operando).Val())
+		return arvore
+
 	default:
 		return tree.NewTree(tree.NodeString("?"))
 	}
@@ -88,6 +93,99 @@ func (v *VisualizadorArvore) criarArvoreRecursiva(expressao Expressao) *tree.Tre
 		v.adicionarSubarvore(arvore, subarvoreDireita)
 		return arvore
 
+	case *OperacaoUnaria:
+		arvore := tree.NewTree(tree.NodeString(expr.Operador.String()))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Operando))
+		return arvore
+
+	case *LiteralTexto:
+		return tree.NewTree(tree.NodeString(expr.String()))
+
+	case *TextoInterpolado:
+		arvore := tree.NewTree(tree.NodeString("texto"))
+		for _, parte := range expr.Partes {
+			v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(parte))
+		}
+		return arvore
+
+	case *ListaLiteral:
+		arvore := tree.NewTree(tree.NodeString("[]"))
+		for _, elemento := range expr.Elementos {
+			v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(elemento))
+		}
+		return arvore
+
+	case *MapaLiteral:
+		arvore := tree.NewTree(tree.NodeString("{}"))
+		for i, chave := range expr.Chaves {
+			entrada := tree.NewTree(tree.NodeString(":"))
+			v.adicionarSubarvore(entrada, v.criarArvoreRecursiva(chave))
+			v.adicionarSubarvore(entrada, v.criarArvoreRecursiva(expr.Valores[i]))
+			v.adicionarSubarvore(arvore, entrada)
+		}
+		return arvore
+
+	case *Indexacao:
+		arvore := tree.NewTree(tree.NodeString("[i]"))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Colecao))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Indice))
+		return arvore
+
+	case *AtribuicaoIndice:
+		arvore := tree.NewTree(tree.NodeString("[i] ~>"))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Colecao))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Indice))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Valor))
+		return arvore
+
+	case *RegistroDeclaracao:
+		arvore := tree.NewTree(tree.NodeString("registro " + expr.Nome))
+		for _, campo := range expr.Campos {
+			arvore.AddChild(tree.NodeString(fmt.Sprintf("%s: %s", campo.Nome, campo.Tipo.String())))
+		}
+		return arvore
+
+	case *RegistroLiteral:
+		arvore := tree.NewTree(tree.NodeString(expr.Nome + "{}"))
+		for i, campo := range expr.Campos {
+			entrada := tree.NewTree(tree.NodeString(campo + ":"))
+			v.adicionarSubarvore(entrada, v.criarArvoreRecursiva(expr.Valores[i]))
+			v.adicionarSubarvore(arvore, entrada)
+		}
+		return arvore
+
+	case *AcessoCampo:
+		arvore := tree.NewTree(tree.NodeString("." + expr.Campo))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Objeto))
+		return arvore
+
+	case *AtribuicaoCampo:
+		arvore := tree.NewTree(tree.NodeString("." + expr.Campo + " ~>"))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Objeto))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Valor))
+		return arvore
+
+	case *EnumeracaoDeclaracao:
+		arvore := tree.NewTree(tree.NodeString("enumeracao " + expr.Nome))
+		for _, variante := range expr.Variantes {
+			arvore.AddChild(tree.NodeString(variante))
+		}
+		return arvore
+
+	case *Escolha:
+		arvore := tree.NewTree(tree.NodeString("escolha"))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Valor))
+		for _, caso := range expr.Casos {
+			padrao := "_"
+			if caso.Padrao != nil {
+				padrao = caso.Padrao.String()
+			}
+			casoArvore := tree.NewTree(tree.NodeString("caso " + padrao))
+			v.adicionarSubarvore(casoArvore, v.criarArvoreRecursiva(caso.Corpo))
+			v.adicionarSubarvore(arvore, casoArvore)
+		}
+		return arvore
+
 	case *ChamadaFuncao:
 		arvore := tree.NewTree(tree.NodeString(expr.Nome))
 
@@ -132,6 +230,14 @@ func (v *VisualizadorArvore) criarArvoreRecursiva(expressao Expressao) *tree.Tre
 		blocoSeArvore := v.criarArvoreRecursiva(expr.BlocoSe)
 		v.adicionarSubarvore(arvore, blocoSeArvore)
 
+		// Adiciona cada ramo "senao se" com sua condição e bloco
+		for _, ramo := range expr.SenaoSe {
+			ramoArvore := tree.NewTree(tree.NodeString("senao se"))
+			v.adicionarSubarvore(ramoArvore, v.criarArvoreRecursiva(ramo.Condicao))
+			v.adicionarSubarvore(ramoArvore, v.criarArvoreRecursiva(ramo.Bloco))
+			v.adicionarSubarvore(arvore, ramoArvore)
+		}
+
 		// Adiciona bloco "senao" se existir
 		if expr.BlocoSenao != nil {
 			blocoSenaoArvore := v.criarArvoreRecursiva(expr.BlocoSenao)
@@ -142,6 +248,12 @@ func (v *VisualizadorArvore) criarArvoreRecursiva(expressao Expressao) *tree.Tre
 
 		return arvore
 
+	case *Parar:
+		return tree.NewTree(tree.NodeString(expr.String()))
+
+	case *Continuar:
+		return tree.NewTree(tree.NodeString(expr.String()))
+
 	case *ComandoEnquanto:
 		arvore := tree.NewTree(tree.NodeString("enquanto"))
 		cond := v.criarArvoreRecursiva(expr.Condicao)
@@ -164,6 +276,26 @@ func (v *VisualizadorArvore) criarArvoreRecursiva(expressao Expressao) *tree.Tre
 		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Corpo))
 		return arvore
 
+	case *ComandoParaCada:
+		variaveis := expr.Variavel
+		if expr.Valor != "" {
+			variaveis += ", " + expr.Valor
+		}
+		arvore := tree.NewTree(tree.NodeString(fmt.Sprintf("para cada %s em", variaveis)))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Colecao))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Corpo))
+		return arvore
+
+	case *ComandoParaIntervalo:
+		arvore := tree.NewTree(tree.NodeString(fmt.Sprintf("para %s", expr.Variavel)))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Inicio))
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Fim))
+		if expr.Passo != nil {
+			v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Passo))
+		}
+		v.adicionarSubarvore(arvore, v.criarArvoreRecursiva(expr.Corpo))
+		return arvore
+
 	case *Bloco:
 		arvore := tree.NewTree(tree.NodeString("bloco"))
 
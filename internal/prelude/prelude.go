@@ -1,6 +1,11 @@
 package prelude
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/khevencolino/Solar/internal/registry"
+)
 
 // Prelude contém símbolos sempre disponíveis (sem import)
 type Prelude struct {
@@ -32,23 +37,7 @@ func NewPrelude() *Prelude {
 				if i > 0 {
 					fmt.Print(" ")
 				}
-				// Formata diferentes tipos apropriadamente
-				switch v := arg.(type) {
-				case int:
-					fmt.Print(v)
-				case float64:
-					fmt.Printf("%g", v)
-				case string:
-					fmt.Print(v)
-				case bool:
-					if v {
-						fmt.Print("verdadeiro")
-					} else {
-						fmt.Print("falso")
-					}
-				default:
-					fmt.Print(v)
-				}
+				fmt.Print(formatar(arg))
 			}
 			fmt.Println()
 			return 0
@@ -58,6 +47,42 @@ func NewPrelude() *Prelude {
 	return p
 }
 
+// formatar formata diferentes tipos apropriadamente; listas saem como [a, b, c],
+// mapas como {a: 1, b: 2} e registros como Ponto{x: 1, y: 2}
+func formatar(arg interface{}) string {
+	switch v := arg.(type) {
+	case float64:
+		return fmt.Sprintf("%g", v)
+	case bool:
+		if v {
+			return "verdadeiro"
+		}
+		return "falso"
+	case *[]interface{}:
+		elementos := make([]string, len(*v))
+		for i, elemento := range *v {
+			elementos[i] = formatar(elemento)
+		}
+		return "[" + strings.Join(elementos, ", ") + "]"
+	case *registry.Mapa:
+		entradas := make([]string, len(v.Chaves))
+		for i, chave := range v.Chaves {
+			entradas[i] = formatar(chave) + ": " + formatar(v.Valores[chave])
+		}
+		return "{" + strings.Join(entradas, ", ") + "}"
+	case *registry.Registro:
+		campos := make([]string, len(v.Campos))
+		for i, campo := range v.Campos {
+			campos[i] = campo + ": " + formatar(v.Valores[i])
+		}
+		return v.Nome + "{" + strings.Join(campos, ", ") + "}"
+	case registry.Variante:
+		return v.Nome
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 // EhFuncaoPrelude verifica se uma função está no prelude
 func (p *Prelude) EhFuncaoPrelude(nome string) bool {
 	_, existe := p.funcoes[nome]
@@ -2,6 +2,8 @@ package registry
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 )
 
 // TipoArgumento define os tipos de argumentos aceitos
@@ -10,6 +12,16 @@ type TipoArgumento int
 const (
 	TIPO_INTEIRO TipoArgumento = iota
 	TIPO_QUALQUER
+	TIPO_DECIMAL
+	TIPO_TEXTO
+	TIPO_BOOLEANO
+	TIPO_VAZIO
+	TIPO_LISTA       // lista com qualquer tipo de elemento
+	TIPO_ELEMENTO    // tipo dos elementos da lista do primeiro argumento
+	TIPO_LISTA_TEXTO // lista<texto>
+	TIPO_MAPA        // mapa com quaisquer tipos de chave e valor
+	TIPO_CHAVE       // tipo das chaves do mapa do primeiro argumento
+	TIPO_LISTA_CHAVE // lista com o tipo das chaves do mapa do primeiro argumento
 )
 
 // TipoFuncao define como a função se comporta
@@ -18,6 +30,7 @@ type TipoFuncao int
 const (
 	FUNCAO_IMPRIME TipoFuncao = iota // Função que imprime (tem efeito colateral)
 	FUNCAO_PURA                      // Função que só retorna valor
+	FUNCAO_RUNTIME                   // Função pura implementada por uma rotina do runtime nos backends nativos
 )
 
 // AssinaturaFuncao define a assinatura de uma função builtin
@@ -26,7 +39,11 @@ type AssinaturaFuncao struct {
 	MinArgumentos  int
 	MaxArgumentos  int // -1 para ilimitado
 	TiposArgumento []TipoArgumento
+	TipoRetorno    TipoArgumento // tipo do valor retornado (inteiro por padrão)
 	TipoFuncao     TipoFuncao
+	Rotina         string // nome da rotina do runtime (FUNCAO_RUNTIME)
+	RotinaLista    string // rotina usada quando o primeiro argumento é uma lista
+	RotinaMapa     string // rotina usada quando o primeiro argumento é um mapa
 	Descricao      string
 }
 
@@ -68,6 +85,193 @@ var funcoesBuiltinPadroes = map[string]FuncaoBuiltin{
 			return nil, nil
 		},
 	},
+	"tamanho": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "tamanho",
+			MinArgumentos:  1,
+			MaxArgumentos:  1,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO},
+			TipoRetorno:    TIPO_INTEIRO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_tamanho",
+			RotinaLista:    "lista_tamanho",
+			RotinaMapa:     "mapa_tamanho",
+			Descricao:      "Número de caracteres de um texto, de elementos de uma lista ou de chaves de um mapa",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			switch colecao := argumentos[0].(type) {
+			case *[]interface{}:
+				return len(*colecao), nil
+			case *Mapa:
+				return len(colecao.Chaves), nil
+			}
+			return utf8.RuneCountInString(argumentos[0].(string)), nil
+		},
+	},
+	"anexar": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "anexar",
+			MinArgumentos:  2,
+			MaxArgumentos:  2,
+			TiposArgumento: []TipoArgumento{TIPO_LISTA, TIPO_ELEMENTO},
+			TipoRetorno:    TIPO_VAZIO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			RotinaLista:    "lista_anexa",
+			Descricao:      "Adiciona um elemento ao fim de uma lista",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			lista := argumentos[0].(*[]interface{})
+			*lista = append(*lista, argumentos[1])
+			return nil, nil
+		},
+	},
+	"contem_chave": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "contem_chave",
+			MinArgumentos:  2,
+			MaxArgumentos:  2,
+			TiposArgumento: []TipoArgumento{TIPO_MAPA, TIPO_CHAVE},
+			TipoRetorno:    TIPO_BOOLEANO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			RotinaMapa:     "mapa_contem",
+			Descricao:      "Indica se um mapa tem a chave",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			_, ok := argumentos[0].(*Mapa).Obter(argumentos[1])
+			return ok, nil
+		},
+	},
+	"remover": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "remover",
+			MinArgumentos:  2,
+			MaxArgumentos:  2,
+			TiposArgumento: []TipoArgumento{TIPO_MAPA, TIPO_CHAVE},
+			TipoRetorno:    TIPO_VAZIO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			RotinaMapa:     "mapa_remove",
+			Descricao:      "Remove uma chave de um mapa (sem efeito se ela não existe)",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			argumentos[0].(*Mapa).Remover(argumentos[1])
+			return nil, nil
+		},
+	},
+	"chaves": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "chaves",
+			MinArgumentos:  1,
+			MaxArgumentos:  1,
+			TiposArgumento: []TipoArgumento{TIPO_MAPA},
+			TipoRetorno:    TIPO_LISTA_CHAVE,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			RotinaMapa:     "mapa_chaves",
+			Descricao:      "Lista nova com as chaves de um mapa, em ordem de inserção",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			chaves := append([]interface{}{}, argumentos[0].(*Mapa).Chaves...)
+			return &chaves, nil
+		},
+	},
+	"maiusculas": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "maiusculas",
+			MinArgumentos:  1,
+			MaxArgumentos:  1,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO},
+			TipoRetorno:    TIPO_TEXTO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_maiusculas",
+			Descricao:      "Converte as letras de um texto para maiúsculas",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			return ConverterCaixa(argumentos[0].(string), true), nil
+		},
+	},
+	"minusculas": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "minusculas",
+			MinArgumentos:  1,
+			MaxArgumentos:  1,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO},
+			TipoRetorno:    TIPO_TEXTO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_minusculas",
+			Descricao:      "Converte as letras de um texto para minúsculas",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			return ConverterCaixa(argumentos[0].(string), false), nil
+		},
+	},
+	"contem": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "contem",
+			MinArgumentos:  2,
+			MaxArgumentos:  2,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO, TIPO_TEXTO},
+			TipoRetorno:    TIPO_BOOLEANO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_contem",
+			Descricao:      "Verifica se um texto contém outro",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			return strings.Contains(argumentos[0].(string), argumentos[1].(string)), nil
+		},
+	},
+	"substituir": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "substituir",
+			MinArgumentos:  3,
+			MaxArgumentos:  3,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO, TIPO_TEXTO, TIPO_TEXTO},
+			TipoRetorno:    TIPO_TEXTO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_substitui",
+			Descricao:      "Substitui todas as ocorrências de um trecho de um texto",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			texto, antigo := argumentos[0].(string), argumentos[1].(string)
+			if antigo == "" {
+				return texto, nil
+			}
+			return strings.ReplaceAll(texto, antigo, argumentos[2].(string)), nil
+		},
+	},
+	"dividir": {
+		Assinatura: AssinaturaFuncao{
+			Nome:           "dividir",
+			MinArgumentos:  2,
+			MaxArgumentos:  2,
+			TiposArgumento: []TipoArgumento{TIPO_TEXTO, TIPO_TEXTO},
+			TipoRetorno:    TIPO_LISTA_TEXTO,
+			TipoFuncao:     FUNCAO_RUNTIME,
+			Rotina:         "texto_divide",
+			Descricao:      "Divide um texto nos trechos entre as ocorrências de um separador (vazio: em caracteres)",
+		},
+		Executar: func(argumentos []interface{}) (interface{}, error) {
+			partes := strings.Split(argumentos[0].(string), argumentos[1].(string))
+			lista := make([]interface{}, len(partes))
+			for i, parte := range partes {
+				lista[i] = parte
+			}
+			return &lista, nil
+		},
+	},
+}
+
+// ConverterCaixa converte as letras ASCII e as letras acentuadas do Latin-1
+// (U+00C0 a U+00FE, exceto × e ÷) para maiúsculas ou minúsculas. As rotinas
+// dos backends nativos seguem exatamente a mesma regra
+func ConverterCaixa(texto string, maiusculas bool) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case maiusculas && (r >= 'a' && r <= 'z' || r >= 0xE0 && r <= 0xFE && r != 0xF7):
+			return r - 32
+		case !maiusculas && (r >= 'A' && r <= 'Z' || r >= 0xC0 && r <= 0xDE && r != 0xD7):
+			return r + 32
+		}
+		return r
+	}, texto)
 }
 
 // registrarFuncoesBasicas registra as funções builtin básicas de forma otimizada
@@ -0,0 +1,9 @@
+package registry
+
+// Variante é a representação de um valor de enumeração no interpretador. É um valor
+// comparável: duas variantes são iguais quando têm a mesma enumeração e o mesmo nome
+type Variante struct {
+	Enumeracao string
+	Nome       string
+	Indice     int // posição na declaração, usada pelos backends compilados
+}
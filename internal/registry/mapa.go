@@ -0,0 +1,42 @@
+package registry
+
+// Mapa é a representação de mapa<K, V> no interpretador: os valores ficam em um mapa
+// Go e as chaves também em uma lista, que guarda a ordem de inserção. As chaves são
+// int, string ou bool, todas comparáveis em Go
+type Mapa struct {
+	Chaves  []interface{}
+	Valores map[interface{}]interface{}
+}
+
+// NovoMapa cria um mapa vazio
+func NovoMapa() *Mapa {
+	return &Mapa{Valores: make(map[interface{}]interface{})}
+}
+
+// Obter retorna o valor da chave e se ela existe
+func (m *Mapa) Obter(chave interface{}) (interface{}, bool) {
+	valor, ok := m.Valores[chave]
+	return valor, ok
+}
+
+// Definir atribui o valor à chave; uma chave nova vai para o fim da ordem de inserção
+func (m *Mapa) Definir(chave, valor interface{}) {
+	if _, ok := m.Valores[chave]; !ok {
+		m.Chaves = append(m.Chaves, chave)
+	}
+	m.Valores[chave] = valor
+}
+
+// Remover retira a chave do mapa (sem efeito se ela não existe)
+func (m *Mapa) Remover(chave interface{}) {
+	if _, ok := m.Valores[chave]; !ok {
+		return
+	}
+	delete(m.Valores, chave)
+	for i, c := range m.Chaves {
+		if c == chave {
+			m.Chaves = append(m.Chaves[:i], m.Chaves[i+1:]...)
+			break
+		}
+	}
+}
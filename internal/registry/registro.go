@@ -0,0 +1,32 @@
+package registry
+
+// Registro é a representação de um valor de tipo registro no interpretador: os valores
+// ficam na ordem dos campos da declaração. Registros são valores, então cada atribuição
+// guarda uma cópia (Copiar); listas e mapas nos campos continuam compartilhados
+type Registro struct {
+	Nome    string
+	Campos  []string
+	Valores []interface{}
+}
+
+// Campo retorna a posição do campo e se ele existe
+func (r *Registro) Campo(nome string) (int, bool) {
+	for i, campo := range r.Campos {
+		if campo == nome {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Copiar retorna uma cópia do registro, incluindo os registros aninhados
+func (r *Registro) Copiar() *Registro {
+	copia := &Registro{Nome: r.Nome, Campos: r.Campos, Valores: make([]interface{}, len(r.Valores))}
+	for i, valor := range r.Valores {
+		if aninhado, ok := valor.(*Registro); ok {
+			valor = aninhado.Copiar()
+		}
+		copia.Valores[i] = valor
+	}
+	return copia
+}
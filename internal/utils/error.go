@@ -41,3 +41,12 @@ func NovoErro(mensagem string, linha, coluna int, detalhes string) *CompilerErro
 		Detalhes: detalhes,
 	}
 }
+
+// ErroExecucao marca um erro ocorrido ao executar o programa (no interpretador), e não
+// ao compilá-lo
+type ErroExecucao struct {
+	Erro error
+}
+
+func (e *ErroExecucao) Error() string { return e.Erro.Error() }
+func (e *ErroExecucao) Unwrap() error { return e.Erro }